@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/backup"
+	"github.com/matiasvarela/minesweeper/internal/config"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage"
+	"github.com/matiasvarela/minesweeper/internal/storage/schema"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// runAdmin exports the games of the configured storage to a file, imports them from it, upgrades the games stored
+// at an older schema version, or writes the past versions of a game. The bitcask databases being locked by the server,
+// these commands can only be run on them while it is stopped.
+func runAdmin(cfg config.Admin) error {
+	storages, err := storage.Open(cfg.Storage)
+	if err != nil {
+		return err
+	}
+
+	switch cfg.Command {
+	case config.COMMAND_EXPORT:
+		err = exportGames(storages.Games, cfg)
+	case config.COMMAND_IMPORT:
+		err = importGames(storages.Games, cfg)
+	case config.COMMAND_MIGRATE:
+		err = migrateGames(storages.Games)
+	case config.COMMAND_HISTORY:
+		err = gameHistory(storages.Games, cfg)
+	}
+
+	closeErr := storages.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// exportGames writes the games to the file, which is removed when the export fails so that no partial export is left
+func exportGames(games game.Storage, cfg config.Admin) error {
+	if cfg.File == "-" {
+		count, err := backup.Export(games, os.Stdout, cfg.Compress)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("%d games have been exported", count)
+
+		return nil
+	}
+
+	file, err := os.Create(cfg.File)
+	if err != nil {
+		return err
+	}
+
+	count, err := backup.Export(games, file, cfg.Compress)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(cfg.File)
+		return err
+	}
+
+	log.Printf("%d games have been exported to %s", count, cfg.File)
+
+	return nil
+}
+
+func importGames(games game.Storage, cfg config.Admin) error {
+	file, err := os.Open(cfg.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	summary, err := backup.Import(games, file, cfg.DryRun)
+	if err != nil {
+		return err
+	}
+
+	if cfg.DryRun {
+		log.Printf("dry run: the export holds %d games, %d would be imported and %d skipped as already stored", summary.Games, summary.Imported, summary.Skipped)
+		return nil
+	}
+
+	log.Printf("the export holds %d games, %d have been imported and %d skipped as already stored", summary.Games, summary.Imported, summary.Skipped)
+
+	return nil
+}
+
+// migrateGames upgrades the games stored at an older schema version at once, rather than one after the other
+// when they are read
+func migrateGames(games game.Storage) error {
+	migrator, ok := games.(schema.Migrator)
+	if !ok {
+		return errors.New(apperrors.Unsupported, nil, "the storage has no stored games to migrate, its schema is migrated on startup", "")
+	}
+
+	migrated, err := migrator.Migrate()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%d games have been upgraded to the schema version %d", migrated, schema.Games.Current())
+
+	return nil
+}
+
+// gameHistory writes the game at each of its versions, from its creation on, or at the given version only, as
+// newline delimited json. The file is removed when the history cannot be written.
+func gameHistory(games game.Storage, cfg config.Admin) error {
+	historian, ok := games.(game.Historian)
+	if !ok {
+		return errors.New(apperrors.Unsupported, nil, "the storage does not keep the past versions of the games", "")
+	}
+
+	first, last := cfg.Version, cfg.Version
+	if cfg.Version < 0 {
+		current, err := games.GetByID(cfg.GameID)
+		if err != nil {
+			return err
+		}
+
+		first, last = 0, current.Version
+	}
+
+	if cfg.File == "-" {
+		return writeVersions(historian, cfg.GameID, first, last, os.Stdout)
+	}
+
+	file, err := os.Create(cfg.File)
+	if err != nil {
+		return err
+	}
+
+	err = writeVersions(historian, cfg.GameID, first, last, file)
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(cfg.File)
+		return err
+	}
+
+	log.Printf("%d versions of the game %s have been written to %s", last-first+1, cfg.GameID, cfg.File)
+
+	return nil
+}
+
+// writeVersions writes the game at each version from the first one to the last one, both included
+func writeVersions(historian game.Historian, id string, first int64, last int64, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	for version := first; version <= last; version++ {
+		g, err := historian.GetAt(id, version)
+		if err != nil {
+			return err
+		}
+
+		err = encoder.Encode(g)
+		if err != nil {
+			return errors.New(apperrors.Internal, err, "internal error", "write version of the game has failed")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/schema"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// FORMAT identifies the exports of games
+const FORMAT = "minesweeper-games"
+
+// VERSION is the version of the format written by Export. Import reads the exports of this version or older ones.
+// The versions are:
+//
+//  1. the games as served by the api
+//  2. the games as stored, in the compact encoding along with their schema version, so that the games of older
+//     exports are upgraded by the schema migrations when imported
+const VERSION = 2
+
+// Header is the first record of an export
+type Header struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	ExportedAt int64  `json:"exported_at"`
+}
+
+// Trailer is the last record of an export. It holds the number of exported games along with the sha256 of their
+// records, so that truncated or altered exports are detected.
+type Trailer struct {
+	Count  int    `json:"count"`
+	SHA256 string `json:"sha256"`
+}
+
+// record is a line of an export, holding either the header, a game or the trailer
+type record struct {
+	Header  *Header         `json:"header,omitempty"`
+	Game    json.RawMessage `json:"game,omitempty"`
+	Trailer *Trailer        `json:"trailer,omitempty"`
+}
+
+// Summary reports the games of an import
+type Summary struct {
+	// Games is the number of games of the export
+	Games int
+	// Imported is the number of games created in the storage, or which would be created by a dry run
+	Imported int
+	// Skipped is the number of games left untouched because the storage already holds them
+	Skipped int
+}
+
+// Export writes every game of the storage, which must be a game.Walker, to w as newline delimited json, compressed
+// with gzip when asked to, and return the number of exported games. The export is only a point in time backup when
+// the games are not modified meanwhile.
+func Export(storage game.Storage, w io.Writer, compress bool) (int, error) {
+	walker, ok := storage.(game.Walker)
+	if !ok {
+		return 0, errors.New(apperrors.Unsupported, nil, "exporting games is not supported by the storage", "")
+	}
+
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	checksum := sha256.New()
+	count := 0
+
+	err := encoder.Encode(record{Header: &Header{Format: FORMAT, Version: VERSION, ExportedAt: time.Now().Unix()}})
+	if err != nil {
+		return 0, errors.New(apperrors.Internal, err, "internal error", "write header of the export has failed")
+	}
+
+	err = walker.Walk(func(g game.Game) error {
+		bytes, err := schema.MarshalGame(g)
+		if err != nil {
+			return errors.New(apperrors.Internal, err, "internal error", "marshal game into json has failed")
+		}
+
+		sum(checksum, bytes)
+		count++
+
+		return encoder.Encode(record{Game: bytes})
+	})
+	if err != nil {
+		return count, errors.New(apperrors.Internal, err, "internal error", "export games has failed")
+	}
+
+	err = encoder.Encode(record{Trailer: &Trailer{Count: count, SHA256: hex.EncodeToString(checksum.Sum(nil))}})
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		return count, errors.New(apperrors.Internal, err, "internal error", "write export has failed")
+	}
+
+	return count, nil
+}
+
+// Import creates the games of the export read from r into the storage, and return what has been imported.
+// The whole export is checked before the storage is queried, so that nothing is imported from a truncated or altered
+// export. Games already held by the storage are skipped, which makes imports safe to run again once interrupted.
+// The dry run only checks the export and reports what would be imported.
+func Import(storage game.Storage, r io.ReadSeeker, dryRun bool) (Summary, error) {
+	games, err := Read(r, func(g game.Game) error { return nil })
+	if err != nil {
+		return Summary{}, err
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return Summary{}, errors.New(apperrors.Internal, err, "internal error", "rewind export has failed")
+	}
+
+	summary := Summary{Games: games}
+
+	_, err = Read(r, func(g game.Game) error {
+		_, err := storage.GetByID(g.ID)
+		if err == nil {
+			summary.Skipped++
+			return nil
+		}
+		if !errors.Is(err, apperrors.NotFound) {
+			return errors.New(apperrors.Internal, err, "internal error", "get game from storage has failed")
+		}
+
+		if !dryRun {
+			err = storage.Create(g)
+			if err != nil {
+				return errors.New(apperrors.Internal, err, "internal error", fmt.Sprintf("create game %s has failed", g.ID))
+			}
+		}
+
+		summary.Imported++
+
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	return summary, nil
+}
+
+// Read reads the export from r, compressed or not, calling fn with each of its games, and return the number of games.
+// It fails with an invalid input error when the export is malformed, truncated or altered, which is only known once
+// every game has been read.
+func Read(r io.Reader, fn func(g game.Game) error) (int, error) {
+	buffered := bufio.NewReader(r)
+
+	magic, _ := buffered.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return 0, errors.New(apperrors.InvalidInput, err, "the export is not a valid gzip file", "")
+		}
+		defer gz.Close()
+
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	decoder := json.NewDecoder(r)
+
+	invalid := func(err error, message string) error {
+		return errors.New(apperrors.InvalidInput, err, "invalid export: "+message, "")
+	}
+
+	next := func() (record, bool, error) {
+		rec := record{}
+
+		err := decoder.Decode(&rec)
+		if err == io.EOF {
+			return record{}, false, nil
+		}
+		if err != nil {
+			return record{}, false, invalid(err, "malformed record")
+		}
+
+		return rec, true, nil
+	}
+
+	rec, ok, err := next()
+	if err != nil {
+		return 0, err
+	}
+	if !ok || rec.Header == nil {
+		return 0, invalid(nil, "the header is missing")
+	}
+	if rec.Header.Format != FORMAT {
+		return 0, invalid(nil, fmt.Sprintf("unknown format %q", rec.Header.Format))
+	}
+	if rec.Header.Version < 1 || rec.Header.Version > VERSION {
+		return 0, invalid(nil, fmt.Sprintf("unsupported version %d", rec.Header.Version))
+	}
+
+	checksum := sha256.New()
+	ids := map[string]bool{}
+
+	for {
+		rec, ok, err = next()
+		if err != nil {
+			return len(ids), err
+		}
+		if !ok {
+			return len(ids), invalid(nil, "the export is truncated")
+		}
+
+		if rec.Trailer != nil {
+			break
+		}
+
+		if rec.Game == nil {
+			return len(ids), invalid(nil, "empty record")
+		}
+
+		g, _, err := schema.UnmarshalGame(rec.Game)
+		if err != nil {
+			return len(ids), invalid(err, "malformed game")
+		}
+
+		if g.ID == "" {
+			return len(ids), invalid(nil, "game without id")
+		}
+		if ids[g.ID] {
+			return len(ids), invalid(nil, fmt.Sprintf("game %s is duplicated", g.ID))
+		}
+
+		ids[g.ID] = true
+		sum(checksum, rec.Game)
+
+		err = fn(g)
+		if err != nil {
+			return len(ids), err
+		}
+	}
+
+	if rec.Trailer.Count != len(ids) {
+		return len(ids), invalid(nil, fmt.Sprintf("%d games were exported but %d have been read", rec.Trailer.Count, len(ids)))
+	}
+	if rec.Trailer.SHA256 != hex.EncodeToString(checksum.Sum(nil)) {
+		return len(ids), invalid(nil, "the checksum does not match the games")
+	}
+
+	if _, ok, err = next(); err != nil || ok {
+		return len(ids), invalid(err, "records follow the trailer")
+	}
+
+	return len(ids), nil
+}
+
+// sum adds the record of a game to the checksum of an export
+func sum(checksum hash.Hash, game []byte) {
+	checksum.Write(game)
+	checksum.Write([]byte{'\n'})
+}
@@ -0,0 +1,350 @@
+package backup_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/backup"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/internal/storage/localsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/sqlsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/storagetest"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+// walklessStorage hides the Walk method of the storage it wraps
+type walklessStorage struct {
+	game.Storage
+}
+
+func games() []game.Game {
+	return []game.Game{
+		storagetest.NewPlayedGame("1"),
+		storagetest.NewGame("2", "alice", game.MODE_COOP),
+		storagetest.NewGame("3", "", game.MODE_CLASSIC),
+	}
+}
+
+func export(t *testing.T, games []game.Game, compress bool) []byte {
+	sto := fakesto.NewGameStorage()
+	for _, g := range games {
+		assert.Nil(t, sto.Create(g))
+	}
+
+	out := &bytes.Buffer{}
+
+	count, err := backup.Export(sto, out, compress)
+	assert.Nil(t, err)
+	assert.Equal(t, len(games), count)
+
+	return out.Bytes()
+}
+
+// exportV1 return an export of the first version of the format, whose games are encoded as served by the api
+func exportV1(t *testing.T, games []game.Game) []byte {
+	out := &bytes.Buffer{}
+	checksum := sha256.New()
+
+	out.WriteString(`{"header":{"format":"` + backup.FORMAT + `","version":1,"exported_at":1588291200}}` + "\n")
+
+	for _, g := range games {
+		bytes, err := json.Marshal(&g)
+		assert.Nil(t, err)
+
+		checksum.Write(append(bytes, '\n'))
+		out.WriteString(`{"game":` + string(bytes) + "}\n")
+	}
+
+	out.WriteString(fmt.Sprintf(`{"trailer":{"count":%d,"sha256":"%s"}}`, len(games), hex.EncodeToString(checksum.Sum(nil))) + "\n")
+
+	return out.Bytes()
+}
+
+// lines return the records of an uncompressed export
+func lines(export []byte) []string {
+	return strings.Split(strings.TrimSuffix(string(export), "\n"), "\n")
+}
+
+func TestExportImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		should   string
+		compress bool
+	}{
+		{
+			name:   "bitcask to sqlite",
+			should: "import every game as it was exported",
+		},
+		{
+			name:     "compressed export",
+			should:   "import every game as it was exported",
+			compress: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := localsto.NewGameStorage(t.TempDir())
+			assert.Nil(t, err)
+			defer source.Close()
+
+			target, err := sqlsto.NewGameStorage(filepath.Join(t.TempDir(), "minesweeper.db"))
+			assert.Nil(t, err)
+			defer target.Close()
+
+			exported := games()
+			for _, g := range exported {
+				assert.Nil(t, source.Create(g))
+			}
+
+			out := &bytes.Buffer{}
+
+			count, err := backup.Export(source, out, tt.compress)
+			assert.Nil(t, err)
+			assert.Equal(t, 3, count)
+
+			if tt.compress {
+				_, err = gzip.NewReader(bytes.NewReader(out.Bytes()))
+				assert.Nil(t, err)
+			}
+
+			summary, err := backup.Import(target, bytes.NewReader(out.Bytes()), false)
+			assert.Nil(t, err)
+			assert.Equal(t, backup.Summary{Games: 3, Imported: 3}, summary)
+
+			for _, g := range exported {
+				imported, err := target.GetByID(g.ID)
+				assert.Nil(t, err)
+				assert.Equal(t, g, imported)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Run("storage without walk", func(t *testing.T) {
+		_, err := backup.Export(walklessStorage{fakesto.NewGameStorage()}, &bytes.Buffer{}, false)
+		assert.True(t, errors.Is(err, apperrors.Unsupported))
+	})
+
+	t.Run("stored encoding", func(t *testing.T) {
+		exported := lines(export(t, games(), false))
+		assert.Len(t, exported, 5)
+		assert.Contains(t, exported[0], fmt.Sprintf(`"version":%d`, backup.VERSION))
+
+		for _, record := range exported[1:4] {
+			assert.Contains(t, record, `"schema_version":`)
+		}
+	})
+
+	t.Run("empty storage", func(t *testing.T) {
+		exported := lines(export(t, nil, false))
+		assert.Len(t, exported, 2)
+
+		count, err := backup.Read(strings.NewReader(strings.Join(exported, "\n")), func(g game.Game) error { return nil })
+		assert.Nil(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestImport(t *testing.T) {
+	type input struct {
+		export func(t *testing.T) []byte
+		dryRun bool
+	}
+
+	valid := func(t *testing.T) []byte {
+		return export(t, games(), false)
+	}
+
+	firstVersionGames := games()
+
+	// alter return the valid export with its records changed by fn
+	alter := func(fn func(records []string) []string) func(t *testing.T) []byte {
+		return func(t *testing.T) []byte {
+			return []byte(strings.Join(fn(lines(valid(t))), "\n") + "\n")
+		}
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func(sto *fakesto.GameStorage)
+		verify func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage)
+	}{
+		{
+			name:   "dry run",
+			should: "report the games without creating them",
+			input:  input{export: valid, dryRun: true},
+			mock: func(sto *fakesto.GameStorage) {
+				assert.Nil(t, sto.Create(storagetest.NewGame("2", "alice", game.MODE_COOP)))
+			},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.Nil(t, err)
+				assert.Equal(t, backup.Summary{Games: 3, Imported: 2, Skipped: 1}, summary)
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_CREATE), 1)
+			},
+		},
+		{
+			name:   "games already stored",
+			should: "skip them and keep the stored ones",
+			input:  input{export: valid},
+			mock: func(sto *fakesto.GameStorage) {
+				assert.Nil(t, sto.Create(storagetest.NewGame("2", "bob", game.MODE_COOP)))
+			},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.Nil(t, err)
+				assert.Equal(t, backup.Summary{Games: 3, Imported: 2, Skipped: 1}, summary)
+
+				stored, _ := sto.GetByID("2")
+				assert.Equal(t, "bob", stored.PlayerID)
+			},
+		},
+		{
+			name:   "export of the first version",
+			should: "upgrade its games to the current schema",
+			input: input{export: func(t *testing.T) []byte {
+				return exportV1(t, firstVersionGames)
+			}},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.Nil(t, err)
+				assert.Equal(t, backup.Summary{Games: 3, Imported: 3}, summary)
+
+				for _, g := range firstVersionGames {
+					imported, err := sto.GetByID(g.ID)
+					assert.Nil(t, err)
+					assert.Equal(t, g, imported)
+				}
+			},
+		},
+		{
+			name:   "truncated export",
+			should: "return an invalid input error without querying the storage",
+			input: input{export: alter(func(records []string) []string {
+				return records[:len(records)-1]
+			})},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_CREATE), 0)
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_GET_BY_ID), 0)
+			},
+		},
+		{
+			name:   "altered game",
+			should: "return an invalid input error without querying the storage",
+			input: input{export: alter(func(records []string) []string {
+				for i := range records {
+					if strings.Contains(records[i], `"alice"`) {
+						records[i] = strings.Replace(records[i], `"alice"`, `"mallory"`, 1)
+						break
+					}
+				}
+
+				return records
+			})},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_CREATE), 0)
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_GET_BY_ID), 0)
+			},
+		},
+		{
+			name:   "missing game",
+			should: "return an invalid input error without querying the storage",
+			input: input{export: alter(func(records []string) []string {
+				return append(records[:1], records[2:]...)
+			})},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_CREATE), 0)
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_GET_BY_ID), 0)
+			},
+		},
+		{
+			name:   "duplicated game",
+			should: "return an invalid input error",
+			input: input{export: alter(func(records []string) []string {
+				return append([]string{records[0], records[1]}, records[1:]...)
+			})},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "records after the trailer",
+			should: "return an invalid input error",
+			input: input{export: alter(func(records []string) []string {
+				return append(records, records[1])
+			})},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "unknown format",
+			should: "return an invalid input error",
+			input: input{export: func(t *testing.T) []byte {
+				return []byte(`{"header": {"format": "chess", "version": 1}}` + "\n")
+			}},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "newer version",
+			should: "return an invalid input error",
+			input: input{export: alter(func(records []string) []string {
+				records[0] = strings.Replace(records[0], fmt.Sprintf(`"version":%d`, backup.VERSION), fmt.Sprintf(`"version":%d`, backup.VERSION+1), 1)
+				return records
+			})},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "not an export",
+			should: "return an invalid input error",
+			input: input{export: func(t *testing.T) []byte {
+				return []byte("id,owner\n1,alice\n")
+			}},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "storage failure",
+			should: "return an internal error",
+			input:  input{export: valid},
+			mock: func(sto *fakesto.GameStorage) {
+				sto.AddErrorOnCreate(errors.New(apperrors.Internal, nil, "fail", ""))
+			},
+			verify: func(t *testing.T, summary backup.Summary, err error, sto *fakesto.GameStorage) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sto := fakesto.NewGameStorage()
+			if tt.mock != nil {
+				tt.mock(sto)
+			}
+
+			summary, err := backup.Import(sto, bytes.NewReader(tt.input.export(t)), tt.input.dryRun)
+
+			tt.verify(t, summary, err, sto)
+		})
+	}
+}
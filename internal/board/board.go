@@ -23,6 +23,31 @@ func NewBoard(rowsNumber int, columnsNumber int, bombsNumber int) Board {
 	}
 }
 
+// NewSeededBoard create a new board whose bombs layout is fully determined by the given seed.
+// The bombs are placed right away and a safe square is revealed, so the first move is never a matter of luck.
+func NewSeededBoard(rowsNumber int, columnsNumber int, bombsNumber int, seed int64) Board {
+	b := NewBoard(rowsNumber, columnsNumber, bombsNumber)
+	r := rand.New(rand.NewSource(seed))
+
+	for _, p := range r.Perm(b.GetSquaresNumber())[:bombsNumber] {
+		pos := SquarePosition{Row: p / columnsNumber, Column: p % columnsNumber}
+
+		b.Get(pos).Type = BOMB
+		*b.BombsPositions = append(*b.BombsPositions, pos)
+	}
+
+	b.Status = STATUS_ON_GOING
+	b.FirstMoveDone = newBool(true)
+
+	b.RevealSquare(b.startingPosition(r))
+
+	if b.RevealedSquaresCount == b.GetSquaresNumber()-b.BombsNumber {
+		b.Status = STATUS_WON
+	}
+
+	return b
+}
+
 // Get return the square in the given position
 func (b *Board) Get(pos SquarePosition) *Square {
 	return &b.Squares[pos.Row][pos.Column]
@@ -64,6 +89,19 @@ func (b *Board) HasNeighborBomb(pos SquarePosition) bool {
 	return false
 }
 
+// CountNeighborBombs return the number of bombs adjacent to the given position
+func (b *Board) CountNeighborBombs(pos SquarePosition) int {
+	count := 0
+
+	for _, n := range b.GetNeighbors(pos) {
+		if n != pos && b.Is(n, BOMB) {
+			count++
+		}
+	}
+
+	return count
+}
+
 // GetNeighbors return the neighbors squares for the given position
 func (b *Board) GetNeighbors(pos SquarePosition) []SquarePosition {
 	neighbors := []SquarePosition{}
@@ -114,26 +152,31 @@ func (b *Board) FillWithBombs(excludePosition SquarePosition) {
 }
 
 // RevealSquare reveal the square in the given position trigger a reveal in cascade chain.
-func (b *Board) RevealSquare(pos SquarePosition) {
+// It return the positions of every square it has revealed.
+func (b *Board) RevealSquare(pos SquarePosition) []SquarePosition {
 	square := b.Get(pos)
 
 	if b.Is(pos, BOMB) || b.HasNeighborBomb(pos) {
 		square.Revealed = true
 		b.RevealedSquaresCount++
 
-		return
+		return []SquarePosition{pos}
 	}
 
-	b.revealSquareInCascade(pos)
+	touched := []SquarePosition{}
+	b.revealSquareInCascade(pos, &touched)
+
+	return touched
 }
 
-func (b *Board) revealSquareInCascade(pos SquarePosition) {
+func (b *Board) revealSquareInCascade(pos SquarePosition, touched *[]SquarePosition) {
 	if b.Is(pos, BOMB) || b.HasNeighborBomb(pos) || b.Get(pos).Revealed {
 		return
 	}
 
 	b.Get(pos).Revealed = true
 	b.RevealedSquaresCount++
+	*touched = append(*touched, pos)
 
 	neighbors := b.GetNeighbors(pos)
 
@@ -142,21 +185,23 @@ func (b *Board) revealSquareInCascade(pos SquarePosition) {
 			continue
 		}
 
-		b.revealSquareInCascade(neighbors[i])
+		b.revealSquareInCascade(neighbors[i], touched)
 	}
 }
 
-func (b *Board) PlaySquare(pos SquarePosition) error {
+// PlaySquare reveal the square in the given position and update the board status.
+// It return the positions of every square whose state has changed.
+func (b *Board) PlaySquare(pos SquarePosition) ([]SquarePosition, error) {
 	if b.Status == STATUS_LOST || b.Status == STATUS_WON {
-		return errors.New(apperrors.InvalidInput, nil, "cannot play a square on a finished game", "")
+		return nil, errors.New(apperrors.InvalidInput, nil, "cannot play a square on a finished game", "")
 	}
 
 	if !b.VerifyRange(pos) {
-		return errors.New(apperrors.InvalidInput, nil, "invalid square", "")
+		return nil, errors.New(apperrors.InvalidInput, nil, "invalid square", "")
 	}
 
 	if b.Get(pos).Revealed {
-		return nil
+		return []SquarePosition{}, nil
 	}
 
 	// the first move never touch a bomb
@@ -168,28 +213,42 @@ func (b *Board) PlaySquare(pos SquarePosition) error {
 	b.FirstMoveDone = newBool(true)
 
 	square := b.Get(pos)
-	b.RevealSquare(pos)
+	touched := b.RevealSquare(pos)
 
 	switch square.Type {
 	case BOMB:
 		b.Status = STATUS_LOST
 
-		for _, pos := range *b.BombsPositions {
-			b.Get(pos).Marked = false
-			b.Get(pos).Revealed = true
+		for _, bomb := range *b.BombsPositions {
+			b.Get(bomb).Marked = false
+			b.Get(bomb).Revealed = true
+
+			if bomb != pos {
+				touched = append(touched, bomb)
+			}
 		}
 
-		return nil
+		return touched, nil
 	}
 
 	if b.RevealedSquaresCount == b.GetSquaresNumber()-b.BombsNumber {
 		b.Status = STATUS_WON
 	}
 
-	return nil
+	return touched, nil
 }
 
+// MarkSquare toggle the mark of the square in the given position
 func (b *Board) MarkSquare(pos SquarePosition) error {
+	if !b.VerifyRange(pos) {
+		return errors.New(apperrors.InvalidInput, nil, "invalid square", "")
+	}
+
+	return b.SetMark(pos, !b.Get(pos).Marked)
+}
+
+// SetMark set or clear the mark of the square in the given position. Setting the mark it already has does nothing.
+func (b *Board) SetMark(pos SquarePosition, marked bool) error {
 	if b.Status == STATUS_LOST || b.Status == STATUS_WON {
 		return errors.New(apperrors.InvalidInput, nil, "cannot mark a square on a finished game", "")
 	}
@@ -202,11 +261,108 @@ func (b *Board) MarkSquare(pos SquarePosition) error {
 		return nil
 	}
 
-	b.Get(pos).Marked = !b.Get(pos).Marked
+	b.Get(pos).Marked = marked
 
 	return nil
 }
 
+// ChordSquare play every unmarked neighbor of the revealed square in the given position,
+// as long as it has as many marked neighbors as adjacent bombs. Otherwise nothing is played.
+// It return the positions of every square whose state has changed.
+func (b *Board) ChordSquare(pos SquarePosition) ([]SquarePosition, error) {
+	if b.Status == STATUS_LOST || b.Status == STATUS_WON {
+		return nil, errors.New(apperrors.InvalidInput, nil, "cannot play a square on a finished game", "")
+	}
+
+	if !b.VerifyRange(pos) {
+		return nil, errors.New(apperrors.InvalidInput, nil, "invalid square", "")
+	}
+
+	if !b.Get(pos).Revealed {
+		return nil, errors.New(apperrors.InvalidInput, nil, "only revealed squares can be chorded", "")
+	}
+
+	touched := []SquarePosition{}
+
+	marks := 0
+	for _, n := range b.GetNeighbors(pos) {
+		if n != pos && b.Get(n).Marked {
+			marks++
+		}
+	}
+
+	if marks != b.CountNeighborBombs(pos) {
+		return touched, nil
+	}
+
+	for _, n := range b.GetNeighbors(pos) {
+		if b.Status == STATUS_LOST || b.Status == STATUS_WON {
+			break
+		}
+
+		if n == pos || b.Get(n).Marked || b.Get(n).Revealed {
+			continue
+		}
+
+		played, err := b.PlaySquare(n)
+		if err != nil {
+			return nil, err
+		}
+
+		touched = append(touched, played...)
+	}
+
+	return touched, nil
+}
+
+// RevealBomb reveal the bomb in the given position without losing the game.
+// It is meant for game modes where bombs are claimed by the players instead of avoided.
+func (b *Board) RevealBomb(pos SquarePosition) error {
+	if b.Status == STATUS_LOST {
+		return errors.New(apperrors.InvalidInput, nil, "cannot play a square on a finished game", "")
+	}
+
+	if !b.VerifyRange(pos) {
+		return errors.New(apperrors.InvalidInput, nil, "invalid square", "")
+	}
+
+	if !b.Is(pos, BOMB) {
+		return errors.New(apperrors.InvalidInput, nil, "there is no bomb in the square", "")
+	}
+
+	b.Get(pos).Revealed = true
+	b.Get(pos).Marked = false
+
+	return nil
+}
+
+// Clone return a copy of the board sharing nothing with it, so that changes on either of them are not seen by the other
+func (b Board) Clone() Board {
+	clone := b
+
+	if b.Squares != nil {
+		clone.Squares = make([][]Square, len(b.Squares))
+		for i := range b.Squares {
+			clone.Squares[i] = append([]Square(nil), b.Squares[i]...)
+		}
+	}
+
+	if b.BombsPositions != nil {
+		positions := append([]SquarePosition(nil), *b.BombsPositions...)
+		if positions == nil {
+			positions = []SquarePosition{}
+		}
+
+		clone.BombsPositions = &positions
+	}
+
+	if b.FirstMoveDone != nil {
+		clone.FirstMoveDone = newBool(*b.FirstMoveDone)
+	}
+
+	return clone
+}
+
 // Obfuscate hide internal representation. Hide bombs positions, number of bombs, etc
 func (b *Board) Obfuscate() {
 	for _, pos := range *b.BombsPositions {
@@ -220,6 +376,34 @@ func (b *Board) Obfuscate() {
 	b.FirstMoveDone = nil
 }
 
+// startingPosition pick a safe square to be revealed before the first move.
+// Squares without adjacent bombs are preferred since they open a whole area.
+func (b *Board) startingPosition(r *rand.Rand) SquarePosition {
+	safe := []SquarePosition{}
+	open := []SquarePosition{}
+
+	for i := range b.Squares {
+		for j := range b.Squares[i] {
+			pos := SquarePosition{Row: i, Column: j}
+			if b.Is(pos, BOMB) {
+				continue
+			}
+
+			safe = append(safe, pos)
+
+			if !b.HasNeighborBomb(pos) {
+				open = append(open, pos)
+			}
+		}
+	}
+
+	if len(open) > 0 {
+		return open[r.Intn(len(open))]
+	}
+
+	return safe[r.Intn(len(safe))]
+}
+
 // ### HELPER FUNCTIONS ### //
 
 func generateRandomPositions(n int, max int) ([]int, int) {
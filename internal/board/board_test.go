@@ -279,8 +279,231 @@ func TestBoard_PlaySquare(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.input.board.PlaySquare(tt.input.pos)
+			_, err := tt.input.board.PlaySquare(tt.input.pos)
 			tt.verify(t, tt.input, err)
 		})
 	}
 }
+
+func TestBoard_PlaySquareTouched(t *testing.T) {
+	type input struct {
+		board *board.Board
+		pos   board.SquarePosition
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, in input, touched []board.SquarePosition, err error)
+	}{
+		{
+			name:   "reveal in cascade",
+			should: "report every square revealed by the cascade",
+			input: input{
+				board: newChordBoard(),
+				pos:   board.SquarePosition{0, 3},
+			},
+			verify: func(t *testing.T, in input, touched []board.SquarePosition, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, touched, in.board.RevealedSquaresCount-1)
+				assert.Contains(t, touched, in.pos)
+
+				for _, pos := range touched {
+					assert.True(t, in.board.Get(pos).Revealed)
+				}
+			},
+		},
+		{
+			name:   "reveal a bomb",
+			should: "report the played square and every other bomb",
+			input: input{
+				board: newChordBoard(board.SquarePosition{3, 3}),
+				pos:   board.SquarePosition{0, 0},
+			},
+			verify: func(t *testing.T, in input, touched []board.SquarePosition, err error) {
+				assert.Nil(t, err)
+				assert.ElementsMatch(t, []board.SquarePosition{{0, 0}, {3, 3}}, touched)
+			},
+		},
+		{
+			name:   "revealed square",
+			should: "report nothing",
+			input: input{
+				board: newChordBoard(),
+				pos:   board.SquarePosition{2, 2},
+			},
+			verify: func(t *testing.T, in input, touched []board.SquarePosition, err error) {
+				assert.Nil(t, err)
+				assert.Empty(t, touched)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			touched, err := tt.input.board.PlaySquare(tt.input.pos)
+			tt.verify(t, tt.input, touched, err)
+		})
+	}
+}
+
+func TestBoard_ChordSquare(t *testing.T) {
+	type input struct {
+		board *board.Board
+		pos   board.SquarePosition
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, in input, err error)
+	}{
+		{
+			name:   "as many marks as bombs",
+			should: "reveal every unmarked neighbor",
+			input: input{
+				board: newChordBoard(board.SquarePosition{3, 3}),
+				pos:   board.SquarePosition{2, 2},
+			},
+			verify: func(t *testing.T, in input, err error) {
+				assert.Nil(t, err)
+				assert.NotEqual(t, board.STATUS_LOST, in.board.Status)
+
+				for _, n := range []board.SquarePosition{{1, 1}, {1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}} {
+					assert.True(t, in.board.Get(n).Revealed)
+				}
+
+				assert.False(t, in.board.Get(board.SquarePosition{3, 3}).Revealed)
+				assert.True(t, in.board.Get(board.SquarePosition{3, 3}).Marked)
+			},
+		},
+		{
+			name:   "fewer marks than bombs",
+			should: "reveal nothing",
+			input: input{
+				board: newChordBoard(),
+				pos:   board.SquarePosition{2, 2},
+			},
+			verify: func(t *testing.T, in input, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, in.board.RevealedSquaresCount)
+				assert.Equal(t, board.STATUS_ON_GOING, in.board.Status)
+			},
+		},
+		{
+			name:   "wrong mark",
+			should: "reveal the unmarked bomb and lose the game",
+			input: input{
+				board: newChordBoard(board.SquarePosition{3, 2}),
+				pos:   board.SquarePosition{2, 2},
+			},
+			verify: func(t *testing.T, in input, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, board.STATUS_LOST, in.board.Status)
+			},
+		},
+		{
+			name:   "unrevealed square",
+			should: "return an invalid input error",
+			input: input{
+				board: newChordBoard(),
+				pos:   board.SquarePosition{3, 2},
+			},
+			verify: func(t *testing.T, in input, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.input.board.ChordSquare(tt.input.pos)
+			tt.verify(t, tt.input, err)
+		})
+	}
+}
+
+// newChordBoard return a 4x4 on going board with bombs at (0,0) and (3,3), (2,2) revealed and the given squares marked
+func newChordBoard(marked ...board.SquarePosition) *board.Board {
+	_true := true
+
+	b := board.NewBoard(4, 4, 2)
+	b.Status = board.STATUS_ON_GOING
+	b.FirstMoveDone = &_true
+	b.BombsPositions = &[]board.SquarePosition{{0, 0}, {3, 3}}
+
+	for _, pos := range *b.BombsPositions {
+		b.Get(pos).Type = board.BOMB
+	}
+
+	b.Get(board.SquarePosition{2, 2}).Revealed = true
+	b.RevealedSquaresCount = 1
+
+	for _, pos := range marked {
+		b.Get(pos).Marked = true
+	}
+
+	return &b
+}
+
+func TestNewSeededBoard(t *testing.T) {
+	type input struct {
+		rows, columns, bombs int
+		seed                 int64
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, in input, b board.Board)
+	}{
+		{
+			name:   "same seed",
+			should: "generate the same bombs layout and starting reveal",
+			input:  input{rows: 9, columns: 12, bombs: 20, seed: 42},
+			verify: func(t *testing.T, in input, b board.Board) {
+				other := board.NewSeededBoard(in.rows, in.columns, in.bombs, in.seed)
+
+				assert.Equal(t, other.Squares, b.Squares)
+				assert.Equal(t, *other.BombsPositions, *b.BombsPositions)
+			},
+		},
+		{
+			name:   "different seed",
+			should: "generate a different bombs layout",
+			input:  input{rows: 9, columns: 12, bombs: 20, seed: 42},
+			verify: func(t *testing.T, in input, b board.Board) {
+				other := board.NewSeededBoard(in.rows, in.columns, in.bombs, in.seed+1)
+
+				assert.NotEqual(t, *other.BombsPositions, *b.BombsPositions)
+			},
+		},
+		{
+			name:   "starting reveal",
+			should: "place every bomb and reveal a safe square",
+			input:  input{rows: 9, columns: 12, bombs: 20, seed: 7},
+			verify: func(t *testing.T, in input, b board.Board) {
+				assert.Equal(t, board.STATUS_ON_GOING, b.Status)
+				assert.True(t, *b.FirstMoveDone)
+				assert.Len(t, *b.BombsPositions, in.bombs)
+				assert.True(t, b.RevealedSquaresCount > 0)
+
+				for _, pos := range *b.BombsPositions {
+					assert.True(t, b.Is(pos, board.BOMB))
+					assert.False(t, b.Get(pos).Revealed)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := board.NewSeededBoard(tt.input.rows, tt.input.columns, tt.input.bombs, tt.input.seed)
+			tt.verify(t, tt.input, b)
+		})
+	}
+}
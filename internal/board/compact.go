@@ -0,0 +1,92 @@
+package board
+
+import (
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// The compact encoding represents each row of the board as a string with one character per square.
+// The character is the digit made of the bits of the square: its type, 2 when revealed and 4 when marked,
+// so an unrevealed empty square is "0", a revealed empty square is "2" and a marked square "4" or "5".
+
+const (
+	compactRevealed = 2
+	compactMarked   = 4
+)
+
+// CompactBoard is a board whose squares are in the compact encoding. Any other field is kept as is.
+type CompactBoard struct {
+	Squares              []string          `json:"squares"`
+	BombsNumber          int               `json:"bombs_number,omitempty"`
+	BombsPositions       *[]SquarePosition `json:"bombs_positions,omitempty"`
+	Status               string            `json:"status,omitempty"`
+	FirstMoveDone        *bool             `json:"first_move_done,omitempty"`
+	RevealedSquaresCount int               `json:"revealed_squares_count,omitempty"`
+}
+
+// Compact return the board in the compact encoding
+func (b Board) Compact() CompactBoard {
+	rows := make([]string, len(b.Squares))
+
+	for i := range b.Squares {
+		row := make([]byte, len(b.Squares[i]))
+
+		for j, square := range b.Squares[i] {
+			bits := square.Type
+			if square.Revealed {
+				bits |= compactRevealed
+			}
+			if square.Marked {
+				bits |= compactMarked
+			}
+
+			row[j] = byte('0' + bits)
+		}
+
+		rows[i] = string(row)
+	}
+
+	return CompactBoard{
+		Squares:              rows,
+		BombsNumber:          b.BombsNumber,
+		BombsPositions:       b.BombsPositions,
+		Status:               b.Status,
+		FirstMoveDone:        b.FirstMoveDone,
+		RevealedSquaresCount: b.RevealedSquaresCount,
+	}
+}
+
+// Board return the board encoded by the compact board
+func (cb CompactBoard) Board() (Board, error) {
+	squares := make([][]Square, len(cb.Squares))
+
+	for i, row := range cb.Squares {
+		if len(row) != len(cb.Squares[0]) {
+			return Board{}, errors.New(apperrors.InvalidInput, nil, "invalid compact board", "rows of the compact board differ in length")
+		}
+
+		squares[i] = make([]Square, len(row))
+
+		for j := 0; j < len(row); j++ {
+			bits := int(row[j] - '0')
+			if row[j] < '0' || bits > BOMB|compactRevealed|compactMarked {
+				return Board{}, errors.New(apperrors.InvalidInput, nil, "invalid compact board", "unknown square "+string(row[j]))
+			}
+
+			squares[i][j] = Square{
+				Type:     bits & BOMB,
+				Revealed: bits&compactRevealed != 0,
+				Marked:   bits&compactMarked != 0,
+			}
+		}
+	}
+
+	return Board{
+		Squares:              squares,
+		BombsNumber:          cb.BombsNumber,
+		BombsPositions:       cb.BombsPositions,
+		Status:               cb.Status,
+		FirstMoveDone:        cb.FirstMoveDone,
+		RevealedSquaresCount: cb.RevealedSquaresCount,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package board_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactBoard_RoundTrip(t *testing.T) {
+	played := board.NewBoard(16, 16, 40)
+	played.PlaySquare(board.SquarePosition{Row: 8, Column: 8})
+	played.MarkSquare(board.SquarePosition{Row: 0, Column: 0})
+
+	lost := board.NewSeededBoard(9, 9, 10, 3)
+	lost.MarkSquare(board.SquarePosition{Row: 0, Column: 0})
+	lost.PlaySquare((*lost.BombsPositions)[0])
+
+	obfuscated := board.NewSeededBoard(9, 9, 10, 3)
+	obfuscated.Obfuscate()
+
+	tests := []struct {
+		name   string
+		should string
+		input  board.Board
+	}{
+		{name: "new board", should: "keep the board as is", input: board.NewBoard(3, 4, 2)},
+		{name: "seeded board", should: "keep the board as is", input: board.NewSeededBoard(16, 16, 40, 42)},
+		{name: "played board", should: "keep the revealed and marked squares", input: played},
+		{name: "lost board", should: "keep the revealed bombs", input: lost},
+		{name: "obfuscated board", should: "keep the board as is", input: obfuscated},
+		{name: "won board", should: "keep the board as is", input: *mocks.get("won_board")},
+		{name: "on going board", should: "keep the board as is", input: *mocks.get("on_going_board")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := json.Marshal(tt.input)
+			assert.Nil(t, err)
+
+			compact, err := json.Marshal(tt.input.Compact())
+			assert.Nil(t, err)
+
+			decoded := board.CompactBoard{}
+			err = json.Unmarshal(compact, &decoded)
+			assert.Nil(t, err)
+
+			b, err := decoded.Board()
+			assert.Nil(t, err)
+
+			actual, err := json.Marshal(b)
+			assert.Nil(t, err)
+
+			assert.JSONEq(t, string(expected), string(actual))
+			assert.Less(t, len(compact), len(expected))
+		})
+	}
+}
+
+func TestCompactBoard_Board(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		input  board.CompactBoard
+		verify func(t *testing.T, b board.Board, err error)
+	}{
+		{
+			name:   "every square state",
+			should: "decode the type, revealed and marked state of each square",
+			input:  board.CompactBoard{Squares: []string{"0123", "4567"}},
+			verify: func(t *testing.T, b board.Board, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, [][]board.Square{
+					{{Type: board.EMPTY}, {Type: board.BOMB}, {Type: board.EMPTY, Revealed: true}, {Type: board.BOMB, Revealed: true}},
+					{{Type: board.EMPTY, Marked: true}, {Type: board.BOMB, Marked: true}, {Type: board.EMPTY, Revealed: true, Marked: true}, {Type: board.BOMB, Revealed: true, Marked: true}},
+				}, b.Squares)
+			},
+		},
+		{
+			name:   "unknown square",
+			should: "return an invalid input error",
+			input:  board.CompactBoard{Squares: []string{"000", "0x0"}},
+			verify: func(t *testing.T, b board.Board, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "rows of different length",
+			should: "return an invalid input error",
+			input:  board.CompactBoard{Squares: []string{"000", "00"}},
+			verify: func(t *testing.T, b board.Board, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.input.Board()
+			tt.verify(t, b, err)
+		})
+	}
+}
@@ -11,7 +11,7 @@ const (
 )
 
 type Square struct {
-	Type     int  `json:"type"`
+	Type     int  `json:"type" enums:"0,1"`
 	Revealed bool `json:"revealed"`
 	Marked   bool `json:"marked"`
 }
@@ -25,7 +25,7 @@ type Board struct {
 	Squares              [][]Square        `json:"squares"`
 	BombsNumber          int               `json:"bombs_number,omitempty"`
 	BombsPositions       *[]SquarePosition `json:"bombs_positions,omitempty"`
-	Status               string            `json:"status,omitempty"`
+	Status               string            `json:"status,omitempty" enums:"new,on_going,lost,won"`
 	FirstMoveDone        *bool             `json:"first_move_done,omitempty"`
 	RevealedSquaresCount int               `json:"revealed_squares_count,omitempty"`
 }
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/storage"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+const (
+	// COMMAND_EXPORT exports every game of the storage to a file
+	COMMAND_EXPORT string = "export"
+	// COMMAND_IMPORT imports the games of a file into the storage
+	COMMAND_IMPORT string = "import"
+	// COMMAND_MIGRATE upgrades the games stored at an older schema version
+	COMMAND_MIGRATE string = "migrate"
+	// COMMAND_HISTORY writes the past versions of a game to a file
+	COMMAND_HISTORY string = "history"
+)
+
+// Admin is the configuration of the admin commands, which run instead of the server
+type Admin struct {
+	Command string
+	Storage storage.Config
+	// File is the file the games are exported to or imported from, the standard output being used by
+	// the export and the history when it is "-"
+	File string
+	// Compress whether the export is compressed with gzip, imports detecting compressed files by themselves
+	Compress bool
+	// DryRun checks the file and reports what would be imported, without writing anything
+	DryRun bool
+	// GameID is the game whose history is written
+	GameID string
+	// Version is the version of the game the history is limited to, every version being written when negative
+	Version int64
+}
+
+// IsAdminCommand whether the arguments run an admin command rather than the server
+func IsAdminCommand(args []string) bool {
+	return len(args) > 0 && (args[0] == COMMAND_EXPORT || args[0] == COMMAND_IMPORT || args[0] == COMMAND_MIGRATE || args[0] == COMMAND_HISTORY)
+}
+
+// LoadAdmin return the configuration of the command given by the arguments, the first one being the command.
+// The storage is configured the same way as the server's.
+func LoadAdmin(args []string, getenv func(string) string) (Admin, error) {
+	if !IsAdminCommand(args) {
+		return Admin{}, errors.New(apperrors.InvalidInput, nil, "the command must be either export, import, migrate or history", "")
+	}
+
+	config := Admin{Command: args[0]}
+
+	env, durations, ints, err := defaults(getenv)
+	if err != nil {
+		return Admin{}, err
+	}
+
+	flags := flag.NewFlagSet("minesweeper "+config.Command, flag.ContinueOnError)
+
+	storageFlags(flags, &config.Storage, env, durations, ints)
+
+	switch config.Command {
+	case COMMAND_EXPORT:
+		flags.StringVar(&config.File, "out", "", "file the games are exported to, - for the standard output")
+		flags.BoolVar(&config.Compress, "gzip", false, "compress the export with gzip, the default when the file ends with .gz")
+	case COMMAND_IMPORT:
+		flags.StringVar(&config.File, "in", "", "file the games are imported from, compressed or not")
+		flags.BoolVar(&config.DryRun, "dry-run", false, "check the file and report what would be imported without importing it")
+	case COMMAND_HISTORY:
+		flags.StringVar(&config.GameID, "game", "", "id of the game")
+		flags.StringVar(&config.File, "out", "", "file the versions of the game are written to, - for the standard output")
+		flags.Int64Var(&config.Version, "version", -1, "version of the game to write, every version from its creation when negative")
+	}
+
+	err = flags.Parse(args[1:])
+	if err != nil {
+		return Admin{}, errors.New(apperrors.InvalidInput, err, "invalid arguments: "+err.Error(), "")
+	}
+
+	err = validateStorage(config.Storage)
+	if err != nil {
+		return Admin{}, err
+	}
+
+	if config.File == "" && config.Command != COMMAND_MIGRATE {
+		return Admin{}, errors.New(apperrors.InvalidInput, nil, "the "+config.Command+" command requires a file", "")
+	}
+
+	if config.Command == COMMAND_HISTORY && config.GameID == "" {
+		return Admin{}, errors.New(apperrors.InvalidInput, nil, "the history command requires a game", "")
+	}
+
+	// imports are read twice, once to be checked and once to be imported, so they cannot be streamed
+	if config.Command == COMMAND_IMPORT && config.File == "-" {
+		return Admin{}, errors.New(apperrors.InvalidInput, nil, "imports cannot be read from the standard input", "")
+	}
+
+	if config.Command == COMMAND_EXPORT && strings.HasSuffix(config.File, ".gz") {
+		config.Compress = true
+	}
+
+	return config, nil
+}
@@ -0,0 +1,151 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/config"
+	"github.com/matiasvarela/minesweeper/internal/storage"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAdmin(t *testing.T) {
+	type input struct {
+		args []string
+		env  map[string]string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, cfg config.Admin, err error)
+	}{
+		{
+			name:   "export",
+			should: "take the storage from the environment and the file from the flags",
+			input: input{
+				args: []string{"export", "-out", "games.ndjson"},
+				env:  map[string]string{"MINESWEEPER_STORAGE": storage.BACKEND_SQLITE, "MINESWEEPER_DATA_PATH": "/data"},
+			},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, config.COMMAND_EXPORT, cfg.Command)
+				assert.Equal(t, storage.BACKEND_SQLITE, cfg.Storage.Backend)
+				assert.Equal(t, "/data", cfg.Storage.DataPath)
+				assert.Equal(t, "games.ndjson", cfg.File)
+				assert.False(t, cfg.Compress)
+			},
+		},
+		{
+			name:   "compressed export",
+			should: "compress the files ending with .gz",
+			input:  input{args: []string{"export", "-out", "games.ndjson.gz"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.Nil(t, err)
+				assert.True(t, cfg.Compress)
+			},
+		},
+		{
+			name:   "import dry run",
+			should: "take the file and the dry run",
+			input:  input{args: []string{"import", "-storage", storage.BACKEND_BITCASK, "-in", "games.ndjson", "-dry-run"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, config.COMMAND_IMPORT, cfg.Command)
+				assert.Equal(t, "games.ndjson", cfg.File)
+				assert.True(t, cfg.DryRun)
+			},
+		},
+		{
+			name:   "migrate",
+			should: "take the storage without requiring a file",
+			input:  input{args: []string{"migrate", "-data-path", "/data"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, config.COMMAND_MIGRATE, cfg.Command)
+				assert.Equal(t, "/data", cfg.Storage.DataPath)
+			},
+		},
+		{
+			name:   "history",
+			should: "take the game, the version and the file",
+			input:  input{args: []string{"history", "-data-path", "/data", "-game", "123", "-version", "2", "-out", "-"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, config.COMMAND_HISTORY, cfg.Command)
+				assert.Equal(t, "123", cfg.GameID)
+				assert.Equal(t, int64(2), cfg.Version)
+				assert.Equal(t, "-", cfg.File)
+			},
+		},
+		{
+			name:   "history of every version",
+			should: "default to a negative version",
+			input:  input{args: []string{"history", "-game", "123", "-out", "history.ndjson"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, int64(-1), cfg.Version)
+			},
+		},
+		{
+			name:   "history without game",
+			should: "return an invalid input error",
+			input:  input{args: []string{"history", "-out", "-"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "without file",
+			should: "return an invalid input error",
+			input:  input{args: []string{"export"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "import from the standard input",
+			should: "return an invalid input error",
+			input:  input{args: []string{"import", "-in", "-"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "flag of the other command",
+			should: "return an invalid input error",
+			input:  input{args: []string{"export", "-out", "games.ndjson", "-dry-run"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "postgres storage without connection",
+			should: "return an invalid input error",
+			input:  input{args: []string{"import", "-storage", storage.BACKEND_POSTGRES, "-in", "games.ndjson"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "unknown command",
+			should: "return an invalid input error",
+			input:  input{args: []string{"-http-addr", ":80"}},
+			verify: func(t *testing.T, cfg config.Admin, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadAdmin(tt.input.args, func(name string) string {
+				return tt.input.env[name]
+			})
+
+			tt.verify(t, cfg, err)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// Config is the runtime configuration of the server
+type Config struct {
+	HttpAddr        string
+	GrpcAddr        string
+	DailySecret     string
+	ShutdownTimeout time.Duration
+	Storage         storage.Config
+	// Retention is how long games are kept, games being kept forever by default
+	Retention       game.Retention
+	JanitorInterval time.Duration
+	// CacheSize is how many games are cached in memory, the cache being disabled when zero
+	CacheSize int
+	// MetricsAddr is the address the metrics are served on, they are not served when empty
+	MetricsAddr string
+}
+
+// Load return the configuration given by the command line arguments. Every option can be set through an environment
+// variable as well, the arguments taking precedence over the environment.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	config := Config{}
+
+	env, durations, ints, err := defaults(getenv)
+	if err != nil {
+		return Config{}, err
+	}
+
+	flags := flag.NewFlagSet("minesweeper", flag.ContinueOnError)
+
+	flags.StringVar(&config.HttpAddr, "http-addr", env("MINESWEEPER_HTTP_ADDR", ":8080"), "address the http api listens on")
+	flags.StringVar(&config.GrpcAddr, "grpc-addr", env("MINESWEEPER_GRPC_ADDR", ":9090"), "address the grpc api listens on")
+	flags.StringVar(&config.DailySecret, "daily-secret", env("MINESWEEPER_DAILY_SECRET", ""), "secret the daily challenges are derived from, generated and stored in the data directory when empty")
+	flags.DurationVar(&config.ShutdownTimeout, "shutdown-timeout", durations["MINESWEEPER_SHUTDOWN_TIMEOUT"], "time given to the ongoing requests to finish on shutdown")
+	storageFlags(flags, &config.Storage, env, durations, ints)
+
+	flags.DurationVar(&config.Retention.TTL, "game-ttl", durations["MINESWEEPER_GAME_TTL"], "time unfinished games are kept since their last activity, 0 keeps them forever")
+	flags.DurationVar(&config.Retention.Finished, "finished-game-retention", durations["MINESWEEPER_FINISHED_GAME_RETENTION"], "time finished games are kept since they finished, 0 keeps them forever")
+	flags.DurationVar(&config.JanitorInterval, "janitor-interval", durations["MINESWEEPER_JANITOR_INTERVAL"], "time between the deletions of the expired games")
+
+	flags.IntVar(&config.CacheSize, "cache-size", ints["MINESWEEPER_CACHE_SIZE"], "number of games cached in memory, 0 disables the cache")
+	flags.StringVar(&config.MetricsAddr, "metrics-addr", env("MINESWEEPER_METRICS_ADDR", ""), "address the metrics are served on, empty disables them")
+
+	err = flags.Parse(args)
+	if err != nil {
+		return Config{}, errors.New(apperrors.InvalidInput, err, "invalid arguments: "+err.Error(), "")
+	}
+
+	err = validateStorage(config.Storage)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if config.Retention.TTL < 0 || config.Retention.Finished < 0 {
+		return Config{}, errors.New(apperrors.InvalidInput, nil, "the retention of games cannot be negative", "")
+	}
+
+	if config.Retention.Enabled() && config.JanitorInterval <= 0 {
+		return Config{}, errors.New(apperrors.InvalidInput, nil, "the janitor interval must be positive", "")
+	}
+
+	if config.CacheSize < 0 {
+		return Config{}, errors.New(apperrors.InvalidInput, nil, "the cache size cannot be negative", "")
+	}
+
+	return config, nil
+}
+
+// defaults return the lookup of the environment variables, along with the durations and the numbers they hold
+func defaults(getenv func(string) string) (func(name string, fallback string) string, map[string]time.Duration, map[string]int, error) {
+	env := func(name string, fallback string) string {
+		if value := getenv(name); value != "" {
+			return value
+		}
+
+		return fallback
+	}
+
+	durations := map[string]time.Duration{}
+	for name, fallback := range map[string]string{
+		"MINESWEEPER_SHUTDOWN_TIMEOUT":            "10s",
+		"MINESWEEPER_POSTGRES_CONN_MAX_LIFETIME":  "30m",
+		"MINESWEEPER_POSTGRES_CONN_MAX_IDLE_TIME": "5m",
+		"MINESWEEPER_GAME_TTL":                    "0",
+		"MINESWEEPER_FINISHED_GAME_RETENTION":     "0",
+		"MINESWEEPER_JANITOR_INTERVAL":            "1h",
+	} {
+		duration, err := time.ParseDuration(env(name, fallback))
+		if err != nil {
+			return nil, nil, nil, errors.New(apperrors.InvalidInput, err, "invalid "+name, "")
+		}
+
+		durations[name] = duration
+	}
+
+	ints := map[string]int{}
+	for name, fallback := range map[string]string{
+		"MINESWEEPER_POSTGRES_MAX_OPEN_CONNS": "20",
+		"MINESWEEPER_POSTGRES_MAX_IDLE_CONNS": "5",
+		"MINESWEEPER_CACHE_SIZE":              "0",
+		"MINESWEEPER_SNAPSHOT_INTERVAL":       "50",
+	} {
+		n, err := strconv.Atoi(env(name, fallback))
+		if err != nil {
+			return nil, nil, nil, errors.New(apperrors.InvalidInput, err, "invalid "+name, "")
+		}
+
+		ints[name] = n
+	}
+
+	return env, durations, ints, nil
+}
+
+// storageFlags defines the flags selecting the storage backend along with its options
+func storageFlags(flags *flag.FlagSet, cfg *storage.Config, env func(string, string) string, durations map[string]time.Duration, ints map[string]int) {
+	flags.StringVar(&cfg.Backend, "storage", env("MINESWEEPER_STORAGE", storage.BACKEND_BITCASK), "storage backend, either bitcask, sqlite, postgres, events or memory")
+	flags.StringVar(&cfg.DataPath, "data-path", env("MINESWEEPER_DATA_PATH", "/tmp"), "directory the databases are stored in")
+	flags.StringVar(&cfg.Postgres.DSN, "postgres-dsn", env("MINESWEEPER_POSTGRES_DSN", ""), "connection string of the postgres database")
+	flags.IntVar(&cfg.Postgres.MaxOpenConns, "postgres-max-open-conns", ints["MINESWEEPER_POSTGRES_MAX_OPEN_CONNS"], "max number of open connections to postgres")
+	flags.IntVar(&cfg.Postgres.MaxIdleConns, "postgres-max-idle-conns", ints["MINESWEEPER_POSTGRES_MAX_IDLE_CONNS"], "max number of idle connections to postgres")
+	flags.DurationVar(&cfg.Postgres.ConnMaxLifetime, "postgres-conn-max-lifetime", durations["MINESWEEPER_POSTGRES_CONN_MAX_LIFETIME"], "max time a connection to postgres is reused")
+	flags.DurationVar(&cfg.Postgres.ConnMaxIdleTime, "postgres-conn-max-idle-time", durations["MINESWEEPER_POSTGRES_CONN_MAX_IDLE_TIME"], "max time a connection to postgres is kept idle")
+	flags.IntVar(&cfg.SnapshotInterval, "snapshot-interval", ints["MINESWEEPER_SNAPSHOT_INTERVAL"], "number of events between the snapshots of the games of the events storage")
+}
+
+func validateStorage(cfg storage.Config) error {
+	if cfg.Backend == storage.BACKEND_POSTGRES && cfg.Postgres.DSN == "" {
+		return errors.New(apperrors.InvalidInput, nil, "the postgres storage requires a connection string", "")
+	}
+
+	if cfg.SnapshotInterval <= 0 {
+		return errors.New(apperrors.InvalidInput, nil, "the snapshot interval must be positive", "")
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/config"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage"
+	"github.com/matiasvarela/minesweeper/internal/storage/pgsto"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	type input struct {
+		args []string
+		env  map[string]string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, cfg config.Config, err error)
+	}{
+		{
+			name:   "no options",
+			should: "return the defaults",
+			input:  input{},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, config.Config{
+					HttpAddr:        ":8080",
+					GrpcAddr:        ":9090",
+					ShutdownTimeout: 10 * time.Second,
+					Storage: storage.Config{
+						Backend:          storage.BACKEND_BITCASK,
+						DataPath:         "/tmp",
+						Postgres:         pgsto.Config{MaxOpenConns: 20, MaxIdleConns: 5, ConnMaxLifetime: 30 * time.Minute, ConnMaxIdleTime: 5 * time.Minute},
+						SnapshotInterval: 50,
+					},
+					JanitorInterval: time.Hour,
+				}, cfg)
+			},
+		},
+		{
+			name:   "environment variables",
+			should: "take the options from the environment",
+			input: input{env: map[string]string{
+				"MINESWEEPER_STORAGE":          storage.BACKEND_MEMORY,
+				"MINESWEEPER_DATA_PATH":        "/var/lib/minesweeper",
+				"MINESWEEPER_SHUTDOWN_TIMEOUT": "3s",
+				"MINESWEEPER_DAILY_SECRET":     "secret",
+			}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, storage.BACKEND_MEMORY, cfg.Storage.Backend)
+				assert.Equal(t, "/var/lib/minesweeper", cfg.Storage.DataPath)
+				assert.Equal(t, 3*time.Second, cfg.ShutdownTimeout)
+				assert.Equal(t, "secret", cfg.DailySecret)
+			},
+		},
+		{
+			name:   "flags and environment variables",
+			should: "give precedence to the flags",
+			input: input{
+				args: []string{"-data-path", "/data", "-http-addr", ":80"},
+				env:  map[string]string{"MINESWEEPER_DATA_PATH": "/var/lib/minesweeper"},
+			},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "/data", cfg.Storage.DataPath)
+				assert.Equal(t, ":80", cfg.HttpAddr)
+			},
+		},
+		{
+			name:   "retention",
+			should: "take the retention of the games",
+			input: input{
+				args: []string{"-game-ttl", "72h", "-janitor-interval", "10m"},
+				env:  map[string]string{"MINESWEEPER_FINISHED_GAME_RETENTION": "720h"},
+			},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, game.Retention{TTL: 72 * time.Hour, Finished: 720 * time.Hour}, cfg.Retention)
+				assert.Equal(t, 10*time.Minute, cfg.JanitorInterval)
+			},
+		},
+		{
+			name:   "negative retention",
+			should: "return an invalid input error",
+			input:  input{args: []string{"-game-ttl", "-1h"}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "cache and metrics",
+			should: "take the size of the cache and the address of the metrics",
+			input: input{
+				args: []string{"-cache-size", "1000"},
+				env:  map[string]string{"MINESWEEPER_METRICS_ADDR": "localhost:9100"},
+			},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 1000, cfg.CacheSize)
+				assert.Equal(t, "localhost:9100", cfg.MetricsAddr)
+			},
+		},
+		{
+			name:   "negative cache size",
+			should: "return an invalid input error",
+			input:  input{args: []string{"-cache-size", "-1"}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "postgres storage",
+			should: "take the connection and the pool configuration",
+			input: input{
+				args: []string{"-storage", "postgres", "-postgres-max-open-conns", "50"},
+				env: map[string]string{
+					"MINESWEEPER_POSTGRES_DSN":               "postgres://minesweeper@localhost/minesweeper",
+					"MINESWEEPER_POSTGRES_MAX_OPEN_CONNS":    "10",
+					"MINESWEEPER_POSTGRES_CONN_MAX_LIFETIME": "1h",
+				},
+			},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, pgsto.Config{
+					DSN:             "postgres://minesweeper@localhost/minesweeper",
+					MaxOpenConns:    50,
+					MaxIdleConns:    5,
+					ConnMaxLifetime: time.Hour,
+					ConnMaxIdleTime: 5 * time.Minute,
+				}, cfg.Storage.Postgres)
+			},
+		},
+		{
+			name:   "postgres storage without connection",
+			should: "return an invalid input error",
+			input:  input{args: []string{"-storage", "postgres"}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "events storage",
+			should: "take the snapshot interval",
+			input: input{
+				args: []string{"-storage", "events"},
+				env:  map[string]string{"MINESWEEPER_SNAPSHOT_INTERVAL": "20"},
+			},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, storage.BACKEND_EVENTS, cfg.Storage.Backend)
+				assert.Equal(t, 20, cfg.Storage.SnapshotInterval)
+			},
+		},
+		{
+			name:   "events storage without snapshots",
+			should: "return an invalid input error",
+			input:  input{args: []string{"-storage", "events", "-snapshot-interval", "0"}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "invalid number of connections",
+			should: "return an invalid input error",
+			input:  input{env: map[string]string{"MINESWEEPER_POSTGRES_MAX_OPEN_CONNS": "many"}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "unknown flag",
+			should: "return an invalid input error",
+			input:  input{args: []string{"-unknown"}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "invalid timeout",
+			should: "return an invalid input error",
+			input:  input{env: map[string]string{"MINESWEEPER_SHUTDOWN_TIMEOUT": "soon"}},
+			verify: func(t *testing.T, cfg config.Config, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Load(tt.input.args, func(name string) string {
+				return tt.input.env[name]
+			})
+
+			tt.verify(t, cfg, err)
+		})
+	}
+}
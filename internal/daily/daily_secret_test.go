@@ -0,0 +1,75 @@
+package daily_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		mock   func(t *testing.T, dir string)
+		verify func(t *testing.T, dir string, secret string, err error)
+	}{
+		{
+			name:   "first run",
+			should: "generate a random secret and keep it for the next runs",
+			mock:   func(t *testing.T, dir string) {},
+			verify: func(t *testing.T, dir string, secret string, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, secret, 64)
+
+				info, err := os.Stat(filepath.Join(dir, daily.SecretFile))
+				assert.Nil(t, err)
+				assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+				again, err := daily.LoadSecret(dir)
+				assert.Nil(t, err)
+				assert.Equal(t, secret, again)
+
+				other, err := daily.LoadSecret(t.TempDir())
+				assert.Nil(t, err)
+				assert.NotEqual(t, secret, other)
+			},
+		},
+		{
+			name:   "stored secret",
+			should: "return the secret of the file",
+			mock: func(t *testing.T, dir string) {
+				assert.Nil(t, os.WriteFile(filepath.Join(dir, daily.SecretFile), []byte("secret\n"), 0600))
+			},
+			verify: func(t *testing.T, dir string, secret string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "secret", secret)
+			},
+		},
+		{
+			name:   "empty file",
+			should: "return an internal error instead of an empty secret",
+			mock: func(t *testing.T, dir string) {
+				assert.Nil(t, os.WriteFile(filepath.Join(dir, daily.SecretFile), nil, 0600))
+			},
+			verify: func(t *testing.T, dir string, secret string, err error) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.mock(t, dir)
+
+			secret, err := daily.LoadSecret(dir)
+
+			tt.verify(t, dir, secret, err)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package daily_test
+
+import (
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	service          daily.Service
+	gameService      game.Service
+	fakeGameStorage  *fakesto.GameStorage
+	fakeDailyStorage *fakesto.DailyStorage
+)
+
+func init() {
+	fakeGameStorage = fakesto.NewGameStorage()
+	fakeDailyStorage = fakesto.NewDailyStorage()
+	gameService = game.NewService(fakeGameStorage, game.NewHub())
+	service = daily.NewService(gameService, fakeDailyStorage, "secret")
+}
+
+func TestGet(t *testing.T) {
+	type input struct {
+		playerID string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, g game.Game, err error)
+	}{
+		{
+			name:   "first request of the day",
+			should: "create the daily game for the player with a square already revealed",
+			input:  input{"alice"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.NotEmpty(t, g.ID)
+				assert.Equal(t, in.playerID, g.PlayerID)
+				assert.Equal(t, board.STATUS_ON_GOING, g.Board.Status)
+				assert.True(t, g.Board.RevealedSquaresCount > 0)
+			},
+		},
+		{
+			name:   "second request of the day",
+			should: "return the same game, so the player has a single attempt",
+			input:  input{"alice"},
+			mock: func() {
+				_, _ = service.Get("alice")
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+
+				other, _ := service.Get(in.playerID)
+				assert.Equal(t, g.ID, other.ID)
+			},
+		},
+		{
+			name:   "different players",
+			should: "create different games with the same bombs layout",
+			input:  input{"alice"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+
+				other, err := service.Get("bob")
+				assert.Nil(t, err)
+				assert.NotEqual(t, g.ID, other.ID)
+				assert.Equal(t, *g.Board.BombsPositions, *other.Board.BombsPositions)
+				assert.Equal(t, g.Board.Squares, other.Board.Squares)
+			},
+		},
+		{
+			name:   "deleted game",
+			should: "return a forbidden error instead of a new attempt",
+			input:  input{"alice"},
+			mock: func() {
+				g, _ := service.Get("alice")
+				_ = gameService.Delete(g.ID, "alice")
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.True(t, errors.Is(err, apperrors.Forbidden))
+				assert.Equal(t, game.Game{}, g)
+			},
+		},
+		{
+			name:   "get entry fails",
+			should: "fail when trying to get the daily entry",
+			input:  input{"alice"},
+			mock: func() {
+				fakeDailyStorage.AddErrorOnGet(errors.New(apperrors.Internal, nil, "fail", ""))
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.Internal))
+				assert.Equal(t, game.Game{}, g)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGameStorage.CleanDB()
+			fakeGameStorage.CleanErrors()
+			fakeDailyStorage.CleanDB()
+			fakeDailyStorage.CleanErrors()
+
+			tt.mock()
+
+			g, err := service.Get(tt.input.playerID)
+
+			tt.verify(t, tt.input, g, err)
+		})
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	type input struct {
+		date string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, l daily.Leaderboard, err error)
+	}{
+		{
+			name:   "ranking",
+			should: "rank won games by elapsed time followed by lost games, skipping unfinished ones",
+			input:  input{""},
+			mock: func() {
+				for _, p := range []struct {
+					playerID    string
+					status      string
+					elapsedTime int64
+				}{
+					{"slow", board.STATUS_WON, 300},
+					{"loser", board.STATUS_LOST, 10},
+					{"playing", board.STATUS_ON_GOING, 0},
+					{"fast", board.STATUS_WON, 100},
+				} {
+					g, _ := service.Get(p.playerID)
+					g.Board.Status = p.status
+					g.FinishedAt = g.StartedAt + p.elapsedTime
+					_ = fakeGameStorage.Update(g)
+				}
+			},
+			verify: func(t *testing.T, in input, l daily.Leaderboard, err error) {
+				assert.Nil(t, err)
+				assert.NotEmpty(t, l.Date)
+				assert.Len(t, l.Results, 3)
+
+				assert.Equal(t, "fast", l.Results[0].PlayerID)
+				assert.Equal(t, int64(100), l.Results[0].ElapsedTime)
+				assert.Equal(t, 1, l.Results[0].Rank)
+				assert.Equal(t, "slow", l.Results[1].PlayerID)
+				assert.Equal(t, "loser", l.Results[2].PlayerID)
+				assert.Equal(t, 3, l.Results[2].Rank)
+			},
+		},
+		{
+			name:   "expired game",
+			should: "skip the game",
+			input:  input{""},
+			mock: func() {
+				for _, playerID := range []string{"expired", "winner"} {
+					g, _ := service.Get(playerID)
+					g.Board.Status = board.STATUS_WON
+					g.FinishedAt = g.StartedAt + 100
+					_ = fakeGameStorage.Update(g)
+				}
+
+				g, _ := service.Get("expired")
+				_ = fakeGameStorage.Delete(g.ID)
+			},
+			verify: func(t *testing.T, in input, l daily.Leaderboard, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, l.Results, 1)
+				assert.Equal(t, "winner", l.Results[0].PlayerID)
+			},
+		},
+		{
+			name:   "today's leaderboard",
+			should: "leave out the games, whose layout is revealed once lost",
+			input:  input{""},
+			mock: func() {
+				g, _ := service.Get("loser")
+				g.Board.Status = board.STATUS_LOST
+				_ = fakeGameStorage.Update(g)
+			},
+			verify: func(t *testing.T, in input, l daily.Leaderboard, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, l.Results, 1)
+				assert.Equal(t, "loser", l.Results[0].PlayerID)
+				assert.Empty(t, l.Results[0].GameID)
+			},
+		},
+		{
+			name:   "leaderboard of a past day",
+			should: "give the games, since the day is over",
+			input:  input{"2020-05-01"},
+			mock: func() {
+				g, _ := gameService.CreateSeeded(daily.Configuration, 1, "loser", 0)
+				g.Board.Status = board.STATUS_LOST
+				_ = fakeGameStorage.Update(g)
+				_ = fakeDailyStorage.Create(daily.Entry{Date: "2020-05-01", PlayerID: "loser", GameID: g.ID})
+			},
+			verify: func(t *testing.T, in input, l daily.Leaderboard, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, l.Results, 1)
+
+				entry, _ := fakeDailyStorage.Get(in.date, "loser")
+				assert.Equal(t, entry.GameID, l.Results[0].GameID)
+			},
+		},
+		{
+			name:   "invalid date",
+			should: "return an invalid input error",
+			input:  input{"yesterday"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, l daily.Leaderboard, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGameStorage.CleanDB()
+			fakeGameStorage.CleanErrors()
+			fakeDailyStorage.CleanDB()
+			fakeDailyStorage.CleanErrors()
+
+			tt.mock()
+
+			l, err := service.GetLeaderboard(tt.input.date)
+
+			tt.verify(t, tt.input, l, err)
+		})
+	}
+}
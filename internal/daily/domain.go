@@ -0,0 +1,35 @@
+package daily
+
+import "github.com/matiasvarela/minesweeper/internal/game"
+
+const (
+	// DateLayout is the layout used to identify a daily challenge
+	DateLayout = "2006-01-02"
+)
+
+var (
+	// Configuration is the board configuration shared by every daily challenge
+	Configuration = game.Configuration{Rows: 16, Columns: 16, Bombs: 40}
+)
+
+// Entry binds a player to the game created for its ranked attempt of a given day
+type Entry struct {
+	Date     string `json:"date"`
+	PlayerID string `json:"player_id"`
+	GameID   string `json:"game_id"`
+}
+
+type Result struct {
+	Rank     int    `json:"rank"`
+	PlayerID string `json:"player_id"`
+	// GameID is only given once the day is over, since lost games reveal the bombs layout shared by every player
+	GameID               string `json:"game_id,omitempty"`
+	Status               string `json:"status"`
+	ElapsedTime          int64  `json:"elapsed_time"`
+	RevealedSquaresCount int    `json:"revealed_squares_count"`
+}
+
+type Leaderboard struct {
+	Date    string   `json:"date"`
+	Results []Result `json:"results"`
+}
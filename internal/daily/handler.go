@@ -0,0 +1,53 @@
+package daily
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+type HttpHandler interface {
+	Get(*gin.Context)
+	GetLeaderboard(*gin.Context)
+}
+
+type httpHandler struct {
+	service Service
+}
+
+func NewHttpHandler(service Service) HttpHandler {
+	return &httpHandler{service}
+}
+
+func (h *httpHandler) Get(c *gin.Context) {
+	playerID, err := player.FromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	game, err := h.service.Get(playerID)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	game.UpdateElapsedTime()
+	game.Board.Obfuscate()
+	game.Moves = nil
+
+	c.JSON(200, game)
+}
+
+func (h *httpHandler) GetLeaderboard(c *gin.Context) {
+	leaderboard, err := h.service.GetLeaderboard(c.Query("date"))
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	c.JSON(200, leaderboard)
+}
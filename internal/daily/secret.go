@@ -0,0 +1,72 @@
+package daily
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// SecretFile is the name of the file within the data directory holding the generated secret
+const SecretFile = "daily-secret"
+
+// LoadSecret return the secret stored in the data directory, generating it on the first run. Without a secret
+// the layouts of the daily challenges could be computed by anyone, so a random one is kept for the servers
+// which are not given any.
+func LoadSecret(dir string) (string, error) {
+	path := filepath.Join(dir, SecretFile)
+
+	bytes, err := os.ReadFile(path)
+	if err == nil {
+		secret := strings.TrimSpace(string(bytes))
+		if secret == "" {
+			return "", errors.New(apperrors.Internal, nil, "internal error", "daily secret file "+path+" is empty")
+		}
+
+		return secret, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", errors.New(apperrors.Internal, err, "internal error", "read daily secret has failed")
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.New(apperrors.Internal, err, "internal error", "generate daily secret has failed")
+	}
+
+	secret := hex.EncodeToString(random)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.New(apperrors.Internal, err, "internal error", "create data directory has failed")
+	}
+
+	file, err := os.CreateTemp(dir, SecretFile+"-*")
+	if err != nil {
+		return "", errors.New(apperrors.Internal, err, "internal error", "create daily secret has failed")
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(secret + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.New(apperrors.Internal, err, "internal error", "write daily secret has failed")
+	}
+
+	// the secret is linked once written, so that servers started at the same time agree on the first one
+	err = os.Link(file.Name(), path)
+	if os.IsExist(err) {
+		return LoadSecret(dir)
+	}
+	if err != nil {
+		return "", errors.New(apperrors.Internal, err, "internal error", "store daily secret has failed")
+	}
+
+	return secret, nil
+}
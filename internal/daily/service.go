@@ -0,0 +1,149 @@
+package daily
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+type Service interface {
+	Get(playerID string) (game.Game, error)
+	GetLeaderboard(date string) (Leaderboard, error)
+}
+
+type service struct {
+	games   game.Service
+	storage Storage
+	secret  string
+	mutex   sync.Mutex
+}
+
+func NewService(games game.Service, storage Storage, secret string) Service {
+	return &service{games: games, storage: storage, secret: secret}
+}
+
+// Get return the game for today's challenge of the given player, creating it on its first request.
+// A player has exactly one game per day, so it is its only ranked attempt: once its game has been deleted,
+// either by its owner or because it has expired, the player cannot play the challenge again that day.
+func (s *service) Get(playerID string) (game.Game, error) {
+	date := today()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, err := s.storage.Get(date, playerID)
+	if err == nil {
+		g, err := s.games.Get(entry.GameID)
+		if errors.Is(err, apperrors.NotFound) {
+			return game.Game{}, errors.New(apperrors.Forbidden, err, "today's challenge has already been played and its game has been deleted", "daily game not found")
+		}
+
+		return g, err
+	}
+
+	if !errors.Is(err, apperrors.NotFound) {
+		return game.Game{}, errors.New(apperrors.Internal, err, "internal error", "get daily entry from storage has failed")
+	}
+
+	g, err := s.games.CreateSeeded(Configuration, s.seed(date), playerID, 0)
+	if err != nil {
+		return game.Game{}, errors.Wrap(err, err.Error())
+	}
+
+	err = s.storage.Create(Entry{Date: date, PlayerID: playerID, GameID: g.ID})
+	if err != nil {
+		return game.Game{}, errors.New(apperrors.Internal, err, "internal error", "create daily entry into storage has failed")
+	}
+
+	return g, nil
+}
+
+// GetLeaderboard return the finished games of the given day. Won games are ranked first by elapsed time,
+// followed by lost games ranked by the number of revealed squares. The games are left out of the results of
+// the days which are not over, so that the layout revealed by lost games cannot be read while it is played.
+func (s *service) GetLeaderboard(date string) (Leaderboard, error) {
+	current := today()
+	if date == "" {
+		date = current
+	}
+
+	if _, err := time.Parse(DateLayout, date); err != nil {
+		return Leaderboard{}, errors.New(apperrors.InvalidInput, err, "invalid date", "date does not match layout "+DateLayout)
+	}
+
+	entries, err := s.storage.GetByDate(date)
+	if err != nil {
+		return Leaderboard{}, errors.New(apperrors.Internal, err, "internal error", "get daily entries from storage has failed")
+	}
+
+	results := []Result{}
+
+	for _, entry := range entries {
+		g, err := s.games.Get(entry.GameID)
+		if errors.Is(err, apperrors.NotFound) {
+			// the game has expired or has been deleted by its owner
+			continue
+		}
+
+		if err != nil {
+			return Leaderboard{}, errors.Wrap(err, err.Error())
+		}
+
+		if !g.IsFinished() {
+			continue
+		}
+
+		g.UpdateElapsedTime()
+
+		result := Result{
+			PlayerID:             entry.PlayerID,
+			Status:               g.Board.Status,
+			ElapsedTime:          g.ElapsedTime,
+			RevealedSquaresCount: g.Board.RevealedSquaresCount,
+		}
+
+		if date < current {
+			result.GameID = g.ID
+		}
+
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Status != results[j].Status {
+			return results[i].Status == board.STATUS_WON
+		}
+
+		if results[i].Status == board.STATUS_WON {
+			return results[i].ElapsedTime < results[j].ElapsedTime
+		}
+
+		return results[i].RevealedSquaresCount > results[j].RevealedSquaresCount
+	})
+
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+
+	return Leaderboard{Date: date, Results: results}, nil
+}
+
+// seed derive the bombs layout seed of the given day from the server secret
+func (s *service) seed(date string) int64 {
+	sum := sha256.Sum256([]byte(s.secret + ":" + date))
+
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+// Helper
+
+func today() string {
+	return time.Now().UTC().Format(DateLayout)
+}
@@ -0,0 +1,7 @@
+package daily
+
+type Storage interface {
+	Create(e Entry) error
+	Get(date string, playerID string) (Entry, error)
+	GetByDate(date string) ([]Entry, error)
+}
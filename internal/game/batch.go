@@ -0,0 +1,69 @@
+package game
+
+import (
+	"reflect"
+
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"gopkg.in/go-playground/validator.v8"
+)
+
+// Batch is a request to apply several moves on a game at once
+type Batch struct {
+	PlayerID string
+	Moves    []BatchMove
+	// IfVersion, when set, makes the batch fail unless the game is at the given version
+	IfVersion *int64
+}
+
+// BatchMove is a move of a batch. Type is either MOVE_PLAY_SQUARE, MOVE_MARK_SQUARE or MOVE_CHORD_SQUARE,
+// and Mark works the same as in Action for mark moves.
+type BatchMove struct {
+	Type     string
+	Position board.SquarePosition
+	Mark     string
+}
+
+// BatchResult is the outcome of a batch: the moves applied, in order, the number of moves skipped because
+// the game had already finished, and the resulting game.
+type BatchResult struct {
+	Moves   []Move `json:"moves"`
+	Skipped int    `json:"skipped"`
+	Game    Game   `json:"game"`
+}
+
+// BatchDelta is the outcome of a batch without the whole board
+type BatchDelta struct {
+	Moves   []Move `json:"moves"`
+	Skipped int    `json:"skipped"`
+	Delta   Delta  `json:"delta"`
+}
+
+// batchView is the reply of a batch, holding the game in the encoding requested by the client
+type batchView struct {
+	Moves   []Move      `json:"moves"`
+	Skipped int         `json:"skipped"`
+	Game    interface{} `json:"game"`
+}
+
+type MovesBody struct {
+	Moves []MoveBody `json:"moves" validate:"required,min=1,max=1000,dive"`
+}
+
+type MoveBody struct {
+	Type   string `json:"type" validate:"required" enums:"play_square,mark_square,chord_square"`
+	Row    int    `json:"row" validate:"gte=0"`
+	Column int    `json:"column" validate:"gte=0"`
+	Mark   string `json:"mark" enums:"flag,none"`
+}
+
+func MoveBodyStructValidation(v *validator.Validate, structLevel *validator.StructLevel) {
+	body := structLevel.CurrentStruct.Interface().(MoveBody)
+
+	if body.Type != MOVE_PLAY_SQUARE && body.Type != MOVE_MARK_SQUARE && body.Type != MOVE_CHORD_SQUARE {
+		structLevel.ReportError(reflect.ValueOf(body.Type), "Type", "type", "type")
+	}
+
+	if body.Mark != "" && (body.Type != MOVE_MARK_SQUARE || (body.Mark != MARK_FLAG && body.Mark != MARK_NONE)) {
+		structLevel.ReportError(reflect.ValueOf(body.Mark), "Mark", "mark", "mark")
+	}
+}
@@ -4,27 +4,150 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/matiasvarela/errors"
 	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
 	"gopkg.in/go-playground/validator.v8"
 )
 
+const (
+	MODE_CLASSIC string = "classic"
+	MODE_COOP    string = "coop"
+	MODE_VERSUS  string = "versus"
+)
+
 type Game struct {
-	ID          string      `json:"id"`
-	Board       board.Board `json:"board"`
-	StartedAt   int64       `json:"started_at"`
-	ElapsedTime int64       `json:"elapsed_time"`
+	ID          string        `json:"id"`
+	Mode        string        `json:"mode,omitempty"`
+	PlayerID    string        `json:"player_id,omitempty"`
+	Players     []Participant `json:"players,omitempty"`
+	Status      string        `json:"status,omitempty"`
+	Turn        string        `json:"turn,omitempty"`
+	Winner      string        `json:"winner,omitempty"`
+	Board       board.Board   `json:"board"`
+	CreatedAt   int64         `json:"created_at,omitempty"`
+	StartedAt   int64         `json:"started_at"`
+	FinishedAt  int64         `json:"finished_at,omitempty"`
+	ElapsedTime int64         `json:"elapsed_time"`
+	Version     int64         `json:"version"`
+	Moves       []Move        `json:"moves,omitempty"`
+}
+
+// CompactGame is a game whose board is in the compact encoding
+type CompactGame struct {
+	Game
+	Board board.CompactBoard `json:"board"`
+}
+
+// Participant is a player of a cooperative game along with its contribution to the game
+type Participant struct {
+	ID                   string `json:"id"`
+	RevealedSquaresCount int    `json:"revealed_squares_count"`
+	MarkedSquaresCount   int    `json:"marked_squares_count"`
+	Score                int    `json:"score,omitempty"`
+}
+
+// UpdateElapsedTime set the seconds elapsed since the game began. The clock stops once the game is finished.
+func (g *Game) UpdateElapsedTime() {
+	if g.StartedAt == 0 {
+		return
+	}
+
+	if g.FinishedAt > 0 {
+		g.ElapsedTime = g.FinishedAt - g.StartedAt
+		return
+	}
+
+	g.ElapsedTime = time.Now().Unix() - g.StartedAt
+}
+
+// participant return the participant with the given id or nil if the player has not joined the game
+func (g *Game) participant(playerID string) *Participant {
+	for i := range g.Players {
+		if g.Players[i].ID == playerID {
+			return &g.Players[i]
+		}
+	}
+
+	return nil
+}
+
+// CanPlay whether the given player is allowed to play the game. Cooperative and versus games can be played by
+// any of its participants, games with an owner only by its owner and anonymous games by anyone.
+func (g *Game) CanPlay(playerID string) bool {
+	if g.Mode == MODE_COOP || g.Mode == MODE_VERSUS {
+		return g.participant(playerID) != nil
+	}
+
+	return g.PlayerID == "" || g.PlayerID == playerID
+}
+
+// checkAction verifies whether the action can be performed on the game right now
+func (g *Game) checkAction(action Action) error {
+	if !g.CanPlay(action.PlayerID) {
+		return errors.New(apperrors.Forbidden, nil, "the player is not allowed to play this game", "")
+	}
+
+	if g.StartedAt > time.Now().Unix() {
+		return errors.New(apperrors.InvalidInput, nil, "the game has not started yet", "")
+	}
+
+	return nil
+}
+
+// IsFinished whether the game has been either won or lost
+func (g *Game) IsFinished() bool {
+	if g.Mode == MODE_VERSUS {
+		return g.Status == VERSUS_STATUS_FINISHED
+	}
+
+	return g.Board.Status == board.STATUS_WON || g.Board.Status == board.STATUS_LOST
 }
 
-func (g *Game) updateElapsedTime() {
-	if g.StartedAt > 0 {
-		g.ElapsedTime = time.Now().Unix() - g.StartedAt
+// Clone return a copy of the game sharing nothing with it, so that changes on either of them are not seen by the other
+func (g Game) Clone() Game {
+	clone := g
+	clone.Board = g.Board.Clone()
+
+	if g.Players != nil {
+		clone.Players = append([]Participant{}, g.Players...)
+	}
+
+	if g.Moves != nil {
+		clone.Moves = make([]Move, len(g.Moves))
+		for i, move := range g.Moves {
+			clone.Moves[i] = move
+			if move.Squares != nil {
+				clone.Moves[i].Squares = append([]SquareChange{}, move.Squares...)
+			}
+		}
 	}
+
+	return clone
 }
 
 type Configuration struct {
-	Rows    int `json:"rows" validate:"required,gte=3"`
-	Columns int `json:"columns" validate:"required,gte=3"`
-	Bombs   int `json:"bombs" validate:"required,gte=0"`
+	Rows    int    `json:"rows" validate:"required,gte=3"`
+	Columns int    `json:"columns" validate:"required,gte=3"`
+	Bombs   int    `json:"bombs" validate:"required,gte=0"`
+	Mode    string `json:"mode" enums:"classic,coop,versus"`
+}
+
+const (
+	MARK_FLAG string = "flag"
+	MARK_NONE string = "none"
+)
+
+// Action is a request to play or mark a square of a game
+type Action struct {
+	PlayerID string
+	Position board.SquarePosition
+	// Mark is the mark to set on the square, either MARK_FLAG or MARK_NONE. When empty, the mark is toggled.
+	Mark string
+	// IfVersion, when set, makes the action fail unless the game is at the given version
+	IfVersion *int64
+	// IdempotencyKey, when set, makes retries of the action get its original outcome instead of performing it again
+	IdempotencyKey string
 }
 
 type PlaySquareBody struct {
@@ -32,9 +155,257 @@ type PlaySquareBody struct {
 	Column int `json:"column" validate:"gte=0"`
 }
 
+// ListQuery is the query of the requests listing games
+type ListQuery struct {
+	Mode   string `form:"mode" json:"mode" enums:"classic,coop,versus"`
+	Status string `form:"status" json:"status"`
+	Limit  int    `form:"limit" json:"limit" validate:"gte=0,lte=100"`
+	Offset int    `form:"offset" json:"offset" validate:"gte=0"`
+}
+
+// GameList is a page of listed games
+type GameList struct {
+	Games  []Game `json:"games"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// MovesQuery is the query of the requests reading the move log of a game
+type MovesQuery struct {
+	After int64 `form:"after" json:"after" validate:"gte=0"`
+	Limit int   `form:"limit" json:"limit" validate:"gte=0,lte=100"`
+}
+
+// MoveList is a page of the move log of a game: the moves following the one with the After id
+type MoveList struct {
+	Moves []Move `json:"moves"`
+	After int64  `json:"after"`
+	Limit int    `json:"limit"`
+}
+
 type MarkSquareBody struct {
-	Row    int `json:"row" validate:"gte=0"`
-	Column int `json:"column" validate:"gte=0"`
+	Row    int    `json:"row" validate:"gte=0"`
+	Column int    `json:"column" validate:"gte=0"`
+	Mark   string `json:"mark" enums:"flag,none"`
+}
+
+// move applies on the game the move of the given type
+func (g *Game) move(moveType string, action Action) (Move, error) {
+	switch moveType {
+	case MOVE_PLAY_SQUARE:
+		return g.playSquare(action)
+	case MOVE_MARK_SQUARE:
+		return g.markSquare(action)
+	case MOVE_CHORD_SQUARE:
+		return g.chordSquare(action)
+	}
+
+	return Move{}, errors.New(apperrors.InvalidInput, nil, "invalid move", "unknown move type "+moveType)
+}
+
+func (g *Game) playSquare(action Action) (Move, error) {
+	err := g.checkAction(action)
+	if err != nil {
+		return Move{}, err
+	}
+
+	before := g.Board
+
+	if !*g.Board.FirstMoveDone {
+		g.StartedAt = time.Now().Unix()
+	}
+
+	var touched []board.SquarePosition
+	if g.Mode == MODE_VERSUS {
+		touched, err = g.playVersus(action.PlayerID, action.Position)
+	} else {
+		touched, err = g.Board.PlaySquare(action.Position)
+	}
+	if err != nil {
+		return Move{}, err
+	}
+
+	g.finish()
+
+	return g.recordMove(MOVE_PLAY_SQUARE, action.PlayerID, action.Position, touched, before), nil
+}
+
+func (g *Game) markSquare(action Action) (Move, error) {
+	err := g.checkAction(action)
+	if err != nil {
+		return Move{}, err
+	}
+
+	if g.Mode == MODE_VERSUS {
+		return Move{}, errors.New(apperrors.InvalidInput, nil, "squares cannot be marked on versus games", "")
+	}
+
+	before := g.Board
+
+	switch action.Mark {
+	case MARK_FLAG:
+		err = g.Board.SetMark(action.Position, true)
+	case MARK_NONE:
+		err = g.Board.SetMark(action.Position, false)
+	default:
+		err = g.Board.MarkSquare(action.Position)
+	}
+	if err != nil {
+		return Move{}, err
+	}
+
+	return g.recordMove(MOVE_MARK_SQUARE, action.PlayerID, action.Position, []board.SquarePosition{action.Position}, before), nil
+}
+
+func (g *Game) chordSquare(action Action) (Move, error) {
+	err := g.checkAction(action)
+	if err != nil {
+		return Move{}, err
+	}
+
+	if g.Mode == MODE_VERSUS {
+		return Move{}, errors.New(apperrors.InvalidInput, nil, "squares cannot be chorded on versus games", "")
+	}
+
+	before := g.Board
+
+	touched, err := g.Board.ChordSquare(action.Position)
+	if err != nil {
+		return Move{}, err
+	}
+
+	g.finish()
+
+	return g.recordMove(MOVE_CHORD_SQUARE, action.PlayerID, action.Position, touched, before), nil
+}
+
+// finish sets the finishing time of the game once it is over
+func (g *Game) finish() {
+	if g.IsFinished() && g.FinishedAt == 0 {
+		g.FinishedAt = time.Now().Unix()
+	}
+}
+
+// recordMove append to the game log the move that changed the squares in the touched positions
+// and credit it to the participant who made it. Only the status and counters of the board before the move are used.
+func (g *Game) recordMove(moveType string, playerID string, pos board.SquarePosition, touched []board.SquarePosition, before board.Board) Move {
+	move := Move{
+		ID:                   int64(len(g.Moves) + 1),
+		Type:                 moveType,
+		PlayerID:             playerID,
+		Position:             pos,
+		Squares:              squareChanges(g.Board, touched),
+		Status:               g.Board.Status,
+		StatusChanged:        before.Status != g.Board.Status,
+		RevealedSquaresCount: g.Board.RevealedSquaresCount,
+		At:                   time.Now().Unix(),
+	}
+
+	g.Moves = append(g.Moves, move)
+
+	if p := g.participant(playerID); p != nil {
+		switch moveType {
+		case MOVE_PLAY_SQUARE, MOVE_CHORD_SQUARE:
+			p.RevealedSquaresCount += g.Board.RevealedSquaresCount - before.RevealedSquaresCount
+		case MOVE_MARK_SQUARE:
+			// marking a marked square clears its mark, which may have been placed by another participant
+			if g.Board.Get(pos).Marked {
+				p.MarkedSquaresCount++
+			} else if p.MarkedSquaresCount > 0 {
+				p.MarkedSquaresCount--
+			}
+		}
+	}
+
+	return move
+}
+
+const (
+	MOVE_PLAY_SQUARE  string = "play_square"
+	MOVE_MARK_SQUARE  string = "mark_square"
+	MOVE_CHORD_SQUARE string = "chord_square"
+)
+
+// Move is an entry of the game log. It only holds obfuscated information, the squares it exposes were
+// already visible to the player after the move.
+type Move struct {
+	ID                   int64                `json:"id"`
+	Type                 string               `json:"type"`
+	PlayerID             string               `json:"player_id,omitempty"`
+	Position             board.SquarePosition `json:"position"`
+	Squares              []SquareChange       `json:"squares"`
+	Status               string               `json:"status"`
+	StatusChanged        bool                 `json:"status_changed,omitempty"`
+	RevealedSquaresCount int                  `json:"revealed_squares_count"`
+	At                   int64                `json:"at"`
+}
+
+// Delta is the outcome of some moves without the whole board: the squares they changed
+// along with the status and counters of the game
+type Delta struct {
+	ID                   string         `json:"id"`
+	Version              int64          `json:"version"`
+	Squares              []SquareChange `json:"squares"`
+	Status               string         `json:"status,omitempty"`
+	Turn                 string         `json:"turn,omitempty"`
+	Winner               string         `json:"winner,omitempty"`
+	BoardStatus          string         `json:"board_status"`
+	RevealedSquaresCount int            `json:"revealed_squares_count"`
+	MarkedSquaresCount   int            `json:"marked_squares_count"`
+	Players              []Participant  `json:"players,omitempty"`
+	ElapsedTime          int64          `json:"elapsed_time"`
+}
+
+// newDelta return the delta of the game for the given moves. It must be built before obfuscating the board,
+// since it holds the number of bombs adjacent to the revealed squares.
+func newDelta(g Game, moves ...Move) Delta {
+	positions := []board.SquarePosition{}
+	for _, move := range moves {
+		for _, square := range move.Squares {
+			positions = append(positions, board.SquarePosition{Row: square.Row, Column: square.Column})
+		}
+	}
+
+	g.UpdateElapsedTime()
+
+	delta := Delta{
+		ID:                   g.ID,
+		Version:              g.Version,
+		Squares:              squareChanges(g.Board, positions),
+		Status:               g.Status,
+		Turn:                 g.Turn,
+		Winner:               g.Winner,
+		BoardStatus:          g.Board.Status,
+		RevealedSquaresCount: g.Board.RevealedSquaresCount,
+		Players:              g.Players,
+		ElapsedTime:          g.ElapsedTime,
+	}
+
+	for i := range g.Board.Squares {
+		for _, square := range g.Board.Squares[i] {
+			if square.Marked {
+				delta.MarkedSquaresCount++
+			}
+		}
+	}
+
+	return delta
+}
+
+// Command is a move sent through the game web socket
+type Command struct {
+	Action string `json:"action" validate:"required"`
+	Row    int    `json:"row" validate:"gte=0"`
+	Column int    `json:"column" validate:"gte=0"`
+	Mark   string `json:"mark"`
+}
+
+func MarkSquareBodyStructValidation(v *validator.Validate, structLevel *validator.StructLevel) {
+	body := structLevel.CurrentStruct.Interface().(MarkSquareBody)
+
+	if body.Mark != "" && body.Mark != MARK_FLAG && body.Mark != MARK_NONE {
+		structLevel.ReportError(reflect.ValueOf(body.Mark), "Mark", "mark", "mark")
+	}
 }
 
 func ConfigurationStructValidation(v *validator.Validate, structLevel *validator.StructLevel) {
@@ -43,4 +414,10 @@ func ConfigurationStructValidation(v *validator.Validate, structLevel *validator
 	if configuration.Bombs >= (configuration.Rows*configuration.Columns)-1 {
 		structLevel.ReportError(reflect.ValueOf(configuration.Bombs), "Bombs", "bombs", "bombsnumber")
 	}
+
+	switch configuration.Mode {
+	case "", MODE_CLASSIC, MODE_COOP, MODE_VERSUS:
+	default:
+		structLevel.ReportError(reflect.ValueOf(configuration.Mode), "Mode", "mode", "mode")
+	}
 }
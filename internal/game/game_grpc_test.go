@@ -0,0 +1,202 @@
+package game_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/game/gamepb"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// newGrpcClient serves the gRPC game service through an in-process listener and return a client connected to it
+func newGrpcClient(t *testing.T) gamepb.GameServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer()
+	gamepb.RegisterGameServiceServer(server, game.NewGrpcServer(service, hub))
+
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return gamepb.NewGameServiceClient(conn)
+}
+
+func TestGrpcServer(t *testing.T) {
+	client := newGrpcClient(t)
+
+	tests := []struct {
+		name   string
+		should string
+		call   func(ctx context.Context) (*gamepb.Game, error)
+		verify func(t *testing.T, g *gamepb.Game, err error)
+	}{
+		{
+			name:   "create game",
+			should: "return the new game with an hidden board",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				return client.CreateGame(ctx, &gamepb.CreateGameRequest{Rows: 5, Columns: 4, Bombs: 3})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Nil(t, err)
+				assert.NotEmpty(t, g.GetId())
+				assert.Equal(t, game.MODE_CLASSIC, g.GetMode())
+				assert.Len(t, g.GetBoard().GetRows(), 5)
+				assert.Len(t, g.GetBoard().GetRows()[0].GetSquares(), 4)
+				assert.Equal(t, board.STATUS_NEW, g.GetBoard().GetStatus())
+			},
+		},
+		{
+			name:   "create invalid game",
+			should: "return an invalid argument status",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				return client.CreateGame(ctx, &gamepb.CreateGameRequest{Rows: 1, Columns: 4, Bombs: 3})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:   "create multiplayer game anonymously",
+			should: "return an unauthenticated status",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				return client.CreateGame(ctx, &gamepb.CreateGameRequest{Rows: 5, Columns: 5, Bombs: 3, Mode: game.MODE_COOP})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "create multiplayer game",
+			should: "take the player from the call metadata",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				ctx = metadata.AppendToOutgoingContext(ctx, player.MetadataKey, "alice")
+				return client.CreateGame(ctx, &gamepb.CreateGameRequest{Rows: 5, Columns: 5, Bombs: 3, Mode: game.MODE_COOP})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "alice", g.GetPlayerId())
+				assert.Equal(t, "alice", g.GetPlayers()[0].GetId())
+			},
+		},
+		{
+			name:   "get missing game",
+			should: "return a not found status",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				return client.GetGame(ctx, &gamepb.GetGameRequest{Id: "456"})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:   "play square",
+			should: "reveal the square without exposing any bomb",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				return client.PlaySquare(ctx, &gamepb.PlaySquareRequest{GameId: "123", Row: 0, Column: 0})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, int64(1), g.GetVersion())
+				assert.True(t, g.GetBoard().GetRows()[0].GetSquares()[0].GetRevealed())
+				assert.Equal(t, gamepb.SquareType_SQUARE_TYPE_EMPTY, g.GetBoard().GetRows()[1].GetSquares()[1].GetType())
+			},
+		},
+		{
+			name:   "play square of a modified game",
+			should: "return a failed precondition status",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				version := int64(3)
+				return client.PlaySquare(ctx, &gamepb.PlaySquareRequest{GameId: "123", Row: 0, Column: 0, IfVersion: &version})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name:   "flag square",
+			should: "mark the square",
+			call: func(ctx context.Context) (*gamepb.Game, error) {
+				return client.MarkSquare(ctx, &gamepb.MarkSquareRequest{GameId: "123", Row: 1, Column: 1, Mark: gamepb.Mark_MARK_FLAG})
+			},
+			verify: func(t *testing.T, g *gamepb.Game, err error) {
+				assert.Nil(t, err)
+				assert.True(t, g.GetBoard().GetRows()[1].GetSquares()[1].GetMarked())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			g, err := tt.call(ctx)
+
+			tt.verify(t, g, err)
+		})
+	}
+}
+
+func TestGrpcWatchGame(t *testing.T) {
+	client := newGrpcClient(t)
+
+	fakeStorage.CleanDB()
+	fakeStorage.CleanErrors()
+	fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stream, err := client.WatchGame(ctx, &gamepb.WatchGameRequest{Id: "123"})
+	assert.Nil(t, err)
+
+	snapshot, err := stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, game.EVENT_SNAPSHOT, snapshot.GetType())
+	assert.Equal(t, "123", snapshot.GetGame().GetId())
+
+	_, err = client.PlaySquare(ctx, &gamepb.PlaySquareRequest{GameId: "123", Row: 0, Column: 0})
+	assert.Nil(t, err)
+
+	delta, err := stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, game.EVENT_DELTA, delta.GetType())
+	assert.Len(t, delta.GetSquares(), 1)
+	assert.True(t, proto.Equal(&gamepb.SquareChange{Row: 0, Column: 0, Revealed: true, Count: 1}, delta.GetSquares()[0]))
+
+	missing, err := client.WatchGame(ctx, &gamepb.WatchGameRequest{Id: "456"})
+	assert.Nil(t, err)
+
+	_, err = missing.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,771 @@
+package game_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOnGoingBoard() board.Board {
+	_true := true
+
+	return board.Board{
+		Status: board.STATUS_ON_GOING,
+		Squares: [][]board.Square{
+			{{Type: board.EMPTY}, {Type: board.EMPTY}, {Type: board.EMPTY}},
+			{{Type: board.EMPTY}, {Type: board.BOMB}, {Type: board.EMPTY}},
+			{{Type: board.EMPTY}, {Type: board.EMPTY}, {Type: board.BOMB}},
+		},
+		BombsPositions: &[]board.SquarePosition{{Row: 1, Column: 1}, {Row: 2, Column: 2}},
+		FirstMoveDone:  &_true,
+		BombsNumber:    2,
+	}
+}
+
+func TestEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/games/:id/events", game.NewHttpHandler(service, hub).Events)
+
+	type input struct {
+		id          string
+		lastEventID string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, status int, body string)
+	}{
+		{
+			name:   "on going game",
+			should: "stream the logged moves without exposing any bomb",
+			input:  input{id: "123"},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+				service.MarkSquare("123", game.Action{Position: board.SquarePosition{Row: 1, Column: 1}})
+				service.PlaySquare("123", game.Action{Position: board.SquarePosition{Row: 0, Column: 0}})
+			},
+			verify: func(t *testing.T, in input, status int, body string) {
+				assert.Equal(t, 200, status)
+				assert.Contains(t, body, "id: 1\nevent: move\n")
+				assert.Contains(t, body, "id: 2\nevent: move\n")
+				assert.NotContains(t, body, `"type":1`)
+				assert.NotContains(t, body, "event: status")
+			},
+		},
+		{
+			name:   "resume a stream",
+			should: "stream only the moves after the last event id, along with the status change",
+			input:  input{id: "123", lastEventID: "1"},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+				service.PlaySquare("123", game.Action{Position: board.SquarePosition{Row: 0, Column: 0}})
+				service.PlaySquare("123", game.Action{Position: board.SquarePosition{Row: 1, Column: 1}})
+			},
+			verify: func(t *testing.T, in input, status int, body string) {
+				assert.Equal(t, 200, status)
+				assert.NotContains(t, body, "id: 1\n")
+				assert.Contains(t, body, "event: move\n")
+				assert.Contains(t, body, "id: 2\nevent: status\n")
+				assert.Contains(t, body, `"status":"lost"`)
+			},
+		},
+		{
+			name:   "moves missing from the live events",
+			should: "send the missing moves from the log",
+			input:  input{id: "123"},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+				service.MarkSquare("123", game.Action{Position: board.SquarePosition{Row: 0, Column: 0}})
+
+				// the events of the second and third moves are notified once the stream is live, the second one being lost
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+
+					g, _ := fakeStorage.GetByID("123")
+					g.Moves = append(g.Moves,
+						game.Move{ID: 2, Type: game.MOVE_MARK_SQUARE, Squares: []game.SquareChange{}, Status: board.STATUS_ON_GOING},
+						game.Move{ID: 3, Type: game.MOVE_MARK_SQUARE, Squares: []game.SquareChange{}, Status: board.STATUS_ON_GOING},
+					)
+					fakeStorage.Update(g)
+
+					hub.Publish(game.Event{Type: game.EVENT_DELTA, GameID: "123", Move: &g.Moves[2]})
+				}()
+			},
+			verify: func(t *testing.T, in input, status int, body string) {
+				assert.Equal(t, 200, status)
+				assert.Contains(t, body, "id: 1\nevent: move\n")
+				assert.Contains(t, body, "id: 2\nevent: move\n")
+				assert.Contains(t, body, "id: 3\nevent: move\n")
+				assert.Equal(t, 1, strings.Count(body, "id: 3\n"))
+			},
+		},
+		{
+			name:   "invalid last event id",
+			should: "return an invalid input error",
+			input:  input{id: "123", lastEventID: "abc"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, body string) {
+				assert.Equal(t, 400, status)
+			},
+		},
+		{
+			name:   "game not found",
+			should: "return a not found error",
+			input:  input{id: "456"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, body string) {
+				assert.Equal(t, 404, status)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			tt.mock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			req := httptest.NewRequest(http.MethodGet, "/games/"+tt.input.id+"/events", nil).WithContext(ctx)
+			if tt.input.lastEventID != "" {
+				req.Header.Set(game.LastEventIDHeader, tt.input.lastEventID)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			tt.verify(t, tt.input, rec.Code, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestPlayMoves(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/games/:id/moves", game.NewHttpHandler(service, hub).PlayMoves)
+
+	type input struct {
+		id   string
+		body string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, in input, status int, result game.BatchResult)
+	}{
+		{
+			name:   "valid moves",
+			should: "apply every move as a single change of the game",
+			input: input{id: "123", body: `{"moves": [
+				{"type": "mark_square", "row": 1, "column": 1, "mark": "flag"},
+				{"type": "play_square", "row": 0, "column": 2},
+				{"type": "play_square", "row": 2, "column": 0}
+			]}`},
+			verify: func(t *testing.T, in input, status int, result game.BatchResult) {
+				assert.Equal(t, 200, status)
+				assert.Len(t, result.Moves, 3)
+				assert.Equal(t, 0, result.Skipped)
+				assert.Equal(t, int64(1), result.Game.Version)
+				assert.True(t, result.Game.Board.Squares[1][1].Marked)
+				assert.True(t, result.Game.Board.Squares[0][2].Revealed)
+				assert.True(t, result.Game.Board.Squares[2][0].Revealed)
+
+				stored, _ := fakeStorage.GetByID(in.id)
+				assert.Len(t, stored.Moves, 3)
+			},
+		},
+		{
+			name:   "chord",
+			should: "reveal the unmarked neighbors of the square",
+			input: input{id: "123", body: `{"moves": [
+				{"type": "mark_square", "row": 1, "column": 1, "mark": "flag"},
+				{"type": "play_square", "row": 0, "column": 1},
+				{"type": "chord_square", "row": 0, "column": 1}
+			]}`},
+			verify: func(t *testing.T, in input, status int, result game.BatchResult) {
+				assert.Equal(t, 200, status)
+				assert.Len(t, result.Moves, 3)
+				assert.Equal(t, game.MOVE_CHORD_SQUARE, result.Moves[2].Type)
+				assert.Equal(t, board.STATUS_ON_GOING, result.Game.Board.Status)
+				assert.True(t, result.Game.Board.Squares[0][2].Revealed)
+				assert.True(t, result.Game.Board.Squares[1][0].Revealed)
+				assert.True(t, result.Game.Board.Squares[1][2].Revealed)
+			},
+		},
+		{
+			name:   "game ending move",
+			should: "skip the moves after it",
+			input: input{id: "123", body: `{"moves": [
+				{"type": "play_square", "row": 1, "column": 1},
+				{"type": "play_square", "row": 0, "column": 0}
+			]}`},
+			verify: func(t *testing.T, in input, status int, result game.BatchResult) {
+				assert.Equal(t, 200, status)
+				assert.Len(t, result.Moves, 1)
+				assert.Equal(t, 1, result.Skipped)
+				assert.Equal(t, board.STATUS_LOST, result.Game.Board.Status)
+				assert.False(t, result.Game.Board.Squares[0][0].Revealed)
+			},
+		},
+		{
+			name:   "invalid move",
+			should: "reject the whole batch",
+			input: input{id: "123", body: `{"moves": [
+				{"type": "play_square", "row": 0, "column": 2},
+				{"type": "play_square", "row": 9, "column": 9}
+			]}`},
+			verify: func(t *testing.T, in input, status int, result game.BatchResult) {
+				assert.Equal(t, 400, status)
+
+				stored, _ := fakeStorage.GetByID(in.id)
+				assert.Equal(t, int64(0), stored.Version)
+				assert.False(t, stored.Board.Squares[0][2].Revealed)
+			},
+		},
+		{
+			name:   "unknown move type",
+			should: "return a bad request",
+			input:  input{id: "123", body: `{"moves": [{"type": "explode", "row": 0, "column": 2}]}`},
+			verify: func(t *testing.T, in input, status int, result game.BatchResult) {
+				assert.Equal(t, 400, status)
+			},
+		},
+		{
+			name:   "no moves",
+			should: "return a bad request",
+			input:  input{id: "123", body: `{"moves": []}`},
+			verify: func(t *testing.T, in input, status int, result game.BatchResult) {
+				assert.Equal(t, 400, status)
+			},
+		},
+		{
+			name:   "game not found",
+			should: "return not found",
+			input:  input{id: "456", body: `{"moves": [{"type": "play_square", "row": 0, "column": 2}]}`},
+			verify: func(t *testing.T, in input, status int, result game.BatchResult) {
+				assert.Equal(t, 404, status)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+
+			req := httptest.NewRequest(http.MethodPost, "/games/"+tt.input.id+"/moves", strings.NewReader(tt.input.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			result := game.BatchResult{}
+			json.Unmarshal(rec.Body.Bytes(), &result)
+
+			tt.verify(t, tt.input, rec.Code, result)
+		})
+	}
+}
+
+func TestDeltaResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := game.NewHttpHandler(service, hub)
+
+	router := gin.New()
+	router.PUT("/games/:id/play-square", handler.PlaySquare)
+	router.PUT("/games/:id/mark-square", handler.MarkSquare)
+	router.POST("/games/:id/moves", handler.PlayMoves)
+
+	type input struct {
+		method string
+		path   string
+		accept string
+		body   string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, in input, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "play with view query param",
+			should: "reply the revealed square with its adjacent bombs count instead of the board",
+			input:  input{method: http.MethodPut, path: "/games/123/play-square?view=delta", body: `{"row": 0, "column": 0}`},
+			verify: func(t *testing.T, in input, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.NotContains(t, rec.Body.String(), `"board"`)
+
+				delta := game.Delta{}
+				json.Unmarshal(rec.Body.Bytes(), &delta)
+
+				assert.Equal(t, "123", delta.ID)
+				assert.Equal(t, int64(1), delta.Version)
+				assert.Equal(t, board.STATUS_ON_GOING, delta.BoardStatus)
+				assert.Equal(t, 1, delta.RevealedSquaresCount)
+				assert.Equal(t, []game.SquareChange{{Row: 0, Column: 0, Type: board.EMPTY, Revealed: true, Count: 1}}, delta.Squares)
+			},
+		},
+		{
+			name:   "mark with accept header",
+			should: "reply the marked square without exposing the bomb under it",
+			input:  input{method: http.MethodPut, path: "/games/123/mark-square", accept: game.DeltaMediaType, body: `{"row": 1, "column": 1}`},
+			verify: func(t *testing.T, in input, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.Equal(t, game.DeltaMediaType, rec.Header().Get("Content-Type"))
+
+				delta := game.Delta{}
+				json.Unmarshal(rec.Body.Bytes(), &delta)
+
+				assert.Equal(t, 1, delta.MarkedSquaresCount)
+				assert.Equal(t, []game.SquareChange{{Row: 1, Column: 1, Type: board.EMPTY, Marked: true}}, delta.Squares)
+			},
+		},
+		{
+			name:   "losing play",
+			should: "reply every bomb revealed by the loss",
+			input:  input{method: http.MethodPut, path: "/games/123/play-square?view=delta", body: `{"row": 1, "column": 1}`},
+			verify: func(t *testing.T, in input, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+
+				delta := game.Delta{}
+				json.Unmarshal(rec.Body.Bytes(), &delta)
+
+				assert.Equal(t, board.STATUS_LOST, delta.BoardStatus)
+				assert.ElementsMatch(t, []game.SquareChange{
+					{Row: 1, Column: 1, Type: board.BOMB, Revealed: true},
+					{Row: 2, Column: 2, Type: board.BOMB, Revealed: true},
+				}, delta.Squares)
+			},
+		},
+		{
+			name:   "batch",
+			should: "reply the squares changed by every move",
+			input: input{method: http.MethodPost, path: "/games/123/moves?view=delta", body: `{"moves": [
+				{"type": "mark_square", "row": 1, "column": 1},
+				{"type": "play_square", "row": 0, "column": 0},
+				{"type": "mark_square", "row": 1, "column": 1}
+			]}`},
+			verify: func(t *testing.T, in input, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+
+				result := game.BatchDelta{}
+				json.Unmarshal(rec.Body.Bytes(), &result)
+
+				assert.Len(t, result.Moves, 3)
+				assert.Equal(t, 0, result.Delta.MarkedSquaresCount)
+				assert.ElementsMatch(t, []game.SquareChange{
+					{Row: 1, Column: 1, Type: board.EMPTY},
+					{Row: 0, Column: 0, Type: board.EMPTY, Revealed: true, Count: 1},
+				}, result.Delta.Squares)
+			},
+		},
+		{
+			name:   "no delta requested",
+			should: "reply the whole game",
+			input:  input{method: http.MethodPut, path: "/games/123/play-square", body: `{"row": 0, "column": 0}`},
+			verify: func(t *testing.T, in input, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+
+				g := game.Game{}
+				json.Unmarshal(rec.Body.Bytes(), &g)
+
+				assert.Len(t, g.Board.Squares, 3)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			if tt.input.accept != "" {
+				req.Header.Set("Accept", tt.input.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			tt.verify(t, tt.input, rec)
+		})
+	}
+}
+
+func TestCompactEncoding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := game.NewHttpHandler(service, hub)
+
+	router := gin.New()
+	router.GET("/games/:id", handler.Get)
+	router.PUT("/games/:id/play-square", handler.PlaySquare)
+
+	type input struct {
+		method string
+		path   string
+		accept string
+		body   string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, in input, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "accept header",
+			should: "reply the board in the compact encoding",
+			input:  input{method: http.MethodGet, path: "/games/123", accept: game.CompactMediaType},
+			verify: func(t *testing.T, in input, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.Equal(t, game.CompactMediaType, rec.Header().Get("Content-Type"))
+				assert.Contains(t, rec.Body.String(), `"squares":["000","000","000"]`)
+			},
+		},
+		{
+			name:   "encoding query param",
+			should: "reply the same game as the json encoding",
+			input:  input{method: http.MethodPut, path: "/games/123/play-square?encoding=compact", body: `{"row": 0, "column": 0}`},
+			verify: func(t *testing.T, in input, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+
+				compact := game.CompactGame{}
+				err := json.Unmarshal(rec.Body.Bytes(), &compact)
+				assert.Nil(t, err)
+				assert.Equal(t, []string{"200", "000", "000"}, compact.Board.Squares)
+
+				b, err := compact.Board.Board()
+				assert.Nil(t, err)
+
+				req := httptest.NewRequest(http.MethodGet, "/games/123", nil)
+				plain := httptest.NewRecorder()
+				router.ServeHTTP(plain, req)
+
+				expected := game.Game{}
+				json.Unmarshal(plain.Body.Bytes(), &expected)
+
+				actual := compact.Game
+				actual.Board = b
+
+				assert.Equal(t, expected.Board, actual.Board)
+				assert.Equal(t, expected.Moves, actual.Moves)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+
+			req := httptest.NewRequest(tt.input.method, tt.input.path, strings.NewReader(tt.input.body))
+			if tt.input.accept != "" {
+				req.Header.Set("Accept", tt.input.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			tt.verify(t, tt.input, rec)
+		})
+	}
+}
+
+func TestListGames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	lister := &listingStorage{GameStorage: fakesto.NewGameStorage()}
+
+	router := gin.New()
+	router.GET("/games", game.NewHttpHandler(game.NewService(lister, game.NewHub()), hub).List)
+	router.GET("/unsupported/games", game.NewHttpHandler(service, hub).List)
+
+	tests := []struct {
+		name   string
+		should string
+		path   string
+		mock   func()
+		verify func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "list games",
+			should: "reply the obfuscated games matching the query",
+			path:   "/games?player_id=alice&mode=coop&status=won&limit=5&offset=10",
+			mock:   func() {},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.Equal(t, game.Filter{PlayerID: "alice", Mode: game.MODE_COOP, Status: board.STATUS_WON, Limit: 5, Offset: 10}, lister.filter)
+
+				list := game.GameList{}
+				assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &list))
+				assert.Equal(t, 5, list.Limit)
+				assert.Equal(t, 10, list.Offset)
+				assert.Len(t, list.Games, 1)
+				assert.Equal(t, board.EMPTY, list.Games[0].Board.Squares[1][1].Type)
+				assert.Nil(t, list.Games[0].Board.BombsPositions)
+			},
+		},
+		{
+			name:   "no limit",
+			should: "list the default number of games",
+			path:   "/games?player_id=alice",
+			mock:   func() {},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.Equal(t, game.DefaultListLimit, lister.filter.Limit)
+			},
+		},
+		{
+			name:   "anonymous request",
+			should: "reply unauthorized",
+			path:   "/games",
+			mock:   func() {},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 401, rec.Code)
+			},
+		},
+		{
+			name:   "lost daily game of another player",
+			should: "not list it, since its revealed bombs are the layout other players have to play",
+			path:   "/games?player_id=bob",
+			mock: func() {
+				b := newOnGoingBoard()
+				_, _ = b.PlaySquare(board.SquarePosition{Row: 1, Column: 1})
+				lister.games = []game.Game{{ID: "daily", PlayerID: "alice", Mode: game.MODE_CLASSIC, Board: b}}
+			},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.Equal(t, "bob", lister.filter.PlayerID)
+
+				list := game.GameList{}
+				assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &list))
+				assert.Empty(t, list.Games)
+			},
+		},
+		{
+			name:   "invalid limit",
+			should: "reply a bad request",
+			path:   "/games?player_id=alice&limit=1000",
+			mock:   func() {},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, rec.Code)
+			},
+		},
+		{
+			name:   "storage unable to list",
+			should: "reply not implemented",
+			path:   "/unsupported/games?player_id=alice",
+			mock:   func() {},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 501, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"code":"unsupported"`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister.filter, lister.err = game.Filter{}, nil
+			lister.games = []game.Game{{ID: "123", Mode: game.MODE_COOP, Players: []game.Participant{{ID: "alice"}}, Board: newOnGoingBoard()}}
+			tt.mock()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			tt.verify(t, rec)
+		})
+	}
+}
+
+func TestDeleteGame(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.DELETE("/games/:id", game.NewHttpHandler(service, hub).Delete)
+
+	type input struct {
+		id       string
+		playerID string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "owner",
+			should: "delete the game and reply no content",
+			input:  input{id: "123", playerID: "alice"},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 204, rec.Code)
+				assert.Empty(t, rec.Body.String())
+
+				_, err := fakeStorage.GetByID("123")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+		{
+			name:   "another player",
+			should: "reply forbidden",
+			input:  input{id: "123", playerID: "bob"},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 403, rec.Code)
+
+				_, err := fakeStorage.GetByID("123")
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:   "anonymous player",
+			should: "reply unauthorized",
+			input:  input{id: "123"},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 401, rec.Code)
+			},
+		},
+		{
+			name:   "missing game",
+			should: "reply not found",
+			input:  input{id: "456", playerID: "alice"},
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 404, rec.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: "123", PlayerID: "alice", Board: newOnGoingBoard()})
+
+			req := httptest.NewRequest(http.MethodDelete, "/games/"+tt.input.id, nil)
+			if tt.input.playerID != "" {
+				req.Header.Set(player.Header, tt.input.playerID)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			tt.verify(t, rec)
+		})
+	}
+}
+
+func TestGetGameMoves(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	handler := game.NewHttpHandler(service, hub)
+	router.GET("/games/:id", handler.Get)
+	router.GET("/games/:id/moves", handler.GetMoves)
+
+	tests := []struct {
+		name   string
+		should string
+		path   string
+		verify func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "get game",
+			should: "reply the game without its move log",
+			path:   "/games/123",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.NotContains(t, rec.Body.String(), `"moves"`)
+			},
+		},
+		{
+			name:   "first page",
+			should: "reply the first moves of the log",
+			path:   "/games/123/moves?limit=1",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+
+				list := game.MoveList{}
+				assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &list))
+				assert.Len(t, list.Moves, 1)
+				assert.Equal(t, int64(1), list.Moves[0].ID)
+				assert.Equal(t, 1, list.Limit)
+			},
+		},
+		{
+			name:   "next page",
+			should: "reply the moves after the given one",
+			path:   "/games/123/moves?after=1",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+
+				list := game.MoveList{}
+				assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &list))
+				assert.Len(t, list.Moves, 1)
+				assert.Equal(t, int64(2), list.Moves[0].ID)
+				assert.Equal(t, game.DefaultListLimit, list.Limit)
+			},
+		},
+		{
+			name:   "invalid limit",
+			should: "reply a bad request",
+			path:   "/games/123/moves?limit=1000",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 400, rec.Code)
+			},
+		},
+		{
+			name:   "game not found",
+			should: "reply not found",
+			path:   "/games/456/moves",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 404, rec.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+			service.MarkSquare("123", game.Action{Position: board.SquarePosition{Row: 1, Column: 1}})
+			service.PlaySquare("123", game.Action{Position: board.SquarePosition{Row: 0, Column: 0}})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			tt.verify(t, rec)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package game_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+// compactingStorage is a game storage recording its compactions
+type compactingStorage struct {
+	*fakesto.GameStorage
+	compactions int
+}
+
+func (sto *compactingStorage) Compact() error {
+	sto.compactions++
+	return nil
+}
+
+// racingStorage is a game storage whose games are changed right after it has gone through them
+type racingStorage struct {
+	*compactingStorage
+	afterWalk func()
+}
+
+func (sto racingStorage) Walk(fn func(g game.Game) error) error {
+	err := sto.compactingStorage.Walk(fn)
+	sto.afterWalk()
+
+	return err
+}
+
+// walklessStorage is a game storage unable to go through its games
+type walklessStorage struct {
+	game.Storage
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Unix(1588291200, 0)
+	day := int64(24 * 60 * 60)
+	retention := game.Retention{TTL: 24 * time.Hour, Finished: 7 * 24 * time.Hour}
+
+	tests := []struct {
+		name      string
+		should    string
+		game      game.Game
+		retention game.Retention
+		expired   bool
+	}{
+		{
+			name:      "recently created game",
+			should:    "not expire",
+			game:      game.Game{CreatedAt: now.Unix() - day + 1, Board: newOnGoingBoard()},
+			retention: retention,
+			expired:   false,
+		},
+		{
+			name:      "abandoned game",
+			should:    "expire once the ttl has elapsed since its creation",
+			game:      game.Game{CreatedAt: now.Unix() - day, Board: newOnGoingBoard()},
+			retention: retention,
+			expired:   true,
+		},
+		{
+			name:   "recently played game",
+			should: "not expire",
+			game: game.Game{
+				CreatedAt: now.Unix() - 10*day,
+				StartedAt: now.Unix() - 10*day,
+				Board:     newOnGoingBoard(),
+				Moves:     []game.Move{{ID: 1, At: now.Unix() - 60}},
+			},
+			retention: retention,
+			expired:   false,
+		},
+		{
+			name:      "finished game within the retention",
+			should:    "not expire",
+			game:      game.Game{CreatedAt: now.Unix() - 10*day, FinishedAt: now.Unix() - 6*day, Board: board.Board{Status: board.STATUS_WON}},
+			retention: retention,
+			expired:   false,
+		},
+		{
+			name:      "finished game after the retention",
+			should:    "expire",
+			game:      game.Game{CreatedAt: now.Unix() - 10*day, FinishedAt: now.Unix() - 7*day, Board: board.Board{Status: board.STATUS_LOST}},
+			retention: retention,
+			expired:   true,
+		},
+		{
+			name:      "finished versus game",
+			should:    "expire after the retention of finished games",
+			game:      game.Game{Mode: game.MODE_VERSUS, Status: game.VERSUS_STATUS_FINISHED, FinishedAt: now.Unix() - 2*day, Board: newOnGoingBoard()},
+			retention: game.Retention{TTL: 24 * time.Hour, Finished: 48 * time.Hour},
+			expired:   true,
+		},
+		{
+			name:      "no ttl",
+			should:    "keep unfinished games forever",
+			game:      game.Game{CreatedAt: now.Unix() - 1000*day, Board: newOnGoingBoard()},
+			retention: game.Retention{Finished: time.Hour},
+			expired:   false,
+		},
+		{
+			name:      "no activity recorded",
+			should:    "not expire",
+			game:      game.Game{Board: newOnGoingBoard()},
+			retention: retention,
+			expired:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expired, tt.game.Expired(tt.retention, now))
+		})
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Unix(1588291200, 0)
+	retention := game.Retention{TTL: time.Hour, Finished: 24 * time.Hour}
+
+	sto := &compactingStorage{GameStorage: fakesto.NewGameStorage()}
+
+	tests := []struct {
+		name    string
+		should  string
+		storage game.Storage
+		mock    func()
+		verify  func(t *testing.T, deleted int, err error)
+	}{
+		{
+			name:    "expired games",
+			should:  "delete them and compact the storage",
+			storage: sto,
+			mock: func() {
+				sto.Create(game.Game{ID: "abandoned", CreatedAt: now.Unix() - 3600, Board: newOnGoingBoard()})
+				sto.Create(game.Game{ID: "old", CreatedAt: now.Unix() - 3*86400, FinishedAt: now.Unix() - 2*86400, Board: board.Board{Status: board.STATUS_WON}})
+				sto.Create(game.Game{ID: "active", CreatedAt: now.Unix() - 60, Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, deleted int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 2, deleted)
+				assert.Equal(t, 1, sto.compactions)
+
+				_, err = sto.GetByID("abandoned")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+				_, err = sto.GetByID("old")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+				_, err = sto.GetByID("active")
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:    "no expired game",
+			should:  "not compact the storage",
+			storage: sto,
+			mock: func() {
+				sto.Create(game.Game{ID: "active", CreatedAt: now.Unix() - 60, Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, deleted int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 0, deleted)
+				assert.Equal(t, 0, sto.compactions)
+			},
+		},
+		{
+			name:    "game without activity recorded",
+			should:  "keep it untouched, so that the version clients hold remains valid",
+			storage: sto,
+			mock: func() {
+				sto.Create(game.Game{ID: "legacy", Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, deleted int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 0, deleted)
+				assert.Len(t, sto.CallsOf(fakesto.METHOD_UPDATE), 0)
+
+				g, err := sto.GetByID("legacy")
+				assert.Nil(t, err)
+				assert.Equal(t, int64(0), g.CreatedAt)
+				assert.Equal(t, int64(0), g.Version)
+			},
+		},
+		{
+			name:   "game played after being found expired",
+			should: "keep it",
+			storage: racingStorage{sto, func() {
+				g, _ := sto.GetByID("abandoned")
+				g.Moves = []game.Move{{ID: 1, At: now.Unix() - 60}}
+				sto.Update(g)
+			}},
+			mock: func() {
+				sto.Create(game.Game{ID: "abandoned", CreatedAt: now.Unix() - 3600, Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, deleted int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 0, deleted)
+				assert.Equal(t, 0, sto.compactions)
+
+				_, err = sto.GetByID("abandoned")
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:   "game deleted after being found expired",
+			should: "not count it as deleted",
+			storage: racingStorage{sto, func() {
+				sto.Delete("abandoned")
+			}},
+			mock: func() {
+				sto.Create(game.Game{ID: "abandoned", CreatedAt: now.Unix() - 3600, Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, deleted int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 0, deleted)
+			},
+		},
+		{
+			name:    "storage failure",
+			should:  "return an internal error",
+			storage: sto,
+			mock: func() {
+				sto.Create(game.Game{ID: "abandoned", CreatedAt: now.Unix() - 3600, Board: newOnGoingBoard()})
+				sto.AddErrorOnDelete(errors.New(apperrors.Internal, nil, "fail", ""))
+			},
+			verify: func(t *testing.T, deleted int, err error) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+				assert.Equal(t, 0, deleted)
+			},
+		},
+		{
+			name:    "storage unable to walk",
+			should:  "return an unsupported error",
+			storage: walklessStorage{sto},
+			mock:    func() {},
+			verify: func(t *testing.T, deleted int, err error) {
+				assert.True(t, errors.Is(err, apperrors.Unsupported))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sto.CleanDB()
+			sto.CleanErrors()
+			sto.CleanCalls()
+			sto.compactions = 0
+
+			tt.mock()
+
+			deleted, err := game.NewJanitor(game.NewService(tt.storage, game.NewHub()), tt.storage, retention, time.Hour).Sweep(now)
+
+			tt.verify(t, deleted, err)
+		})
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	sto := fakesto.NewGameStorage()
+	sto.Create(game.Game{ID: "abandoned", CreatedAt: time.Now().Unix() - 7200, Board: newOnGoingBoard()})
+
+	janitor := game.NewJanitor(game.NewService(sto, game.NewHub()), sto, game.Retention{TTL: time.Hour}, time.Hour)
+	janitor.Start()
+
+	assert.Eventually(t, func() bool {
+		_, err := sto.GetByID("abandoned")
+		return errors.Is(err, apperrors.NotFound)
+	}, time.Second, 10*time.Millisecond)
+
+	janitor.Stop()
+}
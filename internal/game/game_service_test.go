@@ -3,7 +3,9 @@ package game_test
 import (
 	"github.com/matiasvarela/errors"
 	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/matiasvarela/minesweeper/internal/game"
 
@@ -14,12 +16,14 @@ import (
 
 var (
 	service     game.Service
+	hub         *game.Hub
 	fakeStorage *fakesto.GameStorage
 )
 
 func init() {
 	fakeStorage = fakesto.NewGameStorage()
-	service = game.NewService(fakeStorage)
+	hub = game.NewHub()
+	service = game.NewService(fakeStorage, hub)
 }
 
 func TestCreate(t *testing.T) {
@@ -71,7 +75,7 @@ func TestCreate(t *testing.T) {
 
 			tt.mock()
 
-			game, err := service.Create(tt.input.configuration)
+			game, err := service.Create(tt.input.configuration, "")
 
 			tt.verify(t, tt.input, game, err)
 		})
@@ -175,7 +179,7 @@ func TestMarkSquare(t *testing.T) {
 
 			tt.mock()
 
-			game, err := service.MarkSquare(tt.input.id, tt.input.pos)
+			game, err := service.MarkSquare(tt.input.id, game.Action{Position: tt.input.pos})
 
 			tt.verify(t, tt.input, game, err)
 		})
@@ -223,9 +227,1110 @@ func TestPlaySquare(t *testing.T) {
 
 			tt.mock()
 
-			game, err := service.PlaySquare(tt.input.id, tt.input.pos)
+			game, err := service.PlaySquare(tt.input.id, game.Action{Position: tt.input.pos})
 
 			tt.verify(t, tt.input, game, err)
 		})
 	}
 }
+
+func TestPublishChanges(t *testing.T) {
+	type input struct {
+		id   string
+		pos  board.SquarePosition
+		play bool
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, events []game.Event)
+	}{
+		{
+			name:   "first play",
+			should: "notify the revealed squares and the status transition without exposing bombs",
+			input: input{
+				id:   "123",
+				pos:  board.SquarePosition{Row: 0, Column: 0},
+				play: true,
+			},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:    "123",
+					Board: board.NewBoard(3, 3, 1),
+				})
+			},
+			verify: func(t *testing.T, in input, events []game.Event) {
+				assert.Len(t, events, 2)
+
+				assert.Equal(t, game.EVENT_DELTA, events[0].Type)
+				assert.Equal(t, in.id, events[0].GameID)
+				assert.NotEmpty(t, events[0].Squares)
+				assert.Equal(t, events[0].RevealedSquaresCount, len(events[0].Squares))
+
+				for _, square := range events[0].Squares {
+					assert.True(t, square.Revealed)
+					assert.Equal(t, board.EMPTY, square.Type)
+				}
+
+				assert.Equal(t, game.EVENT_STATUS, events[1].Type)
+				assert.Equal(t, board.STATUS_ON_GOING, events[1].Status)
+			},
+		},
+		{
+			name:   "mark square",
+			should: "notify only the marked square",
+			input: input{
+				id:  "123",
+				pos: board.SquarePosition{Row: 1, Column: 2},
+			},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:    "123",
+					Board: board.NewBoard(3, 3, 1),
+				})
+			},
+			verify: func(t *testing.T, in input, events []game.Event) {
+				assert.Len(t, events, 1)
+				assert.Equal(t, []game.SquareChange{{Row: 1, Column: 2, Marked: true}}, events[0].Squares)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			tt.mock()
+
+			ch, unsubscribe := hub.Subscribe(tt.input.id)
+
+			var err error
+			if tt.input.play {
+				_, err = service.PlaySquare(tt.input.id, game.Action{Position: tt.input.pos})
+			} else {
+				_, err = service.MarkSquare(tt.input.id, game.Action{Position: tt.input.pos})
+			}
+			assert.Nil(t, err)
+
+			unsubscribe()
+
+			events := []game.Event{}
+			for event := range ch {
+				events = append(events, event)
+			}
+
+			tt.verify(t, tt.input, events)
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	type input struct {
+		id       string
+		playerID string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, g game.Game, err error)
+	}{
+		{
+			name:   "join success",
+			should: "add the player as a participant of the game",
+			input:  input{id: "123", playerID: "bob"},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:      "123",
+					Mode:    game.MODE_COOP,
+					Players: []game.Participant{{ID: "alice"}},
+					Board:   board.NewBoard(3, 3, 1),
+				})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []game.Participant{{ID: "alice"}, {ID: "bob"}}, g.Players)
+			},
+		},
+		{
+			name:   "join twice",
+			should: "keep a single participant for the player",
+			input:  input{id: "123", playerID: "alice"},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:      "123",
+					Mode:    game.MODE_COOP,
+					Players: []game.Participant{{ID: "alice"}},
+					Board:   board.NewBoard(3, 3, 1),
+				})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, g.Players, 1)
+			},
+		},
+		{
+			name:   "join a classic game",
+			should: "return an invalid input error",
+			input:  input{id: "123", playerID: "bob"},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:    "123",
+					Mode:  game.MODE_CLASSIC,
+					Board: board.NewBoard(3, 3, 1),
+				})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "game not found",
+			should: "return a not found error",
+			input:  input{id: "123", playerID: "bob"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			tt.mock()
+
+			game, err := service.Join(tt.input.id, tt.input.playerID)
+
+			tt.verify(t, tt.input, game, err)
+		})
+	}
+}
+
+func TestCooperativePlay(t *testing.T) {
+	type input struct {
+		id       string
+		playerID string
+		pos      board.SquarePosition
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, g game.Game, err error)
+	}{
+		{
+			name:   "participant plays",
+			should: "attribute the move and the revealed squares to the player",
+			input:  input{id: "123", playerID: "bob", pos: board.SquarePosition{Row: 0, Column: 0}},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:      "123",
+					Mode:    game.MODE_COOP,
+					Players: []game.Participant{{ID: "alice"}, {ID: "bob"}},
+					Board:   newOnGoingBoard(),
+				})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, in.playerID, g.Moves[0].PlayerID)
+				assert.Equal(t, 0, g.Players[0].RevealedSquaresCount)
+				assert.Equal(t, g.Board.RevealedSquaresCount, g.Players[1].RevealedSquaresCount)
+			},
+		},
+		{
+			name:   "not a participant",
+			should: "return a forbidden error",
+			input:  input{id: "123", playerID: "eve", pos: board.SquarePosition{Row: 0, Column: 0}},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:      "123",
+					Mode:    game.MODE_COOP,
+					Players: []game.Participant{{ID: "alice"}},
+					Board:   newOnGoingBoard(),
+				})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.Forbidden))
+			},
+		},
+		{
+			name:   "concurrent plays",
+			should: "apply every move without losing any of them",
+			input:  input{id: "123", playerID: "alice", pos: board.SquarePosition{Row: 0, Column: 0}},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:      "123",
+					Mode:    game.MODE_COOP,
+					Players: []game.Participant{{ID: "alice"}, {ID: "bob"}},
+					Board:   newOnGoingBoard(),
+				})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+
+				var wg sync.WaitGroup
+				for _, pos := range []board.SquarePosition{{Row: 0, Column: 2}, {Row: 2, Column: 0}, {Row: 1, Column: 2}} {
+					wg.Add(1)
+					go func(pos board.SquarePosition) {
+						defer wg.Done()
+						service.MarkSquare(in.id, game.Action{PlayerID: "bob", Position: pos})
+					}(pos)
+				}
+				wg.Wait()
+
+				g, err = service.Get(in.id)
+				assert.Nil(t, err)
+				assert.Len(t, g.Moves, 4)
+				assert.Equal(t, 3, g.Players[1].MarkedSquaresCount)
+			},
+		},
+		{
+			name:   "mark then unmark",
+			should: "only count the squares left marked",
+			input:  input{id: "123", playerID: "alice", pos: board.SquarePosition{Row: 0, Column: 0}},
+			mock: func() {
+				fakeStorage.Create(game.Game{
+					ID:      "123",
+					Mode:    game.MODE_COOP,
+					Players: []game.Participant{{ID: "alice"}, {ID: "bob"}},
+					Board:   newOnGoingBoard(),
+				})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+
+				for _, pos := range []board.SquarePosition{{Row: 0, Column: 2}, {Row: 0, Column: 2}, {Row: 2, Column: 0}} {
+					_, err = service.MarkSquare(in.id, game.Action{PlayerID: "bob", Position: pos})
+					assert.Nil(t, err)
+				}
+
+				g, err = service.Get(in.id)
+				assert.Nil(t, err)
+				assert.Equal(t, 0, g.Players[0].MarkedSquaresCount)
+				assert.Equal(t, 1, g.Players[1].MarkedSquaresCount)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			tt.mock()
+
+			game, err := service.PlaySquare(tt.input.id, game.Action{PlayerID: tt.input.playerID, Position: tt.input.pos})
+
+			tt.verify(t, tt.input, game, err)
+		})
+	}
+}
+
+func TestVersusPlay(t *testing.T) {
+	type input struct {
+		id       string
+		playerID string
+		pos      board.SquarePosition
+	}
+
+	newVersusGame := func(status string) game.Game {
+		return game.Game{
+			ID:      "123",
+			Mode:    game.MODE_VERSUS,
+			Status:  status,
+			Turn:    "alice",
+			Players: []game.Participant{{ID: "alice"}, {ID: "bob"}},
+			Board:   newOnGoingBoard(),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, g game.Game, err error)
+	}{
+		{
+			name:   "reveal a square",
+			should: "reveal the square and pass the turn",
+			input:  input{id: "123", playerID: "alice", pos: board.SquarePosition{Row: 0, Column: 0}},
+			mock: func() {
+				fakeStorage.Create(newVersusGame(game.VERSUS_STATUS_PLAYING))
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.True(t, g.Board.Squares[0][0].Revealed)
+				assert.Equal(t, "bob", g.Turn)
+				assert.Equal(t, board.STATUS_ON_GOING, g.Board.Status)
+			},
+		},
+		{
+			name:   "claim a bomb",
+			should: "score a point and keep the turn",
+			input:  input{id: "123", playerID: "alice", pos: board.SquarePosition{Row: 1, Column: 1}},
+			mock: func() {
+				fakeStorage.Create(newVersusGame(game.VERSUS_STATUS_PLAYING))
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.True(t, g.Board.Squares[1][1].Revealed)
+				assert.Equal(t, 1, g.Players[0].Score)
+				assert.Equal(t, "alice", g.Turn)
+				assert.Equal(t, game.VERSUS_STATUS_PLAYING, g.Status)
+			},
+		},
+		{
+			name:   "claim the majority of the bombs",
+			should: "win the game",
+			input:  input{id: "123", playerID: "alice", pos: board.SquarePosition{Row: 2, Column: 2}},
+			mock: func() {
+				fakeStorage.Create(newVersusGame(game.VERSUS_STATUS_PLAYING))
+				service.PlaySquare("123", game.Action{PlayerID: "alice", Position: board.SquarePosition{Row: 1, Column: 1}})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 2, g.Players[0].Score)
+				assert.Equal(t, game.VERSUS_STATUS_FINISHED, g.Status)
+				assert.Equal(t, "alice", g.Winner)
+				assert.True(t, g.IsFinished())
+				assert.NotZero(t, g.FinishedAt)
+			},
+		},
+		{
+			name:   "out of turn",
+			should: "return an out of turn error",
+			input:  input{id: "123", playerID: "bob", pos: board.SquarePosition{Row: 0, Column: 0}},
+			mock: func() {
+				fakeStorage.Create(newVersusGame(game.VERSUS_STATUS_PLAYING))
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.OutOfTurn))
+			},
+		},
+		{
+			name:   "waiting for an opponent",
+			should: "return an invalid input error",
+			input:  input{id: "123", playerID: "alice", pos: board.SquarePosition{Row: 0, Column: 0}},
+			mock: func() {
+				fakeStorage.Create(newVersusGame(game.VERSUS_STATUS_WAITING))
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			tt.mock()
+
+			game, err := service.PlaySquare(tt.input.id, game.Action{PlayerID: tt.input.playerID, Position: tt.input.pos})
+
+			tt.verify(t, tt.input, game, err)
+		})
+	}
+}
+
+func TestVersusJoin(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		mock   func() game.Game
+		verify func(t *testing.T, g game.Game, err error)
+	}{
+		{
+			name:   "opponent joins",
+			should: "start the game with the turn of the player who created it",
+			mock: func() game.Game {
+				g, _ := service.Create(game.Configuration{Rows: 3, Columns: 3, Bombs: 3, Mode: game.MODE_VERSUS}, "alice")
+				return g
+			},
+			verify: func(t *testing.T, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, game.VERSUS_STATUS_PLAYING, g.Status)
+				assert.Equal(t, "alice", g.Turn)
+			},
+		},
+		{
+			name:   "game already has an opponent",
+			should: "return an invalid input error",
+			mock: func() game.Game {
+				g, _ := service.Create(game.Configuration{Rows: 3, Columns: 3, Bombs: 3, Mode: game.MODE_VERSUS}, "alice")
+				service.Join(g.ID, "carol")
+				return g
+			},
+			verify: func(t *testing.T, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			g := tt.mock()
+
+			g, err := service.Join(g.ID, "bob")
+
+			tt.verify(t, g, err)
+		})
+	}
+}
+
+func TestVersioning(t *testing.T) {
+	type input struct {
+		id        string
+		ifVersion *int64
+	}
+
+	version := func(v int64) *int64 {
+		return &v
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, g game.Game, err error)
+	}{
+		{
+			name:   "expected version",
+			should: "play the square and increment the version",
+			input:  input{id: "123", ifVersion: version(0)},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, int64(1), g.Version)
+
+				stored, _ := fakeStorage.GetByID(in.id)
+				assert.Equal(t, int64(1), stored.Version)
+			},
+		},
+		{
+			name:   "unexpected version",
+			should: "return a precondition failed error",
+			input:  input{id: "123", ifVersion: version(0)},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard(), Version: 4})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.PreconditionFailed))
+			},
+		},
+		{
+			name:   "concurrent modifications",
+			should: "return a conflict error after retrying",
+			input:  input{id: "123"},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+				fakeStorage.AddErrorOnUpdate(errors.New(apperrors.Conflict, nil, "conflict", ""))
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.Conflict))
+			},
+		},
+		{
+			name:   "stale update",
+			should: "be rejected by the storage with a conflict error",
+			input:  input{id: "123"},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, in input, g game.Game, err error) {
+				assert.Nil(t, err)
+
+				g.Version = 0
+				err = fakeStorage.Update(g)
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.Conflict))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			tt.mock()
+
+			game, err := service.PlaySquare(tt.input.id, game.Action{
+				Position:  board.SquarePosition{Row: 0, Column: 0},
+				IfVersion: tt.input.ifVersion,
+			})
+
+			tt.verify(t, tt.input, game, err)
+		})
+	}
+}
+
+func TestUpdateRetries(t *testing.T) {
+	conflict := errors.New(apperrors.Conflict, nil, "conflict", "")
+
+	tests := []struct {
+		name   string
+		should string
+		mock   func()
+		verify func(t *testing.T, g game.Game, err error)
+	}{
+		{
+			name:   "transient conflict",
+			should: "apply the change again on the fresh game",
+			mock: func() {
+				fakeStorage.AddErrorOnCall(fakesto.METHOD_UPDATE, 1, conflict)
+			},
+			verify: func(t *testing.T, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, int64(1), g.Version)
+				assert.Equal(t, 2, len(fakeStorage.CallsOf(fakesto.METHOD_UPDATE)))
+				assert.Equal(t, 2, len(fakeStorage.CallsOf(fakesto.METHOD_GET_BY_ID)))
+			},
+		},
+		{
+			name:   "persistent conflict",
+			should: "give up with a conflict error after several attempts",
+			mock: func() {
+				fakeStorage.AddErrorOnID(fakesto.METHOD_UPDATE, "123", conflict)
+			},
+			verify: func(t *testing.T, g game.Game, err error) {
+				assert.True(t, errors.Is(err, apperrors.Conflict))
+				assert.Equal(t, 3, len(fakeStorage.CallsOf(fakesto.METHOD_UPDATE)))
+			},
+		},
+		{
+			name:   "storage failure",
+			should: "return an internal error without retrying",
+			mock: func() {
+				fakeStorage.AddErrorOnCall(fakesto.METHOD_UPDATE, 1, errors.New(apperrors.Internal, nil, "fail", ""))
+			},
+			verify: func(t *testing.T, g game.Game, err error) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+				assert.Equal(t, 1, len(fakeStorage.CallsOf(fakesto.METHOD_UPDATE)))
+			},
+		},
+		{
+			name:   "failure of another game",
+			should: "not affect the game",
+			mock: func() {
+				fakeStorage.AddErrorOnID(fakesto.METHOD_UPDATE, "456", conflict)
+			},
+			verify: func(t *testing.T, g game.Game, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, len(fakeStorage.CallsOf(fakesto.METHOD_UPDATE)))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+			fakeStorage.CleanCalls()
+
+			tt.mock()
+
+			g, err := service.MarkSquare("123", game.Action{Position: board.SquarePosition{Row: 0, Column: 0}})
+
+			tt.verify(t, g, err)
+		})
+	}
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	fakeStorage.CleanDB()
+	fakeStorage.CleanErrors()
+	fakeStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+	fakeStorage.CleanCalls()
+
+	fakeStorage.SetLatency(time.Millisecond)
+	defer fakeStorage.SetLatency(0)
+
+	var wg sync.WaitGroup
+
+	for row := 0; row < 3; row++ {
+		for column := 0; column < 3; column++ {
+			wg.Add(1)
+			go func(pos board.SquarePosition) {
+				defer wg.Done()
+
+				_, err := service.MarkSquare("123", game.Action{Position: pos})
+				assert.Nil(t, err)
+			}(board.SquarePosition{Row: row, Column: column})
+		}
+	}
+
+	wg.Wait()
+
+	stored, err := fakeStorage.GetByID("123")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9), stored.Version)
+
+	for row := range stored.Board.Squares {
+		for column := range stored.Board.Squares[row] {
+			assert.True(t, stored.Board.Squares[row][column].Marked)
+		}
+	}
+
+	// moves on the same game are serialized, so none of them is rejected by the storage
+	for _, call := range fakeStorage.CallsOf(fakesto.METHOD_UPDATE) {
+		assert.Nil(t, call.Err)
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	type move struct {
+		play   bool
+		action game.Action
+	}
+
+	type input struct {
+		id    string
+		moves []move
+	}
+
+	mark := func(key string, mark string) move {
+		return move{action: game.Action{Position: board.SquarePosition{Row: 0, Column: 0}, Mark: mark, IdempotencyKey: key}}
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, in input, games []game.Game, errs []error)
+	}{
+		{
+			name:   "retried mark",
+			should: "mark the square only once and return the original game",
+			input:  input{id: "123", moves: []move{mark("abc", ""), mark("abc", "")}},
+			verify: func(t *testing.T, in input, games []game.Game, errs []error) {
+				assert.Nil(t, errs[0])
+				assert.Nil(t, errs[1])
+				assert.True(t, games[1].Board.Squares[0][0].Marked)
+				assert.Equal(t, games[0].Version, games[1].Version)
+
+				stored, _ := fakeStorage.GetByID(in.id)
+				assert.True(t, stored.Board.Squares[0][0].Marked)
+				assert.Equal(t, int64(1), stored.Version)
+				assert.Len(t, stored.Moves, 1)
+			},
+		},
+		{
+			name:   "different keys",
+			should: "toggle the mark twice",
+			input:  input{id: "123", moves: []move{mark("abc", ""), mark("def", "")}},
+			verify: func(t *testing.T, in input, games []game.Game, errs []error) {
+				assert.Nil(t, errs[1])
+
+				stored, _ := fakeStorage.GetByID(in.id)
+				assert.False(t, stored.Board.Squares[0][0].Marked)
+				assert.Equal(t, int64(2), stored.Version)
+			},
+		},
+		{
+			name:   "explicit marks",
+			should: "leave the square flagged however many times it is flagged",
+			input:  input{id: "123", moves: []move{mark("", game.MARK_FLAG), mark("", game.MARK_FLAG), mark("", game.MARK_NONE), mark("", game.MARK_FLAG)}},
+			verify: func(t *testing.T, in input, games []game.Game, errs []error) {
+				assert.True(t, games[0].Board.Squares[0][0].Marked)
+				assert.True(t, games[1].Board.Squares[0][0].Marked)
+				assert.False(t, games[2].Board.Squares[0][0].Marked)
+				assert.True(t, games[3].Board.Squares[0][0].Marked)
+			},
+		},
+		{
+			name:   "key reused with another square",
+			should: "return an invalid input error",
+			input: input{id: "123", moves: []move{
+				mark("abc", ""),
+				{action: game.Action{Position: board.SquarePosition{Row: 0, Column: 1}, IdempotencyKey: "abc"}},
+			}},
+			verify: func(t *testing.T, in input, games []game.Game, errs []error) {
+				assert.Nil(t, errs[0])
+				assert.NotNil(t, errs[1])
+				assert.True(t, errors.Is(errs[1], apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "key reused with another move",
+			should: "return an invalid input error",
+			input:  input{id: "123", moves: []move{mark("abc", ""), {play: true, action: mark("abc", "").action}}},
+			verify: func(t *testing.T, in input, games []game.Game, errs []error) {
+				assert.NotNil(t, errs[1])
+				assert.True(t, errors.Is(errs[1], apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "retried play",
+			should: "return the original game even if it has changed since then",
+			input: input{id: "123", moves: []move{
+				{play: true, action: game.Action{Position: board.SquarePosition{Row: 0, Column: 0}, IdempotencyKey: "abc"}},
+				mark("def", ""),
+				{play: true, action: game.Action{Position: board.SquarePosition{Row: 0, Column: 0}, IdempotencyKey: "abc"}},
+			}},
+			verify: func(t *testing.T, in input, games []game.Game, errs []error) {
+				assert.Nil(t, errs[2])
+				assert.Equal(t, int64(1), games[2].Version)
+				assert.Equal(t, games[0].Board, games[2].Board)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: tt.input.id, Board: newOnGoingBoard()})
+
+			service := game.NewService(fakeStorage, game.NewHub())
+
+			games := []game.Game{}
+			errs := []error{}
+
+			for _, m := range tt.input.moves {
+				var g game.Game
+				var err error
+
+				if m.play {
+					g, err = service.PlaySquare(tt.input.id, m.action)
+				} else {
+					g, err = service.MarkSquare(tt.input.id, m.action)
+				}
+
+				games = append(games, g)
+				errs = append(errs, err)
+			}
+
+			tt.verify(t, tt.input, games, errs)
+		})
+	}
+}
+
+// listingStorage is a storage able to list games, recording the filter it has been given. Only the player of the
+// filter is matched.
+type listingStorage struct {
+	*fakesto.GameStorage
+	filter game.Filter
+	games  []game.Game
+	err    error
+}
+
+func (sto *listingStorage) List(filter game.Filter) ([]game.Game, error) {
+	sto.filter = filter
+
+	if sto.games == nil || filter.PlayerID == "" {
+		return sto.games, sto.err
+	}
+
+	games := []game.Game{}
+	for _, g := range sto.games {
+		if g.PlayerID == filter.PlayerID {
+			games = append(games, g)
+			continue
+		}
+
+		for _, p := range g.Players {
+			if p.ID == filter.PlayerID {
+				games = append(games, g)
+				break
+			}
+		}
+	}
+
+	return games, sto.err
+}
+
+func TestDelete(t *testing.T) {
+	type input struct {
+		id       string
+		playerID string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, err error)
+	}{
+		{
+			name:   "owner",
+			should: "delete the game",
+			input:  input{id: "123", playerID: "alice"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, err error) {
+				assert.Nil(t, err)
+
+				_, err = fakeStorage.GetByID(in.id)
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+		{
+			name:   "another player",
+			should: "return a forbidden error and keep the game",
+			input:  input{id: "123", playerID: "bob"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, err error) {
+				assert.True(t, errors.Is(err, apperrors.Forbidden))
+
+				_, err = fakeStorage.GetByID(in.id)
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:   "anonymous game",
+			should: "return a forbidden error",
+			input:  input{id: "456", playerID: "alice"},
+			mock: func() {
+				fakeStorage.Create(game.Game{ID: "456", Board: newOnGoingBoard()})
+			},
+			verify: func(t *testing.T, in input, err error) {
+				assert.True(t, errors.Is(err, apperrors.Forbidden))
+			},
+		},
+		{
+			name:   "missing game",
+			should: "return a not found error",
+			input:  input{id: "789", playerID: "alice"},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, err error) {
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+		{
+			name:   "storage failure",
+			should: "return an internal error",
+			input:  input{id: "123", playerID: "alice"},
+			mock: func() {
+				fakeStorage.AddErrorOnDelete(errors.New(apperrors.Internal, nil, "fail", ""))
+			},
+			verify: func(t *testing.T, in input, err error) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+			fakeStorage.Create(game.Game{ID: "123", PlayerID: "alice", Board: newOnGoingBoard()})
+
+			tt.mock()
+
+			err := service.Delete(tt.input.id, tt.input.playerID)
+
+			tt.verify(t, tt.input, err)
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	lister := &listingStorage{GameStorage: fakesto.NewGameStorage()}
+	listingService := game.NewService(lister, game.NewHub())
+
+	tests := []struct {
+		name    string
+		should  string
+		service game.Service
+		filter  game.Filter
+		mock    func()
+		verify  func(t *testing.T, list game.GameList, err error)
+	}{
+		{
+			name:    "storage unable to list",
+			should:  "return an unsupported error",
+			service: service,
+			filter:  game.Filter{},
+			mock:    func() {},
+			verify: func(t *testing.T, list game.GameList, err error) {
+				assert.True(t, errors.Is(err, apperrors.Unsupported))
+			},
+		},
+		{
+			name:    "filter without limit",
+			should:  "list the default number of games",
+			service: listingService,
+			filter:  game.Filter{PlayerID: "alice", Status: board.STATUS_WON},
+			mock: func() {
+				lister.games = []game.Game{{ID: "123", PlayerID: "alice"}}
+			},
+			verify: func(t *testing.T, list game.GameList, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, game.GameList{Games: []game.Game{{ID: "123", PlayerID: "alice"}}, Limit: game.DefaultListLimit}, list)
+				assert.Equal(t, game.Filter{PlayerID: "alice", Status: board.STATUS_WON, Limit: game.DefaultListLimit}, lister.filter)
+			},
+		},
+		{
+			name:    "limit too high",
+			should:  "return an invalid input error",
+			service: listingService,
+			filter:  game.Filter{Limit: game.MaxListLimit + 1},
+			mock:    func() {},
+			verify: func(t *testing.T, list game.GameList, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:    "storage fails",
+			should:  "return an internal error",
+			service: listingService,
+			filter:  game.Filter{Limit: 5},
+			mock: func() {
+				lister.err = errors.New(apperrors.Internal, nil, "fail", "")
+			},
+			verify: func(t *testing.T, list game.GameList, err error) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister.filter, lister.games, lister.err = game.Filter{}, nil, nil
+			tt.mock()
+
+			list, err := tt.service.List(tt.filter)
+
+			tt.verify(t, list, err)
+		})
+	}
+}
+
+func TestGetMoves(t *testing.T) {
+	type input struct {
+		id    string
+		after int64
+		limit int
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		verify func(t *testing.T, list game.MoveList, err error)
+	}{
+		{
+			name:   "first page",
+			should: "return the first moves of the log",
+			input:  input{id: "123", limit: 2},
+			verify: func(t *testing.T, list game.MoveList, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, list.Moves, 2)
+				assert.Equal(t, int64(1), list.Moves[0].ID)
+				assert.Equal(t, int64(2), list.Moves[1].ID)
+				assert.Equal(t, 2, list.Limit)
+			},
+		},
+		{
+			name:   "next page",
+			should: "return the moves after the given one",
+			input:  input{id: "123", after: 2, limit: 2},
+			verify: func(t *testing.T, list game.MoveList, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, list.Moves, 1)
+				assert.Equal(t, int64(3), list.Moves[0].ID)
+				assert.Equal(t, int64(2), list.After)
+			},
+		},
+		{
+			name:   "after the last move",
+			should: "return no moves",
+			input:  input{id: "123", after: 10},
+			verify: func(t *testing.T, list game.MoveList, err error) {
+				assert.Nil(t, err)
+				assert.Empty(t, list.Moves)
+				assert.Equal(t, game.DefaultListLimit, list.Limit)
+			},
+		},
+		{
+			name:   "limit too high",
+			should: "return an invalid input error",
+			input:  input{id: "123", limit: game.MaxListLimit + 1},
+			verify: func(t *testing.T, list game.MoveList, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "game not found",
+			should: "return a not found error",
+			input:  input{id: "456"},
+			verify: func(t *testing.T, list game.MoveList, err error) {
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeStorage.CleanDB()
+			fakeStorage.CleanErrors()
+
+			fakeStorage.Create(game.Game{ID: "123", Board: board.NewBoard(5, 5, 1)})
+			for _, pos := range []board.SquarePosition{{Row: 0, Column: 0}, {Row: 0, Column: 1}, {Row: 0, Column: 0}} {
+				_, err := service.MarkSquare("123", game.Action{Position: pos})
+				assert.Nil(t, err)
+			}
+
+			list, err := service.GetMoves(tt.input.id, tt.input.after, tt.input.limit)
+
+			tt.verify(t, list, err)
+		})
+	}
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := game.NewHub()
+
+	slow, unsubscribe := hub.Subscribe("123")
+	defer unsubscribe()
+
+	fast, unsubscribeFast := hub.Subscribe("123")
+	defer unsubscribeFast()
+
+	received := 0
+	for i := 0; i < 40; i++ {
+		hub.Publish(game.Event{Type: game.EVENT_TICK, GameID: "123"})
+
+		<-fast
+		received++
+	}
+
+	assert.Equal(t, 40, received)
+
+	// the slow subscriber gets the events it had room for, then its channel is closed
+	missed := 40
+	for range slow {
+		missed--
+	}
+
+	assert.Equal(t, 40-32, missed)
+}
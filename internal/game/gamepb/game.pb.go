@@ -0,0 +1,1180 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.27.0
+// source: game.proto
+
+package gamepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Mark int32
+
+const (
+	// MARK_TOGGLE toggles the mark of the square
+	Mark_MARK_TOGGLE Mark = 0
+	Mark_MARK_FLAG   Mark = 1
+	Mark_MARK_NONE   Mark = 2
+)
+
+// Enum value maps for Mark.
+var (
+	Mark_name = map[int32]string{
+		0: "MARK_TOGGLE",
+		1: "MARK_FLAG",
+		2: "MARK_NONE",
+	}
+	Mark_value = map[string]int32{
+		"MARK_TOGGLE": 0,
+		"MARK_FLAG":   1,
+		"MARK_NONE":   2,
+	}
+)
+
+func (x Mark) Enum() *Mark {
+	p := new(Mark)
+	*p = x
+	return p
+}
+
+func (x Mark) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Mark) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[0].Descriptor()
+}
+
+func (Mark) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[0]
+}
+
+func (x Mark) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Mark.Descriptor instead.
+func (Mark) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{0}
+}
+
+type SquareType int32
+
+const (
+	SquareType_SQUARE_TYPE_EMPTY SquareType = 0
+	SquareType_SQUARE_TYPE_BOMB  SquareType = 1
+)
+
+// Enum value maps for SquareType.
+var (
+	SquareType_name = map[int32]string{
+		0: "SQUARE_TYPE_EMPTY",
+		1: "SQUARE_TYPE_BOMB",
+	}
+	SquareType_value = map[string]int32{
+		"SQUARE_TYPE_EMPTY": 0,
+		"SQUARE_TYPE_BOMB":  1,
+	}
+)
+
+func (x SquareType) Enum() *SquareType {
+	p := new(SquareType)
+	*p = x
+	return p
+}
+
+func (x SquareType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SquareType) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[1].Descriptor()
+}
+
+func (SquareType) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[1]
+}
+
+func (x SquareType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SquareType.Descriptor instead.
+func (SquareType) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{1}
+}
+
+type CreateGameRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Rows    int32                  `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Columns int32                  `protobuf:"varint,2,opt,name=columns,proto3" json:"columns,omitempty"`
+	Bombs   int32                  `protobuf:"varint,3,opt,name=bombs,proto3" json:"bombs,omitempty"`
+	// mode is either classic, coop or versus. Classic when empty.
+	Mode          string `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGameRequest) Reset() {
+	*x = CreateGameRequest{}
+	mi := &file_game_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGameRequest) ProtoMessage() {}
+
+func (x *CreateGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGameRequest.ProtoReflect.Descriptor instead.
+func (*CreateGameRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateGameRequest) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetColumns() int32 {
+	if x != nil {
+		return x.Columns
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetBombs() int32 {
+	if x != nil {
+		return x.Bombs
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type GetGameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGameRequest) Reset() {
+	*x = GetGameRequest{}
+	mi := &file_game_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGameRequest) ProtoMessage() {}
+
+func (x *GetGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGameRequest.ProtoReflect.Descriptor instead.
+func (*GetGameRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetGameRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type PlaySquareRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	GameId string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Row    int32                  `protobuf:"varint,2,opt,name=row,proto3" json:"row,omitempty"`
+	Column int32                  `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	// if_version makes the move fail unless the game is at the given version
+	IfVersion *int64 `protobuf:"varint,4,opt,name=if_version,json=ifVersion,proto3,oneof" json:"if_version,omitempty"`
+	// idempotency_key makes retries of the move get its original outcome
+	IdempotencyKey string `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PlaySquareRequest) Reset() {
+	*x = PlaySquareRequest{}
+	mi := &file_game_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaySquareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaySquareRequest) ProtoMessage() {}
+
+func (x *PlaySquareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaySquareRequest.ProtoReflect.Descriptor instead.
+func (*PlaySquareRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PlaySquareRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *PlaySquareRequest) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *PlaySquareRequest) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *PlaySquareRequest) GetIfVersion() int64 {
+	if x != nil && x.IfVersion != nil {
+		return *x.IfVersion
+	}
+	return 0
+}
+
+func (x *PlaySquareRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type MarkSquareRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	GameId         string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Row            int32                  `protobuf:"varint,2,opt,name=row,proto3" json:"row,omitempty"`
+	Column         int32                  `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	Mark           Mark                   `protobuf:"varint,4,opt,name=mark,proto3,enum=minesweeper.game.v1.Mark" json:"mark,omitempty"`
+	IfVersion      *int64                 `protobuf:"varint,5,opt,name=if_version,json=ifVersion,proto3,oneof" json:"if_version,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MarkSquareRequest) Reset() {
+	*x = MarkSquareRequest{}
+	mi := &file_game_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkSquareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkSquareRequest) ProtoMessage() {}
+
+func (x *MarkSquareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkSquareRequest.ProtoReflect.Descriptor instead.
+func (*MarkSquareRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MarkSquareRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *MarkSquareRequest) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *MarkSquareRequest) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *MarkSquareRequest) GetMark() Mark {
+	if x != nil {
+		return x.Mark
+	}
+	return Mark_MARK_TOGGLE
+}
+
+func (x *MarkSquareRequest) GetIfVersion() int64 {
+	if x != nil && x.IfVersion != nil {
+		return *x.IfVersion
+	}
+	return 0
+}
+
+func (x *MarkSquareRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type WatchGameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchGameRequest) Reset() {
+	*x = WatchGameRequest{}
+	mi := &file_game_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchGameRequest) ProtoMessage() {}
+
+func (x *WatchGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchGameRequest.ProtoReflect.Descriptor instead.
+func (*WatchGameRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchGameRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Game struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	PlayerId      string                 `protobuf:"bytes,3,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Players       []*Participant         `protobuf:"bytes,4,rep,name=players,proto3" json:"players,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Turn          string                 `protobuf:"bytes,6,opt,name=turn,proto3" json:"turn,omitempty"`
+	Winner        string                 `protobuf:"bytes,7,opt,name=winner,proto3" json:"winner,omitempty"`
+	Board         *Board                 `protobuf:"bytes,8,opt,name=board,proto3" json:"board,omitempty"`
+	StartedAt     int64                  `protobuf:"varint,9,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    int64                  `protobuf:"varint,10,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	ElapsedTime   int64                  `protobuf:"varint,11,opt,name=elapsed_time,json=elapsedTime,proto3" json:"elapsed_time,omitempty"`
+	Version       int64                  `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Game) Reset() {
+	*x = Game{}
+	mi := &file_game_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Game) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Game) ProtoMessage() {}
+
+func (x *Game) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Game.ProtoReflect.Descriptor instead.
+func (*Game) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Game) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Game) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Game) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *Game) GetPlayers() []*Participant {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *Game) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Game) GetTurn() string {
+	if x != nil {
+		return x.Turn
+	}
+	return ""
+}
+
+func (x *Game) GetWinner() string {
+	if x != nil {
+		return x.Winner
+	}
+	return ""
+}
+
+func (x *Game) GetBoard() *Board {
+	if x != nil {
+		return x.Board
+	}
+	return nil
+}
+
+func (x *Game) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *Game) GetFinishedAt() int64 {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return 0
+}
+
+func (x *Game) GetElapsedTime() int64 {
+	if x != nil {
+		return x.ElapsedTime
+	}
+	return 0
+}
+
+func (x *Game) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type Participant struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RevealedSquaresCount int32                  `protobuf:"varint,2,opt,name=revealed_squares_count,json=revealedSquaresCount,proto3" json:"revealed_squares_count,omitempty"`
+	MarkedSquaresCount   int32                  `protobuf:"varint,3,opt,name=marked_squares_count,json=markedSquaresCount,proto3" json:"marked_squares_count,omitempty"`
+	Score                int32                  `protobuf:"varint,4,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Participant) Reset() {
+	*x = Participant{}
+	mi := &file_game_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Participant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Participant) ProtoMessage() {}
+
+func (x *Participant) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Participant.ProtoReflect.Descriptor instead.
+func (*Participant) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Participant) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Participant) GetRevealedSquaresCount() int32 {
+	if x != nil {
+		return x.RevealedSquaresCount
+	}
+	return 0
+}
+
+func (x *Participant) GetMarkedSquaresCount() int32 {
+	if x != nil {
+		return x.MarkedSquaresCount
+	}
+	return 0
+}
+
+func (x *Participant) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// Board is always obfuscated: unrevealed bombs are reported as empty squares
+type Board struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Rows                 []*Row                 `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	Status               string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	RevealedSquaresCount int32                  `protobuf:"varint,3,opt,name=revealed_squares_count,json=revealedSquaresCount,proto3" json:"revealed_squares_count,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Board) Reset() {
+	*x = Board{}
+	mi := &file_game_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Board) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Board) ProtoMessage() {}
+
+func (x *Board) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Board.ProtoReflect.Descriptor instead.
+func (*Board) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Board) GetRows() []*Row {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *Board) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Board) GetRevealedSquaresCount() int32 {
+	if x != nil {
+		return x.RevealedSquaresCount
+	}
+	return 0
+}
+
+type Row struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Squares       []*Square              `protobuf:"bytes,1,rep,name=squares,proto3" json:"squares,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	mi := &file_game_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Row) GetSquares() []*Square {
+	if x != nil {
+		return x.Squares
+	}
+	return nil
+}
+
+type Square struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          SquareType             `protobuf:"varint,1,opt,name=type,proto3,enum=minesweeper.game.v1.SquareType" json:"type,omitempty"`
+	Revealed      bool                   `protobuf:"varint,2,opt,name=revealed,proto3" json:"revealed,omitempty"`
+	Marked        bool                   `protobuf:"varint,3,opt,name=marked,proto3" json:"marked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Square) Reset() {
+	*x = Square{}
+	mi := &file_game_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Square) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Square) ProtoMessage() {}
+
+func (x *Square) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Square.ProtoReflect.Descriptor instead.
+func (*Square) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Square) GetType() SquareType {
+	if x != nil {
+		return x.Type
+	}
+	return SquareType_SQUARE_TYPE_EMPTY
+}
+
+func (x *Square) GetRevealed() bool {
+	if x != nil {
+		return x.Revealed
+	}
+	return false
+}
+
+func (x *Square) GetMarked() bool {
+	if x != nil {
+		return x.Marked
+	}
+	return false
+}
+
+type SquareChange struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Row      int32                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Column   int32                  `protobuf:"varint,2,opt,name=column,proto3" json:"column,omitempty"`
+	Type     SquareType             `protobuf:"varint,3,opt,name=type,proto3,enum=minesweeper.game.v1.SquareType" json:"type,omitempty"`
+	Revealed bool                   `protobuf:"varint,4,opt,name=revealed,proto3" json:"revealed,omitempty"`
+	Marked   bool                   `protobuf:"varint,5,opt,name=marked,proto3" json:"marked,omitempty"`
+	// count is the number of bombs adjacent to a revealed square
+	Count         int32 `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SquareChange) Reset() {
+	*x = SquareChange{}
+	mi := &file_game_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SquareChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SquareChange) ProtoMessage() {}
+
+func (x *SquareChange) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SquareChange.ProtoReflect.Descriptor instead.
+func (*SquareChange) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SquareChange) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *SquareChange) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *SquareChange) GetType() SquareType {
+	if x != nil {
+		return x.Type
+	}
+	return SquareType_SQUARE_TYPE_EMPTY
+}
+
+func (x *SquareChange) GetRevealed() bool {
+	if x != nil {
+		return x.Revealed
+	}
+	return false
+}
+
+func (x *SquareChange) GetMarked() bool {
+	if x != nil {
+		return x.Marked
+	}
+	return false
+}
+
+func (x *SquareChange) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GameEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// type is either snapshot, delta, status, tick or join
+	Type   string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	GameId string `protobuf:"bytes,2,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	// game is only set on snapshots
+	Game                 *Game           `protobuf:"bytes,3,opt,name=game,proto3" json:"game,omitempty"`
+	Squares              []*SquareChange `protobuf:"bytes,4,rep,name=squares,proto3" json:"squares,omitempty"`
+	Status               string          `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	PlayerId             string          `protobuf:"bytes,6,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Players              []*Participant  `protobuf:"bytes,7,rep,name=players,proto3" json:"players,omitempty"`
+	RevealedSquaresCount int32           `protobuf:"varint,8,opt,name=revealed_squares_count,json=revealedSquaresCount,proto3" json:"revealed_squares_count,omitempty"`
+	ElapsedTime          int64           `protobuf:"varint,9,opt,name=elapsed_time,json=elapsedTime,proto3" json:"elapsed_time,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GameEvent) Reset() {
+	*x = GameEvent{}
+	mi := &file_game_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameEvent) ProtoMessage() {}
+
+func (x *GameEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameEvent.ProtoReflect.Descriptor instead.
+func (*GameEvent) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GameEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *GameEvent) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GameEvent) GetGame() *Game {
+	if x != nil {
+		return x.Game
+	}
+	return nil
+}
+
+func (x *GameEvent) GetSquares() []*SquareChange {
+	if x != nil {
+		return x.Squares
+	}
+	return nil
+}
+
+func (x *GameEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GameEvent) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *GameEvent) GetPlayers() []*Participant {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *GameEvent) GetRevealedSquaresCount() int32 {
+	if x != nil {
+		return x.RevealedSquaresCount
+	}
+	return 0
+}
+
+func (x *GameEvent) GetElapsedTime() int64 {
+	if x != nil {
+		return x.ElapsedTime
+	}
+	return 0
+}
+
+var File_game_proto protoreflect.FileDescriptor
+
+const file_game_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"game.proto\x12\x13minesweeper.game.v1\"k\n" +
+	"\x11CreateGameRequest\x12\x12\n" +
+	"\x04rows\x18\x01 \x01(\x05R\x04rows\x12\x18\n" +
+	"\acolumns\x18\x02 \x01(\x05R\acolumns\x12\x14\n" +
+	"\x05bombs\x18\x03 \x01(\x05R\x05bombs\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\tR\x04mode\" \n" +
+	"\x0eGetGameRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xb2\x01\n" +
+	"\x11PlaySquareRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x10\n" +
+	"\x03row\x18\x02 \x01(\x05R\x03row\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\x05R\x06column\x12\"\n" +
+	"\n" +
+	"if_version\x18\x04 \x01(\x03H\x00R\tifVersion\x88\x01\x01\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKeyB\r\n" +
+	"\v_if_version\"\xe1\x01\n" +
+	"\x11MarkSquareRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x10\n" +
+	"\x03row\x18\x02 \x01(\x05R\x03row\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\x05R\x06column\x12-\n" +
+	"\x04mark\x18\x04 \x01(\x0e2\x19.minesweeper.game.v1.MarkR\x04mark\x12\"\n" +
+	"\n" +
+	"if_version\x18\x05 \x01(\x03H\x00R\tifVersion\x88\x01\x01\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKeyB\r\n" +
+	"\v_if_version\"\"\n" +
+	"\x10WatchGameRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xf6\x02\n" +
+	"\x04Game\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12\x1b\n" +
+	"\tplayer_id\x18\x03 \x01(\tR\bplayerId\x12:\n" +
+	"\aplayers\x18\x04 \x03(\v2 .minesweeper.game.v1.ParticipantR\aplayers\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x12\n" +
+	"\x04turn\x18\x06 \x01(\tR\x04turn\x12\x16\n" +
+	"\x06winner\x18\a \x01(\tR\x06winner\x120\n" +
+	"\x05board\x18\b \x01(\v2\x1a.minesweeper.game.v1.BoardR\x05board\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\t \x01(\x03R\tstartedAt\x12\x1f\n" +
+	"\vfinished_at\x18\n" +
+	" \x01(\x03R\n" +
+	"finishedAt\x12!\n" +
+	"\felapsed_time\x18\v \x01(\x03R\velapsedTime\x12\x18\n" +
+	"\aversion\x18\f \x01(\x03R\aversion\"\x9b\x01\n" +
+	"\vParticipant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x124\n" +
+	"\x16revealed_squares_count\x18\x02 \x01(\x05R\x14revealedSquaresCount\x120\n" +
+	"\x14marked_squares_count\x18\x03 \x01(\x05R\x12markedSquaresCount\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x05R\x05score\"\x83\x01\n" +
+	"\x05Board\x12,\n" +
+	"\x04rows\x18\x01 \x03(\v2\x18.minesweeper.game.v1.RowR\x04rows\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x124\n" +
+	"\x16revealed_squares_count\x18\x03 \x01(\x05R\x14revealedSquaresCount\"<\n" +
+	"\x03Row\x125\n" +
+	"\asquares\x18\x01 \x03(\v2\x1b.minesweeper.game.v1.SquareR\asquares\"q\n" +
+	"\x06Square\x123\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1f.minesweeper.game.v1.SquareTypeR\x04type\x12\x1a\n" +
+	"\brevealed\x18\x02 \x01(\bR\brevealed\x12\x16\n" +
+	"\x06marked\x18\x03 \x01(\bR\x06marked\"\xb7\x01\n" +
+	"\fSquareChange\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x05R\x03row\x12\x16\n" +
+	"\x06column\x18\x02 \x01(\x05R\x06column\x123\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x1f.minesweeper.game.v1.SquareTypeR\x04type\x12\x1a\n" +
+	"\brevealed\x18\x04 \x01(\bR\brevealed\x12\x16\n" +
+	"\x06marked\x18\x05 \x01(\bR\x06marked\x12\x14\n" +
+	"\x05count\x18\x06 \x01(\x05R\x05count\"\xee\x02\n" +
+	"\tGameEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x17\n" +
+	"\agame_id\x18\x02 \x01(\tR\x06gameId\x12-\n" +
+	"\x04game\x18\x03 \x01(\v2\x19.minesweeper.game.v1.GameR\x04game\x12;\n" +
+	"\asquares\x18\x04 \x03(\v2!.minesweeper.game.v1.SquareChangeR\asquares\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1b\n" +
+	"\tplayer_id\x18\x06 \x01(\tR\bplayerId\x12:\n" +
+	"\aplayers\x18\a \x03(\v2 .minesweeper.game.v1.ParticipantR\aplayers\x124\n" +
+	"\x16revealed_squares_count\x18\b \x01(\x05R\x14revealedSquaresCount\x12!\n" +
+	"\felapsed_time\x18\t \x01(\x03R\velapsedTime*5\n" +
+	"\x04Mark\x12\x0f\n" +
+	"\vMARK_TOGGLE\x10\x00\x12\r\n" +
+	"\tMARK_FLAG\x10\x01\x12\r\n" +
+	"\tMARK_NONE\x10\x02*9\n" +
+	"\n" +
+	"SquareType\x12\x15\n" +
+	"\x11SQUARE_TYPE_EMPTY\x10\x00\x12\x14\n" +
+	"\x10SQUARE_TYPE_BOMB\x10\x012\xa1\x03\n" +
+	"\vGameService\x12O\n" +
+	"\n" +
+	"CreateGame\x12&.minesweeper.game.v1.CreateGameRequest\x1a\x19.minesweeper.game.v1.Game\x12I\n" +
+	"\aGetGame\x12#.minesweeper.game.v1.GetGameRequest\x1a\x19.minesweeper.game.v1.Game\x12O\n" +
+	"\n" +
+	"PlaySquare\x12&.minesweeper.game.v1.PlaySquareRequest\x1a\x19.minesweeper.game.v1.Game\x12O\n" +
+	"\n" +
+	"MarkSquare\x12&.minesweeper.game.v1.MarkSquareRequest\x1a\x19.minesweeper.game.v1.Game\x12T\n" +
+	"\tWatchGame\x12%.minesweeper.game.v1.WatchGameRequest\x1a\x1e.minesweeper.game.v1.GameEvent0\x01B:Z8github.com/matiasvarela/minesweeper/internal/game/gamepbb\x06proto3"
+
+var (
+	file_game_proto_rawDescOnce sync.Once
+	file_game_proto_rawDescData []byte
+)
+
+func file_game_proto_rawDescGZIP() []byte {
+	file_game_proto_rawDescOnce.Do(func() {
+		file_game_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_game_proto_rawDesc), len(file_game_proto_rawDesc)))
+	})
+	return file_game_proto_rawDescData
+}
+
+var file_game_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_game_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_game_proto_goTypes = []any{
+	(Mark)(0),                 // 0: minesweeper.game.v1.Mark
+	(SquareType)(0),           // 1: minesweeper.game.v1.SquareType
+	(*CreateGameRequest)(nil), // 2: minesweeper.game.v1.CreateGameRequest
+	(*GetGameRequest)(nil),    // 3: minesweeper.game.v1.GetGameRequest
+	(*PlaySquareRequest)(nil), // 4: minesweeper.game.v1.PlaySquareRequest
+	(*MarkSquareRequest)(nil), // 5: minesweeper.game.v1.MarkSquareRequest
+	(*WatchGameRequest)(nil),  // 6: minesweeper.game.v1.WatchGameRequest
+	(*Game)(nil),              // 7: minesweeper.game.v1.Game
+	(*Participant)(nil),       // 8: minesweeper.game.v1.Participant
+	(*Board)(nil),             // 9: minesweeper.game.v1.Board
+	(*Row)(nil),               // 10: minesweeper.game.v1.Row
+	(*Square)(nil),            // 11: minesweeper.game.v1.Square
+	(*SquareChange)(nil),      // 12: minesweeper.game.v1.SquareChange
+	(*GameEvent)(nil),         // 13: minesweeper.game.v1.GameEvent
+}
+var file_game_proto_depIdxs = []int32{
+	0,  // 0: minesweeper.game.v1.MarkSquareRequest.mark:type_name -> minesweeper.game.v1.Mark
+	8,  // 1: minesweeper.game.v1.Game.players:type_name -> minesweeper.game.v1.Participant
+	9,  // 2: minesweeper.game.v1.Game.board:type_name -> minesweeper.game.v1.Board
+	10, // 3: minesweeper.game.v1.Board.rows:type_name -> minesweeper.game.v1.Row
+	11, // 4: minesweeper.game.v1.Row.squares:type_name -> minesweeper.game.v1.Square
+	1,  // 5: minesweeper.game.v1.Square.type:type_name -> minesweeper.game.v1.SquareType
+	1,  // 6: minesweeper.game.v1.SquareChange.type:type_name -> minesweeper.game.v1.SquareType
+	7,  // 7: minesweeper.game.v1.GameEvent.game:type_name -> minesweeper.game.v1.Game
+	12, // 8: minesweeper.game.v1.GameEvent.squares:type_name -> minesweeper.game.v1.SquareChange
+	8,  // 9: minesweeper.game.v1.GameEvent.players:type_name -> minesweeper.game.v1.Participant
+	2,  // 10: minesweeper.game.v1.GameService.CreateGame:input_type -> minesweeper.game.v1.CreateGameRequest
+	3,  // 11: minesweeper.game.v1.GameService.GetGame:input_type -> minesweeper.game.v1.GetGameRequest
+	4,  // 12: minesweeper.game.v1.GameService.PlaySquare:input_type -> minesweeper.game.v1.PlaySquareRequest
+	5,  // 13: minesweeper.game.v1.GameService.MarkSquare:input_type -> minesweeper.game.v1.MarkSquareRequest
+	6,  // 14: minesweeper.game.v1.GameService.WatchGame:input_type -> minesweeper.game.v1.WatchGameRequest
+	7,  // 15: minesweeper.game.v1.GameService.CreateGame:output_type -> minesweeper.game.v1.Game
+	7,  // 16: minesweeper.game.v1.GameService.GetGame:output_type -> minesweeper.game.v1.Game
+	7,  // 17: minesweeper.game.v1.GameService.PlaySquare:output_type -> minesweeper.game.v1.Game
+	7,  // 18: minesweeper.game.v1.GameService.MarkSquare:output_type -> minesweeper.game.v1.Game
+	13, // 19: minesweeper.game.v1.GameService.WatchGame:output_type -> minesweeper.game.v1.GameEvent
+	15, // [15:20] is the sub-list for method output_type
+	10, // [10:15] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_game_proto_init() }
+func file_game_proto_init() {
+	if File_game_proto != nil {
+		return
+	}
+	file_game_proto_msgTypes[2].OneofWrappers = []any{}
+	file_game_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_game_proto_rawDesc), len(file_game_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_game_proto_goTypes,
+		DependencyIndexes: file_game_proto_depIdxs,
+		EnumInfos:         file_game_proto_enumTypes,
+		MessageInfos:      file_game_proto_msgTypes,
+	}.Build()
+	File_game_proto = out.File
+	file_game_proto_goTypes = nil
+	file_game_proto_depIdxs = nil
+}
@@ -0,0 +1,6 @@
+// Package gamepb holds the protobuf definition of the game service along with the code generated from it
+package gamepb
+
+//go:generate go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.9
+//go:generate go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+//go:generate go run github.com/bufbuild/buf/cmd/buf@v1.47.2 alpha protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative game.proto
@@ -0,0 +1,254 @@
+package game
+
+import (
+	"context"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game/gamepb"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	gamepb.UnimplementedGameServiceServer
+
+	service Service
+	hub     *Hub
+}
+
+// NewGrpcServer return the gRPC game service backed by the given service
+func NewGrpcServer(service Service, hub *Hub) gamepb.GameServiceServer {
+	return &grpcServer{service: service, hub: hub}
+}
+
+func (s *grpcServer) CreateGame(ctx context.Context, req *gamepb.CreateGameRequest) (*gamepb.Game, error) {
+	playerID, err := player.OptionalFromIncomingContext(ctx)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+
+	configuration := Configuration{
+		Rows:    int(req.GetRows()),
+		Columns: int(req.GetColumns()),
+		Bombs:   int(req.GetBombs()),
+		Mode:    req.GetMode(),
+	}
+
+	err = validate.Struct(configuration)
+	if err != nil {
+		return nil, toGrpcError(errors.New(apperrors.InvalidInput, err, "invalid request", "validations has failed"))
+	}
+
+	game, err := s.service.Create(configuration, playerID)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+
+	return toProtoGame(game), nil
+}
+
+func (s *grpcServer) GetGame(ctx context.Context, req *gamepb.GetGameRequest) (*gamepb.Game, error) {
+	game, err := s.service.Get(req.GetId())
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+
+	return toProtoGame(game), nil
+}
+
+func (s *grpcServer) PlaySquare(ctx context.Context, req *gamepb.PlaySquareRequest) (*gamepb.Game, error) {
+	playerID, err := player.OptionalFromIncomingContext(ctx)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+
+	game, err := s.service.PlaySquare(req.GetGameId(), Action{
+		PlayerID:       playerID,
+		Position:       board.SquarePosition{Row: int(req.GetRow()), Column: int(req.GetColumn())},
+		IfVersion:      req.IfVersion,
+		IdempotencyKey: req.GetIdempotencyKey(),
+	})
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+
+	return toProtoGame(game), nil
+}
+
+func (s *grpcServer) MarkSquare(ctx context.Context, req *gamepb.MarkSquareRequest) (*gamepb.Game, error) {
+	playerID, err := player.OptionalFromIncomingContext(ctx)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+
+	action := Action{
+		PlayerID:       playerID,
+		Position:       board.SquarePosition{Row: int(req.GetRow()), Column: int(req.GetColumn())},
+		IfVersion:      req.IfVersion,
+		IdempotencyKey: req.GetIdempotencyKey(),
+	}
+
+	switch req.GetMark() {
+	case gamepb.Mark_MARK_FLAG:
+		action.Mark = MARK_FLAG
+	case gamepb.Mark_MARK_NONE:
+		action.Mark = MARK_NONE
+	}
+
+	game, err := s.service.MarkSquare(req.GetGameId(), action)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+
+	return toProtoGame(game), nil
+}
+
+// WatchGame streams a snapshot of the game followed by the changes notified by the hub until the client leaves,
+// or until it falls behind the changes and has to watch the game again
+func (s *grpcServer) WatchGame(req *gamepb.WatchGameRequest, stream grpc.ServerStreamingServer[gamepb.GameEvent]) error {
+	game, err := s.service.Get(req.GetId())
+	if err != nil {
+		return toGrpcError(err)
+	}
+
+	events, unsubscribe := s.hub.Subscribe(game.ID)
+	defer unsubscribe()
+
+	snapshot := toProtoGame(game)
+
+	err = stream.Send(&gamepb.GameEvent{
+		Type:                 EVENT_SNAPSHOT,
+		GameId:               game.ID,
+		Game:                 snapshot,
+		Status:               snapshot.Board.Status,
+		RevealedSquaresCount: snapshot.Board.RevealedSquaresCount,
+		ElapsedTime:          snapshot.ElapsedTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				// the hub dropped the subscription, since the client is not keeping up with the events
+				return status.Error(codes.Unavailable, "the stream has fallen behind the game, watch it again")
+			}
+
+			err = stream.Send(toProtoEvent(event))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toProtoGame return the obfuscated game as a protobuf message
+func toProtoGame(game Game) *gamepb.Game {
+	game.UpdateElapsedTime()
+	game.Board.Obfuscate()
+
+	rows := make([]*gamepb.Row, len(game.Board.Squares))
+	for i := range game.Board.Squares {
+		squares := make([]*gamepb.Square, len(game.Board.Squares[i]))
+
+		for j, square := range game.Board.Squares[i] {
+			squares[j] = &gamepb.Square{
+				Type:     gamepb.SquareType(square.Type),
+				Revealed: square.Revealed,
+				Marked:   square.Marked,
+			}
+		}
+
+		rows[i] = &gamepb.Row{Squares: squares}
+	}
+
+	return &gamepb.Game{
+		Id:       game.ID,
+		Mode:     game.Mode,
+		PlayerId: game.PlayerID,
+		Players:  toProtoParticipants(game.Players),
+		Status:   game.Status,
+		Turn:     game.Turn,
+		Winner:   game.Winner,
+		Board: &gamepb.Board{
+			Rows:                 rows,
+			Status:               game.Board.Status,
+			RevealedSquaresCount: int32(game.Board.RevealedSquaresCount),
+		},
+		StartedAt:   game.StartedAt,
+		FinishedAt:  game.FinishedAt,
+		ElapsedTime: game.ElapsedTime,
+		Version:     game.Version,
+	}
+}
+
+func toProtoParticipants(participants []Participant) []*gamepb.Participant {
+	players := make([]*gamepb.Participant, len(participants))
+
+	for i, p := range participants {
+		players[i] = &gamepb.Participant{
+			Id:                   p.ID,
+			RevealedSquaresCount: int32(p.RevealedSquaresCount),
+			MarkedSquaresCount:   int32(p.MarkedSquaresCount),
+			Score:                int32(p.Score),
+		}
+	}
+
+	return players
+}
+
+func toProtoEvent(event Event) *gamepb.GameEvent {
+	squares := make([]*gamepb.SquareChange, len(event.Squares))
+
+	for i, square := range event.Squares {
+		squares[i] = &gamepb.SquareChange{
+			Row:      int32(square.Row),
+			Column:   int32(square.Column),
+			Type:     gamepb.SquareType(square.Type),
+			Revealed: square.Revealed,
+			Marked:   square.Marked,
+			Count:    int32(square.Count),
+		}
+	}
+
+	return &gamepb.GameEvent{
+		Type:                 event.Type,
+		GameId:               event.GameID,
+		Squares:              squares,
+		Status:               event.Status,
+		PlayerId:             event.PlayerID,
+		Players:              toProtoParticipants(event.Players),
+		RevealedSquaresCount: int32(event.RevealedSquaresCount),
+		ElapsedTime:          event.ElapsedTime,
+	}
+}
+
+// toGrpcError return the gRPC status error equivalent to the given error
+func toGrpcError(err error) error {
+	switch errors.Code(err) {
+	case "not_found":
+		return status.Error(codes.NotFound, err.Error())
+	case "validation", "invalid_input":
+		return status.Error(codes.InvalidArgument, err.Error())
+	case "unauthorized":
+		return status.Error(codes.Unauthenticated, err.Error())
+	case "forbidden":
+		return status.Error(codes.PermissionDenied, err.Error())
+	case "out_of_turn", "precondition_failed":
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case "conflict":
+		return status.Error(codes.Aborted, err.Error())
+	case "unsupported":
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -1,9 +1,19 @@
 package game
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/matiasvarela/errors"
 	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/player"
 	"github.com/matiasvarela/minesweeper/pkg/apperrors"
 	"gopkg.in/go-playground/validator.v8"
 )
@@ -11,25 +21,59 @@ import (
 type HttpHandler interface {
 	Create(*gin.Context)
 	Get(*gin.Context)
+	List(*gin.Context)
+	Delete(*gin.Context)
 	PlaySquare(c *gin.Context)
 	MarkSquare(c *gin.Context)
+	PlayMoves(c *gin.Context)
+	GetMoves(c *gin.Context)
+	Join(c *gin.Context)
+	Watch(c *gin.Context)
+	Events(c *gin.Context)
 }
 
 type httpHandler struct {
 	service Service
+	hub     *Hub
 }
 
+const (
+	// LastEventIDHeader is the header used by event stream clients to resume a stream
+	LastEventIDHeader = "Last-Event-ID"
+
+	// IdempotencyKeyHeader is the header used by clients to safely retry moves
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// DeltaMediaType is the media type accepted by clients that want move endpoints to reply deltas
+	DeltaMediaType = "application/vnd.minesweeper.delta+json"
+
+	// CompactMediaType is the media type accepted by clients that want games with the board in the compact encoding
+	CompactMediaType = "application/vnd.minesweeper.compact+json"
+
+	// maxIdempotencyKeyLength is the max length allowed for idempotency keys
+	maxIdempotencyKeyLength = 255
+
+	// keepAliveInterval is how often a comment is sent through idle event streams
+	keepAliveInterval = 15 * time.Second
+)
+
 var (
 	validate *validator.Validate
+	upgrader = websocket.Upgrader{
+		// origins are not restricted, same as the cors configuration of the api
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
 )
 
 func init() {
 	validate = validator.New(&validator.Config{TagName: "validate"})
 	validate.RegisterStructValidation(ConfigurationStructValidation, Configuration{})
+	validate.RegisterStructValidation(MarkSquareBodyStructValidation, MarkSquareBody{})
+	validate.RegisterStructValidation(MoveBodyStructValidation, MoveBody{})
 }
 
-func NewHttpHandler(service Service) HttpHandler {
-	return &httpHandler{service}
+func NewHttpHandler(service Service, hub *Hub) HttpHandler {
+	return &httpHandler{service, hub}
 }
 
 func (h *httpHandler) Get(c *gin.Context) {
@@ -40,16 +84,83 @@ func (h *httpHandler) Get(c *gin.Context) {
 		return
 	}
 
-	game.updateElapsedTime()
-	game.Board.Obfuscate()
+	respondGame(c, 200, game)
+}
+
+// List replies the obfuscated games of the player matching the query. The games of other players are never
+// listed, since lost games reveal their bombs layout, which daily challenges and matches share between players.
+func (h *httpHandler) List(c *gin.Context) {
+	playerID, err := player.FromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	query := ListQuery{}
+
+	err = c.ShouldBindQuery(&query)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid query", "bind query has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
 
-	c.JSON(200, game)
+	err = validate.Struct(query)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid query", "validations has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	filter := Filter{PlayerID: playerID, Mode: query.Mode, Status: query.Status, Limit: query.Limit, Offset: query.Offset}
+
+	list, err := h.service.List(filter)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	for i := range list.Games {
+		list.Games[i].UpdateElapsedTime()
+		list.Games[i].Board.Obfuscate()
+		list.Games[i].Moves = nil
+	}
+
+	c.JSON(200, list)
+}
+
+// Delete deletes the game, which is only allowed to its owner
+func (h *httpHandler) Delete(c *gin.Context) {
+	playerID, err := player.FromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	err = h.service.Delete(c.Param("id"), playerID)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	c.Status(204)
 }
 
 func (h *httpHandler) Create(c *gin.Context) {
+	playerID, err := player.OptionalFromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
 	configuration := Configuration{}
 
-	err := c.BindJSON(&configuration)
+	err = c.BindJSON(&configuration)
 	if err != nil {
 		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "bind json has failed"))
 		c.AbortWithStatusJSON(apierr.Status, apierr)
@@ -63,23 +174,27 @@ func (h *httpHandler) Create(c *gin.Context) {
 		return
 	}
 
-	game, err := h.service.Create(configuration)
+	game, err := h.service.Create(configuration, playerID)
 	if err != nil {
 		apierr := apperrors.ToApiError(err)
 		c.AbortWithStatusJSON(apierr.Status, apierr)
 		return
 	}
 
-	game.updateElapsedTime()
-	game.Board.Obfuscate()
-
-	c.JSON(201, game)
+	respondGame(c, 201, game)
 }
 
 func (h *httpHandler) PlaySquare(c *gin.Context) {
+	playerID, err := player.OptionalFromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
 	body := PlaySquareBody{}
 
-	err := c.BindJSON(&body)
+	err = c.BindJSON(&body)
 	if err != nil {
 		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "bind json has failed"))
 		c.AbortWithStatusJSON(apierr.Status, apierr)
@@ -93,23 +208,46 @@ func (h *httpHandler) PlaySquare(c *gin.Context) {
 		return
 	}
 
-	game, err := h.service.PlaySquare(c.Param("id"), board.SquarePosition{Row: body.Row, Column: body.Column})
+	ifVersion, err := ifMatch(c)
 	if err != nil {
 		apierr := apperrors.ToApiError(err)
 		c.AbortWithStatusJSON(apierr.Status, apierr)
 		return
 	}
 
-	game.updateElapsedTime()
-	game.Board.Obfuscate()
+	idempotencyKey, err := idempotencyKey(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	game, err := h.service.PlaySquare(c.Param("id"), Action{
+		PlayerID:       playerID,
+		Position:       board.SquarePosition{Row: body.Row, Column: body.Column},
+		IfVersion:      ifVersion,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
 
-	c.JSON(200, game)
+	respondMove(c, game)
 }
 
 func (h *httpHandler) MarkSquare(c *gin.Context) {
+	playerID, err := player.OptionalFromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
 	body := MarkSquareBody{}
 
-	err := c.BindJSON(&body)
+	err = c.BindJSON(&body)
 	if err != nil {
 		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "bind json has failed"))
 		c.AbortWithStatusJSON(apierr.Status, apierr)
@@ -123,15 +261,543 @@ func (h *httpHandler) MarkSquare(c *gin.Context) {
 		return
 	}
 
-	game, err := h.service.MarkSquare(c.Param("id"), board.SquarePosition{Row: body.Row, Column: body.Column})
+	ifVersion, err := ifMatch(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	idempotencyKey, err := idempotencyKey(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	game, err := h.service.MarkSquare(c.Param("id"), Action{
+		PlayerID:       playerID,
+		Position:       board.SquarePosition{Row: body.Row, Column: body.Column},
+		Mark:           body.Mark,
+		IfVersion:      ifVersion,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	respondMove(c, game)
+}
+
+// PlayMoves applies a batch of moves on the game at once
+func (h *httpHandler) PlayMoves(c *gin.Context) {
+	playerID, err := player.OptionalFromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	body := MovesBody{}
+
+	err = c.BindJSON(&body)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "bind json has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	err = validate.Struct(body)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "validations has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	ifVersion, err := ifMatch(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	batch := Batch{PlayerID: playerID, IfVersion: ifVersion}
+	for _, m := range body.Moves {
+		batch.Moves = append(batch.Moves, BatchMove{
+			Type:     m.Type,
+			Position: board.SquarePosition{Row: m.Row, Column: m.Column},
+			Mark:     m.Mark,
+		})
+	}
+
+	result, err := h.service.PlayMoves(c.Param("id"), batch)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	setETag(c, result.Game)
+	c.Header("Vary", "Accept")
+
+	if wantsDelta(c) {
+		setDeltaContentType(c)
+		c.JSON(200, BatchDelta{Moves: result.Moves, Skipped: result.Skipped, Delta: newDelta(result.Game, result.Moves...)})
+		return
+	}
+
+	c.JSON(200, batchView{Moves: result.Moves, Skipped: result.Skipped, Game: gameView(c, result.Game)})
+}
+
+// GetMoves replies a page of the move log of the game, without exposing any bomb while the game is on going
+func (h *httpHandler) GetMoves(c *gin.Context) {
+	query := MovesQuery{}
+
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid query", "bind query has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	err = validate.Struct(query)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid query", "validations has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	list, err := h.service.GetMoves(c.Param("id"), query.After, query.Limit)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	moves := make([]Move, len(list.Moves))
+	for i, move := range list.Moves {
+		moves[i] = spectatorMove(move)
+	}
+
+	list.Moves = moves
+
+	c.JSON(200, list)
+}
+
+func (h *httpHandler) Join(c *gin.Context) {
+	playerID, err := player.FromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	game, err := h.service.Join(c.Param("id"), playerID)
 	if err != nil {
 		apierr := apperrors.ToApiError(err)
 		c.AbortWithStatusJSON(apierr.Status, apierr)
 		return
 	}
 
-	game.updateElapsedTime()
+	respondGame(c, 200, game)
+}
+
+// respondMove replies the game resulting from a move, or only the delta of the move when the client asks for it
+func respondMove(c *gin.Context, game Game) {
+	setETag(c, game)
+	c.Header("Vary", "Accept")
+
+	if wantsDelta(c) {
+		moves := []Move{}
+		if len(game.Moves) > 0 {
+			moves = game.Moves[len(game.Moves)-1:]
+		}
+
+		setDeltaContentType(c)
+		c.JSON(200, newDelta(game, moves...))
+		return
+	}
+
+	c.JSON(200, gameView(c, game))
+}
+
+// respondGame replies the obfuscated game in the encoding requested by the client
+func respondGame(c *gin.Context, status int, game Game) {
+	setETag(c, game)
+	c.Header("Vary", "Accept")
+
+	c.JSON(status, gameView(c, game))
+}
+
+// gameView return the obfuscated game in the encoding requested by the client, which is the compact one
+// when asked through the Accept header or the encoding query param. The move log is left out, it is read
+// page by page through GetMoves.
+func gameView(c *gin.Context, game Game) interface{} {
+	game.UpdateElapsedTime()
 	game.Board.Obfuscate()
+	game.Moves = nil
+
+	accept := c.GetHeader("Accept")
+	if c.Query("encoding") != "compact" && !strings.Contains(accept, CompactMediaType) {
+		return game
+	}
+
+	if strings.Contains(accept, CompactMediaType) {
+		c.Header("Content-Type", CompactMediaType)
+	}
+
+	return CompactGame{Game: game, Board: game.Board.Compact()}
+}
+
+// wantsDelta whether the client asked for the delta of the move instead of the whole game,
+// either through the view query param or the Accept header
+func wantsDelta(c *gin.Context) bool {
+	return c.Query("view") == "delta" || strings.Contains(c.GetHeader("Accept"), DeltaMediaType)
+}
+
+// setDeltaContentType sets the delta media type as the response content type when the client asked for it
+func setDeltaContentType(c *gin.Context) {
+	if strings.Contains(c.GetHeader("Accept"), DeltaMediaType) {
+		c.Header("Content-Type", DeltaMediaType)
+	}
+}
+
+// setETag sets the version of the game as its entity tag, so clients can make conditional moves with If-Match
+func setETag(c *gin.Context, game Game) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(game.Version, 10)))
+}
+
+// ifMatch return the version of the game expected by the request or nil when any version is fine
+func ifMatch(c *gin.Context) (*int64, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return nil, errors.New(apperrors.InvalidInput, err, "invalid If-Match header", "entity tag is not quoted")
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return nil, errors.New(apperrors.InvalidInput, err, "invalid If-Match header", "entity tag is not a game version")
+	}
+
+	return &version, nil
+}
+
+// idempotencyKey return the idempotency key of the request or an empty string when there is none
+func idempotencyKey(c *gin.Context) (string, error) {
+	key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+	if len(key) > maxIdempotencyKeyLength {
+		return "", errors.New(apperrors.InvalidInput, nil, "invalid Idempotency-Key header", "idempotency key is too long")
+	}
+
+	return key, nil
+}
+
+// Watch upgrades the connection to a web socket that streams the obfuscated changes of the game
+// and accepts commands to play or mark squares
+func (h *httpHandler) Watch(c *gin.Context) {
+	playerID, err := player.OptionalFromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	game, err := h.service.Get(c.Param("id"))
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader has already replied with the corresponding http error
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := h.hub.Subscribe(game.ID)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	replies := make(chan Event)
+	go h.readCommands(ctx, cancel, conn, game.ID, playerID, replies)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	game.UpdateElapsedTime()
+	game.Board.Obfuscate()
+	game.Moves = nil
+
+	status, startedAt := game.Board.Status, game.StartedAt
+
+	err = conn.WriteJSON(Event{Type: EVENT_SNAPSHOT, GameID: game.ID, Game: &game, Status: status,
+		RevealedSquaresCount: game.Board.RevealedSquaresCount, ElapsedTime: game.ElapsedTime})
+	if err != nil {
+		return
+	}
+
+	for {
+		var event Event
+
+		select {
+		case <-ctx.Done():
+			return
+		case event = <-replies:
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			event = e
+			status = event.Status
+
+			if startedAt == 0 {
+				startedAt = time.Now().Unix() - event.ElapsedTime
+			}
+		case <-ticker.C:
+			if status != board.STATUS_ON_GOING || startedAt == 0 {
+				continue
+			}
+
+			event = Event{Type: EVENT_TICK, GameID: game.ID, Status: status, ElapsedTime: time.Now().Unix() - startedAt}
+		}
+
+		err = conn.WriteJSON(event)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readCommands executes the commands received through the web socket until it gets closed.
+// The outcome of a successful command reaches the client as a hub event, so only failures are replied.
+func (h *httpHandler) readCommands(ctx context.Context, cancel func(), conn *websocket.Conn, gameID string, playerID string, replies chan<- Event) {
+	defer cancel()
 
-	c.JSON(200, game)
-}
\ No newline at end of file
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		command := Command{}
+
+		err = json.Unmarshal(message, &command)
+		if err != nil {
+			err = errors.New(apperrors.InvalidInput, err, "invalid command", "unmarshal json has failed")
+		} else {
+			err = h.execute(gameID, playerID, command)
+		}
+
+		if err == nil {
+			continue
+		}
+
+		select {
+		case replies <- Event{Type: EVENT_ERROR, GameID: gameID, Error: apperrors.ToApiError(err)}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *httpHandler) execute(gameID string, playerID string, command Command) error {
+	err := validate.Struct(command)
+	if err != nil {
+		return errors.New(apperrors.InvalidInput, err, "invalid command", "validations has failed")
+	}
+
+	pos := board.SquarePosition{Row: command.Row, Column: command.Column}
+
+	switch command.Action {
+	case MOVE_PLAY_SQUARE:
+		_, err = h.service.PlaySquare(gameID, Action{PlayerID: playerID, Position: pos})
+	case MOVE_MARK_SQUARE:
+		if command.Mark != "" && command.Mark != MARK_FLAG && command.Mark != MARK_NONE {
+			return errors.New(apperrors.InvalidInput, nil, "invalid command", "unknown mark "+command.Mark)
+		}
+
+		_, err = h.service.MarkSquare(gameID, Action{PlayerID: playerID, Position: pos, Mark: command.Mark})
+	default:
+		err = errors.New(apperrors.InvalidInput, nil, "invalid command", "unknown action "+command.Action)
+	}
+
+	return err
+}
+
+// Events streams the moves of the game as server-sent events, meant for read-only spectators.
+// Every move is sent as a "move" event, followed by a "status" event when it changes the game status,
+// and carries the move id as event id. Streams are resumed from the game move log through the
+// Last-Event-ID header or the last_event_id query param. The moves missing between the live events,
+// which may be notified out of order, are sent from the log, and the stream ends when the hub drops
+// its subscription so that the client resumes it.
+func (h *httpHandler) Events(c *gin.Context) {
+	lastEventID, err := lastEventID(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	// subscribe before reading the game so no move is lost between the log replay and the live events
+	events, unsubscribe := h.hub.Subscribe(c.Param("id"))
+	defer unsubscribe()
+
+	game, err := h.service.Get(c.Param("id"))
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(200)
+
+	lastEventID, err = writeMoveEvents(c, game.Moves, lastEventID)
+	if err != nil {
+		return
+	}
+
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			_, err = fmt.Fprint(c.Writer, ": keep-alive\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			// the status event is written along with its move
+			if event.Type != EVENT_DELTA || event.Move.ID <= lastEventID {
+				continue
+			}
+
+			if event.Move.ID == lastEventID+1 {
+				err = writeMoveEvent(c, *event.Move)
+				lastEventID = event.Move.ID
+				break
+			}
+
+			// the log holds the event move along with the missing ones, since moves are notified once stored
+			game, err = h.service.Get(game.ID)
+			if err != nil {
+				return
+			}
+
+			lastEventID, err = writeMoveEvents(c, game.Moves, lastEventID)
+		}
+
+		if err != nil {
+			return
+		}
+
+		c.Writer.Flush()
+	}
+}
+
+// writeMoveEvents writes the moves following the last event id and return the id of the last one written
+func writeMoveEvents(c *gin.Context, moves []Move, lastEventID int64) (int64, error) {
+	for _, move := range moves {
+		if move.ID <= lastEventID {
+			continue
+		}
+
+		if err := writeMoveEvent(c, move); err != nil {
+			return lastEventID, err
+		}
+
+		lastEventID = move.ID
+	}
+
+	return lastEventID, nil
+}
+
+// writeMoveEvent writes the move as a spectator safe event. When the move changed the game status,
+// a status event is written too and the event id is attached to it, so a resumed stream never skips it.
+func writeMoveEvent(c *gin.Context, move Move) error {
+	move = spectatorMove(move)
+
+	data, err := json.Marshal(move)
+	if err != nil {
+		return err
+	}
+
+	if !move.StatusChanged {
+		_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: move\ndata: %s\n\n", move.ID, data)
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "event: move\ndata: %s\n\n", data)
+	if err != nil {
+		return err
+	}
+
+	data, err = json.Marshal(gin.H{"status": move.Status, "revealed_squares_count": move.RevealedSquaresCount})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: status\ndata: %s\n\n", move.ID, data)
+
+	return err
+}
+
+// spectatorMove hide any bomb of the move while the game is not finished
+func spectatorMove(move Move) Move {
+	if move.Status == board.STATUS_WON || move.Status == board.STATUS_LOST {
+		return move
+	}
+
+	squares := make([]SquareChange, len(move.Squares))
+	for i, square := range move.Squares {
+		if square.Type == board.BOMB {
+			square.Type = board.EMPTY
+		}
+
+		squares[i] = square
+	}
+
+	move.Squares = squares
+
+	return move
+}
+
+func lastEventID(c *gin.Context) (int64, error) {
+	value := c.GetHeader(LastEventIDHeader)
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New(apperrors.InvalidInput, err, "invalid last event id", "last event id is not a positive number")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"sync"
+
+	"github.com/matiasvarela/minesweeper/internal/board"
+)
+
+const (
+	EVENT_SNAPSHOT string = "snapshot"
+	EVENT_DELTA    string = "delta"
+	EVENT_STATUS   string = "status"
+	EVENT_TICK     string = "tick"
+	EVENT_JOIN     string = "join"
+	EVENT_ERROR    string = "error"
+
+	// subscriberBuffer is the number of events a subscriber can fall behind before its subscription is dropped
+	subscriberBuffer = 32
+)
+
+// SquareChange is the obfuscated state of a square that has changed after a move
+type SquareChange struct {
+	Row      int  `json:"row"`
+	Column   int  `json:"column"`
+	Type     int  `json:"type"`
+	Revealed bool `json:"revealed"`
+	Marked   bool `json:"marked"`
+	// Count is the number of bombs adjacent to a revealed square
+	Count int `json:"count"`
+}
+
+// Event is a change on a game notified to its subscribers
+type Event struct {
+	Type                 string         `json:"type"`
+	GameID               string         `json:"game_id"`
+	Game                 *Game          `json:"game,omitempty"`
+	Move                 *Move          `json:"-"`
+	Squares              []SquareChange `json:"squares,omitempty"`
+	Status               string         `json:"status,omitempty"`
+	PlayerID             string         `json:"player_id,omitempty"`
+	Players              []Participant  `json:"players,omitempty"`
+	RevealedSquaresCount int            `json:"revealed_squares_count"`
+	ElapsedTime          int64          `json:"elapsed_time"`
+	Error                interface{}    `json:"error,omitempty"`
+}
+
+// Hub is an in-process publish/subscribe hub of game events
+type Hub struct {
+	mutex       sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: map[string]map[chan Event]struct{}{}}
+}
+
+// Subscribe return a channel receiving the events of the given game and the function to cancel the subscription.
+// The channel is closed when the subscription is cancelled, or when the subscriber falls behind and misses events.
+func (h *Hub) Subscribe(gameID string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mutex.Lock()
+	if _, ok := h.subscribers[gameID]; !ok {
+		h.subscribers[gameID] = map[chan Event]struct{}{}
+	}
+	h.subscribers[gameID][ch] = struct{}{}
+	h.mutex.Unlock()
+
+	return ch, func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		h.remove(gameID, ch)
+	}
+}
+
+// Publish notify the given event to every subscriber of the game. It never blocks: the subscriptions of
+// the subscribers that are not keeping up are dropped, so they know they missed the event.
+func (h *Hub) Publish(event Event) {
+	lagging := []chan Event{}
+
+	h.mutex.RLock()
+	for ch := range h.subscribers[event.GameID] {
+		select {
+		case ch <- event:
+		default:
+			lagging = append(lagging, ch)
+		}
+	}
+	h.mutex.RUnlock()
+
+	if len(lagging) == 0 {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, ch := range lagging {
+		h.remove(event.GameID, ch)
+	}
+}
+
+// remove cancels the subscription and closes its channel, unless it has already been removed
+func (h *Hub) remove(gameID string, ch chan Event) {
+	if _, ok := h.subscribers[gameID][ch]; !ok {
+		return
+	}
+
+	delete(h.subscribers[gameID], ch)
+	if len(h.subscribers[gameID]) == 0 {
+		delete(h.subscribers, gameID)
+	}
+
+	close(ch)
+}
+
+// publishMove notify the subscribers of the game about the squares changed by a move
+// and, if it is the case, about the new status of the game
+func (h *Hub) publishMove(g Game, move Move) {
+	g.UpdateElapsedTime()
+
+	h.Publish(Event{
+		Type:                 EVENT_DELTA,
+		GameID:               g.ID,
+		Move:                 &move,
+		Squares:              move.Squares,
+		PlayerID:             move.PlayerID,
+		Players:              g.Players,
+		Status:               move.Status,
+		RevealedSquaresCount: move.RevealedSquaresCount,
+		ElapsedTime:          g.ElapsedTime,
+	})
+
+	if move.StatusChanged {
+		h.Publish(Event{
+			Type:                 EVENT_STATUS,
+			GameID:               g.ID,
+			Move:                 &move,
+			Status:               move.Status,
+			RevealedSquaresCount: move.RevealedSquaresCount,
+			ElapsedTime:          g.ElapsedTime,
+		})
+	}
+}
+
+// publishJoin notify the subscribers of the game that a new player has joined it
+func (h *Hub) publishJoin(g Game, playerID string) {
+	g.UpdateElapsedTime()
+
+	h.Publish(Event{
+		Type:                 EVENT_JOIN,
+		GameID:               g.ID,
+		Status:               g.Board.Status,
+		PlayerID:             playerID,
+		Players:              g.Players,
+		RevealedSquaresCount: g.Board.RevealedSquaresCount,
+		ElapsedTime:          g.ElapsedTime,
+	})
+}
+
+// squareChanges return the obfuscated state of the squares in the given positions, skipping repeated ones
+func squareChanges(b board.Board, positions []board.SquarePosition) []SquareChange {
+	changes := []SquareChange{}
+	seen := map[board.SquarePosition]bool{}
+
+	for _, pos := range positions {
+		if seen[pos] {
+			continue
+		}
+
+		seen[pos] = true
+
+		square := b.Get(pos)
+
+		change := SquareChange{Row: pos.Row, Column: pos.Column, Type: board.EMPTY, Revealed: square.Revealed, Marked: square.Marked}
+		if square.Revealed {
+			change.Type = square.Type
+
+			if square.Type != board.BOMB {
+				change.Count = b.CountNeighborBombs(pos)
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
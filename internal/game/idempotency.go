@@ -0,0 +1,93 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyKeysPerGame is the number of recent idempotency keys remembered for each game
+	idempotencyKeysPerGame = 32
+	// idempotencyKeyTTL is how long an idempotency key is remembered
+	idempotencyKeyTTL = 10 * time.Minute
+)
+
+// idempotentResult is the outcome of an action performed with an idempotency key
+type idempotentResult struct {
+	key         string
+	moveType    string
+	fingerprint Action
+	game        Game
+	expiresAt   time.Time
+}
+
+// idempotencyKeys remembers the outcome of the recent actions performed on each game with an idempotency key,
+// so retried requests get the original outcome instead of performing the action again
+type idempotencyKeys struct {
+	mutex   sync.Mutex
+	results map[string][]idempotentResult
+}
+
+func newIdempotencyKeys() *idempotencyKeys {
+	return &idempotencyKeys{results: map[string][]idempotentResult{}}
+}
+
+// get return the outcome of the action previously performed on the game with the given key
+func (k *idempotencyKeys) get(gameID string, key string) (idempotentResult, bool) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := time.Now()
+
+	for _, result := range k.results[gameID] {
+		if result.key == key && now.Before(result.expiresAt) {
+			result.game = result.game.Clone()
+			return result, true
+		}
+	}
+
+	return idempotentResult{}, false
+}
+
+// put remember the outcome of an action performed on the game with the given key
+func (k *idempotencyKeys) put(gameID string, key string, moveType string, action Action, g Game) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := time.Now()
+
+	g = g.Clone()
+
+	results := append(k.results[gameID], idempotentResult{
+		key:         key,
+		moveType:    moveType,
+		fingerprint: fingerprint(action),
+		game:        g,
+		expiresAt:   now.Add(idempotencyKeyTTL),
+	})
+	if len(results) > idempotencyKeysPerGame {
+		results = results[len(results)-idempotencyKeysPerGame:]
+	}
+
+	k.results[gameID] = results
+
+	// forget the games whose keys have all expired
+	for id, results := range k.results {
+		if now.After(results[len(results)-1].expiresAt) {
+			delete(k.results, id)
+		}
+	}
+}
+
+// matches whether the result belongs to the same move as the given one
+func (r idempotentResult) matches(moveType string, action Action) bool {
+	return r.moveType == moveType && r.fingerprint == fingerprint(action)
+}
+
+// fingerprint return the action without the fields that do not identify the move itself
+func fingerprint(action Action) Action {
+	action.IfVersion = nil
+	action.IdempotencyKey = ""
+
+	return action
+}
@@ -0,0 +1,159 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// Retention is how long games are kept in the storage. Zero durations keep the games forever.
+type Retention struct {
+	// TTL is how long unfinished games are kept since their last activity
+	TTL time.Duration
+	// Finished is how long finished games are kept since they finished
+	Finished time.Duration
+}
+
+// Enabled whether any game ever expires
+func (r Retention) Enabled() bool {
+	return r.TTL > 0 || r.Finished > 0
+}
+
+// LastActivityAt return the time of the last change of the game: its last move, its start or its creation.
+// It is zero for games stored before their creation time was recorded which have never been played.
+func (g *Game) LastActivityAt() int64 {
+	last := g.CreatedAt
+
+	if g.StartedAt > last {
+		last = g.StartedAt
+	}
+
+	if g.FinishedAt > last {
+		last = g.FinishedAt
+	}
+
+	if len(g.Moves) > 0 && g.Moves[len(g.Moves)-1].At > last {
+		last = g.Moves[len(g.Moves)-1].At
+	}
+
+	return last
+}
+
+// Expired whether the game is no longer kept at the given time. Games without any activity recorded never expire.
+func (g *Game) Expired(retention Retention, now time.Time) bool {
+	if g.IsFinished() {
+		finishedAt := g.FinishedAt
+		if finishedAt == 0 {
+			finishedAt = g.LastActivityAt()
+		}
+
+		return retention.Finished > 0 && finishedAt > 0 && now.Unix()-finishedAt >= int64(retention.Finished/time.Second)
+	}
+
+	lastActivity := g.LastActivityAt()
+
+	return retention.TTL > 0 && lastActivity > 0 && now.Unix()-lastActivity >= int64(retention.TTL/time.Second)
+}
+
+// Janitor periodically deletes the expired games through the service from the storage, which must be a Walker.
+// Storages which are Compacters are compacted once games have been deleted, to reclaim their space.
+type Janitor struct {
+	service   Service
+	storage   Storage
+	retention Retention
+	interval  time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewJanitor(service Service, storage Storage, retention Retention, interval time.Duration) *Janitor {
+	return &Janitor{
+		service:   service,
+		storage:   storage,
+		retention: retention,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start sweeps the storage right away and then every interval, in background, until Stop is called
+func (j *Janitor) Start() {
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			deleted, err := j.Sweep(time.Now())
+			if err != nil {
+				log.Printf("sweep expired games has failed: %s", err.Error())
+			} else if deleted > 0 {
+				log.Printf("%d expired games have been deleted", deleted)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background sweeps, waiting for the ongoing one to finish
+func (j *Janitor) Stop() {
+	close(j.stop)
+	<-j.done
+}
+
+// Sweep deletes the games expired at the given time and return how many have been deleted
+func (j *Janitor) Sweep(now time.Time) (int, error) {
+	walker, ok := j.storage.(Walker)
+	if !ok {
+		return 0, errors.New(apperrors.Unsupported, nil, "expiring games is not supported by the storage", "")
+	}
+
+	candidates := []string{}
+
+	err := walker.Walk(func(g Game) error {
+		if g.Expired(j.retention, now) {
+			candidates = append(candidates, g.ID)
+		}
+
+		return nil
+	})
+	if errors.Is(err, apperrors.Unsupported) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, errors.New(apperrors.Internal, err, "internal error", "walk games of the storage has failed")
+	}
+
+	deleted := 0
+
+	// the games are expired by the service, which checks them again under their lock
+	for _, id := range candidates {
+		expired, err := j.service.Expire(id, j.retention, now)
+		if err != nil {
+			return deleted, errors.Wrap(err, err.Error())
+		}
+
+		if expired {
+			deleted++
+		}
+	}
+
+	if compacter, ok := j.storage.(Compacter); ok && deleted > 0 {
+		err := compacter.Compact()
+		if err != nil {
+			return deleted, errors.New(apperrors.Internal, err, "internal error", "compact storage has failed")
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,44 @@
+package game
+
+import "sync"
+
+// gameLocks serializes the read-modify-write cycles on each game,
+// so concurrent moves on the same game never overwrite each other
+type gameLocks struct {
+	mutex sync.Mutex
+	locks map[string]*gameLock
+}
+
+type gameLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newGameLocks() *gameLocks {
+	return &gameLocks{locks: map[string]*gameLock{}}
+}
+
+// lock acquire the lock of the given game and return the function to release it
+func (l *gameLocks) lock(gameID string) func() {
+	l.mutex.Lock()
+	gl, ok := l.locks[gameID]
+	if !ok {
+		gl = &gameLock{}
+		l.locks[gameID] = gl
+	}
+	gl.refs++
+	l.mutex.Unlock()
+
+	gl.Lock()
+
+	return func() {
+		gl.Unlock()
+
+		l.mutex.Lock()
+		gl.refs--
+		if gl.refs == 0 {
+			delete(l.locks, gameID)
+		}
+		l.mutex.Unlock()
+	}
+}
@@ -1,11 +1,10 @@
 package game
 
 import (
-	"crypto/rand"
 	"fmt"
 	"github.com/matiasvarela/errors"
 	"github.com/matiasvarela/minesweeper/pkg/apperrors"
-	"io"
+	"github.com/matiasvarela/minesweeper/pkg/uuid"
 	"time"
 
 	"github.com/matiasvarela/minesweeper/internal/board"
@@ -13,17 +12,37 @@ import (
 
 type Service interface {
 	Get(id string) (Game, error)
-	Create(configuration Configuration) (Game, error)
-	PlaySquare(gameID string, pos board.SquarePosition) (Game, error)
-	MarkSquare(gameID string, pos board.SquarePosition) (Game, error)
+	Create(configuration Configuration, playerID string) (Game, error)
+	CreateSeeded(configuration Configuration, seed int64, playerID string, startsAt int64) (Game, error)
+	Join(gameID string, playerID string) (Game, error)
+	PlaySquare(gameID string, action Action) (Game, error)
+	MarkSquare(gameID string, action Action) (Game, error)
+	PlayMoves(gameID string, batch Batch) (BatchResult, error)
+	List(filter Filter) (GameList, error)
+	GetMoves(gameID string, after int64, limit int) (MoveList, error)
+	Delete(gameID string, playerID string) error
+	Expire(gameID string, retention Retention, now time.Time) (bool, error)
 }
 
+const (
+	// DefaultListLimit is the number of games listed when the filter has no limit
+	DefaultListLimit = 20
+	// MaxListLimit is the max number of games listed at once
+	MaxListLimit = 100
+)
+
+// maxUpdateAttempts is the number of times a change is applied on a game modified concurrently before giving up
+const maxUpdateAttempts = 3
+
 type service struct {
-	storage Storage
+	storage         Storage
+	hub             *Hub
+	locks           *gameLocks
+	idempotencyKeys *idempotencyKeys
 }
 
-func NewService(storage Storage) Service {
-	return &service{storage}
+func NewService(storage Storage, hub *Hub) Service {
+	return &service{storage, hub, newGameLocks(), newIdempotencyKeys()}
 }
 
 func (srv *service) Get(id string) (Game, error) {
@@ -39,15 +58,147 @@ func (srv *service) Get(id string) (Game, error) {
 	return game, nil
 }
 
-func (s *service) Create(configuration Configuration) (Game, error) {
-	id, err := newUUID()
+// Delete deletes the game on behalf of the given player, who must be its owner
+func (srv *service) Delete(gameID string, playerID string) error {
+	unlock := srv.locks.lock(gameID)
+	defer unlock()
+
+	game, err := srv.Get(gameID)
+	if err != nil {
+		return err
+	}
+
+	if game.PlayerID == "" || game.PlayerID != playerID {
+		return errors.New(apperrors.Forbidden, nil, "only the owner of the game is allowed to delete it", "")
+	}
+
+	err = srv.storage.Delete(gameID)
+	if err != nil {
+		if errors.Is(err, apperrors.NotFound) {
+			return errors.New(apperrors.NotFound, err, "game has not been found", "game not found in storage")
+		}
+
+		return errors.New(apperrors.Internal, err, "internal error", "delete game from storage has failed")
+	}
+
+	return nil
+}
+
+// Expire deletes the game if it has expired at the given time and return whether it has been deleted. The game is
+// read again under its lock, so that a move made since it was found expired keeps it.
+func (srv *service) Expire(gameID string, retention Retention, now time.Time) (bool, error) {
+	unlock := srv.locks.lock(gameID)
+	defer unlock()
+
+	game, err := srv.Get(gameID)
+	if errors.Is(err, apperrors.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !game.Expired(retention, now) {
+		return false, nil
+	}
+
+	err = srv.storage.Delete(gameID)
+	if errors.Is(err, apperrors.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.New(apperrors.Internal, err, "internal error", "delete expired game has failed")
+	}
+
+	return true, nil
+}
+
+// List return a page of the games matching the filter, which is only supported when the storage is a Lister.
+// The page holds the limit it has been listed with, which is DefaultListLimit when the filter has none.
+func (srv *service) List(filter Filter) (GameList, error) {
+	lister, ok := srv.storage.(Lister)
+	if !ok {
+		return GameList{}, errors.New(apperrors.Unsupported, nil, "listing games is not supported by the storage", "")
+	}
+
+	if filter.Limit < 0 || filter.Limit > MaxListLimit || filter.Offset < 0 {
+		return GameList{}, errors.New(apperrors.InvalidInput, nil, fmt.Sprintf("the limit must be between 0 and %d and the offset cannot be negative", MaxListLimit), "")
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultListLimit
+	}
+
+	games, err := lister.List(filter)
+	if errors.Is(err, apperrors.Unsupported) {
+		return GameList{}, err
+	}
+	if err != nil {
+		return GameList{}, errors.New(apperrors.Internal, err, "internal error", "list games from storage has failed")
+	}
+
+	return GameList{Games: games, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+// GetMoves return a page of the move log of the game: up to limit moves following the one with the given id.
+// The log is left out of the games replied by the api, since it grows with every move.
+func (srv *service) GetMoves(gameID string, after int64, limit int) (MoveList, error) {
+	if limit < 0 || limit > MaxListLimit || after < 0 {
+		return MoveList{}, errors.New(apperrors.InvalidInput, nil, fmt.Sprintf("the limit must be between 0 and %d and the move id cannot be negative", MaxListLimit), "")
+	}
+
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+
+	game, err := srv.Get(gameID)
+	if err != nil {
+		return MoveList{}, err
+	}
+
+	// the moves are logged in order with consecutive ids starting at 1
+	moves := []Move{}
+	if after < int64(len(game.Moves)) {
+		moves = game.Moves[after:]
+	}
+
+	if len(moves) > limit {
+		moves = moves[:limit]
+	}
+
+	return MoveList{Moves: moves, After: after, Limit: limit}, nil
+}
+
+// Create creates a new game owned by the given player, or an anonymous game when no player is given.
+// Cooperative and versus games require a player, who becomes its first participant.
+func (s *service) Create(configuration Configuration, playerID string) (Game, error) {
+	if (configuration.Mode == MODE_COOP || configuration.Mode == MODE_VERSUS) && playerID == "" {
+		return Game{}, errors.New(apperrors.Unauthorized, nil, "a player is required to create a multiplayer game", "")
+	}
+
+	id, err := uuid.New()
 	if err != nil {
 		return Game{}, errors.New(apperrors.Internal, err, "internal error", "generate new uuid has fail")
 	}
 
 	g := Game{
-		ID:    id,
-		Board: board.NewBoard(configuration.Rows, configuration.Columns, configuration.Bombs),
+		ID:        id,
+		Mode:      configuration.Mode,
+		PlayerID:  playerID,
+		Board:     board.NewBoard(configuration.Rows, configuration.Columns, configuration.Bombs),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	if g.Mode == "" {
+		g.Mode = MODE_CLASSIC
+	}
+
+	if g.Mode == MODE_COOP || g.Mode == MODE_VERSUS {
+		g.Players = []Participant{{ID: playerID}}
+	}
+
+	if g.Mode == MODE_VERSUS {
+		g.Status = VERSUS_STATUS_WAITING
 	}
 
 	err = s.storage.Create(g)
@@ -58,70 +209,209 @@ func (s *service) Create(configuration Configuration) (Game, error) {
 	return g, nil
 }
 
-func (s *service) PlaySquare(gameID string, pos board.SquarePosition) (Game, error) {
-	game, err := s.storage.GetByID(gameID)
+// CreateSeeded creates a game for the given player whose board is generated from the given seed,
+// so every game created with the same seed and configuration has the same bombs layout.
+// The board comes with a safe square already revealed, hence the clock starts right away,
+// or at the given time when it is in the future. The game cannot be played before it starts.
+func (s *service) CreateSeeded(configuration Configuration, seed int64, playerID string, startsAt int64) (Game, error) {
+	id, err := uuid.New()
 	if err != nil {
-		if errors.Is(err, apperrors.NotFound) {
-			return Game{}, errors.New(apperrors.NotFound, err, "game has not been found", "game not found in storage")
-		}
+		return Game{}, errors.New(apperrors.Internal, err, "internal error", "generate new uuid has fail")
+	}
 
-		return Game{}, errors.New(apperrors.Internal, err, "internal error", "get game from storage has failed")
+	g := Game{
+		ID:        id,
+		PlayerID:  playerID,
+		Mode:      MODE_CLASSIC,
+		Board:     board.NewSeededBoard(configuration.Rows, configuration.Columns, configuration.Bombs, seed),
+		CreatedAt: time.Now().Unix(),
+		StartedAt: time.Now().Unix(),
 	}
 
-	if !*game.Board.FirstMoveDone {
-		game.StartedAt = time.Now().Unix()
+	if startsAt > g.StartedAt {
+		g.StartedAt = startsAt
 	}
 
-	err = game.Board.PlaySquare(pos)
-	if err != nil {
-		return Game{}, errors.Wrap(err, err.Error())
+	if g.IsFinished() {
+		g.FinishedAt = g.StartedAt
 	}
 
-	err = s.storage.Update(game)
+	err = s.storage.Create(g)
 	if err != nil {
-		return Game{}, errors.New(apperrors.Internal, err, "internal error", "update game into storage has failed")
+		return Game{}, errors.New(apperrors.Internal, err, "internal error", "create game into storage has failed")
 	}
 
-	return game, nil
+	return g, nil
 }
 
-func (s *service) MarkSquare(gameID string, pos board.SquarePosition) (Game, error) {
-	game, err := s.storage.GetByID(gameID)
+// Join adds the given player as a participant of a cooperative or versus game. Joining twice has no effect.
+func (s *service) Join(gameID string, playerID string) (Game, error) {
+	game, err := s.Get(gameID)
 	if err != nil {
-		if errors.Is(err, apperrors.NotFound) {
-			return Game{}, errors.New(apperrors.NotFound, err, "game has not been found", "game not found in storage")
+		return Game{}, err
+	}
+
+	if game.participant(playerID) != nil {
+		return game, nil
+	}
+
+	game, err = s.update(gameID, nil, func(game *Game) error {
+		if game.Mode != MODE_COOP && game.Mode != MODE_VERSUS {
+			return errors.New(apperrors.InvalidInput, nil, "only cooperative and versus games can be joined", "")
 		}
 
-		return Game{}, errors.New(apperrors.Internal, err, "internal error", "get game from storage has failed")
+		if game.participant(playerID) != nil {
+			return nil
+		}
+
+		if game.IsFinished() {
+			return errors.New(apperrors.InvalidInput, nil, "cannot join a finished game", "")
+		}
+
+		if game.Mode == MODE_VERSUS {
+			return game.joinVersus(playerID)
+		}
+
+		game.Players = append(game.Players, Participant{ID: playerID})
+
+		return nil
+	})
+	if err != nil {
+		return Game{}, err
 	}
 
-	err = game.Board.MarkSquare(pos)
+	s.hub.publishJoin(game, playerID)
+
+	return game, nil
+}
+
+func (s *service) PlaySquare(gameID string, action Action) (Game, error) {
+	return s.perform(gameID, MOVE_PLAY_SQUARE, action, func(game *Game) (Move, error) {
+		return game.playSquare(action)
+	})
+}
+
+// MarkSquare toggles the mark of the square, or sets it as requested by the action
+func (s *service) MarkSquare(gameID string, action Action) (Game, error) {
+	return s.perform(gameID, MOVE_MARK_SQUARE, action, func(game *Game) (Move, error) {
+		return game.markSquare(action)
+	})
+}
+
+// PlayMoves applies the moves of the batch in order as a single change of the game. Either every move is
+// applied or none of them is: the first invalid move rejects the whole batch. Moves after the one that
+// finishes the game are skipped.
+func (s *service) PlayMoves(gameID string, batch Batch) (BatchResult, error) {
+	result := BatchResult{}
+
+	game, err := s.update(gameID, batch.IfVersion, func(game *Game) error {
+		result = BatchResult{Moves: []Move{}}
+
+		for i, m := range batch.Moves {
+			if game.IsFinished() {
+				result.Skipped = len(batch.Moves) - i
+				break
+			}
+
+			move, err := game.move(m.Type, Action{PlayerID: batch.PlayerID, Position: m.Position, Mark: m.Mark})
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("move %d: %s", i, err.Error()))
+			}
+
+			result.Moves = append(result.Moves, move)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return Game{}, errors.Wrap(err, err.Error())
+		return BatchResult{}, err
+	}
+
+	for _, move := range result.Moves {
+		s.hub.publishMove(game, move)
+	}
+
+	result.Game = game
+
+	return result, nil
+}
+
+// perform applies the given move on the game and notifies it to the game subscribers. When the action carries
+// an idempotency key already used on the game, the move is not applied again and the original outcome is returned.
+func (s *service) perform(gameID string, moveType string, action Action, move func(game *Game) (Move, error)) (Game, error) {
+	unlock := s.locks.lock(gameID)
+	defer unlock()
+
+	if action.IdempotencyKey != "" {
+		if result, ok := s.idempotencyKeys.get(gameID, action.IdempotencyKey); ok {
+			if !result.matches(moveType, action) {
+				return Game{}, errors.New(apperrors.InvalidInput, nil, "the idempotency key has already been used for another move", "")
+			}
+
+			return result.game, nil
+		}
 	}
 
-	err = s.storage.Update(game)
+	var m Move
+
+	game, err := s.apply(gameID, action.IfVersion, func(game *Game) error {
+		var err error
+		m, err = move(game)
+		return err
+	})
 	if err != nil {
-		return Game{}, errors.New(apperrors.Internal, err, "internal error", "update game into storage has failed")
+		return Game{}, err
+	}
+
+	if action.IdempotencyKey != "" {
+		s.idempotencyKeys.put(gameID, action.IdempotencyKey, moveType, action, game)
 	}
 
+	s.hub.publishMove(game, m)
+
 	return game, nil
 }
 
-// Helper
+// update applies the given change to the game and persists it. Moves on the same game are serialized within
+// this process, while changes made concurrently by other processes are detected through the game version:
+// the change is then applied again on the fresh game, up to maxUpdateAttempts times.
+// When ifVersion is set, the change is only applied if the game is at that version.
+func (s *service) update(gameID string, ifVersion *int64, change func(game *Game) error) (Game, error) {
+	unlock := s.locks.lock(gameID)
+	defer unlock()
 
-func newUUID() (string, error) {
-	uuid := make([]byte, 16)
+	return s.apply(gameID, ifVersion, change)
+}
 
-	n, err := io.ReadFull(rand.Reader, uuid)
-	if n != len(uuid) || err != nil {
-		return "", err
-	}
+// apply is the same as update for callers already holding the lock of the game
+func (s *service) apply(gameID string, ifVersion *int64, change func(game *Game) error) (Game, error) {
+	for attempt := 1; ; attempt++ {
+		game, err := s.Get(gameID)
+		if err != nil {
+			return Game{}, err
+		}
+
+		if ifVersion != nil && *ifVersion != game.Version {
+			return Game{}, errors.New(apperrors.PreconditionFailed, nil, "the game has been modified", "game version does not match the expected one")
+		}
+
+		err = change(&game)
+		if err != nil {
+			return Game{}, errors.Wrap(err, err.Error())
+		}
+
+		err = s.storage.Update(game)
+		if err == nil {
+			game.Version++
+			return game, nil
+		}
 
-	// variant bits; see section 4.1.1
-	uuid[8] = uuid[8]&^0xc0 | 0x80
-	// version 4 (pseudo-random); see section 4.1.3
-	uuid[6] = uuid[6]&^0xf0 | 0x40
+		if !errors.Is(err, apperrors.Conflict) {
+			return Game{}, errors.New(apperrors.Internal, err, "internal error", "update game into storage has failed")
+		}
 
-	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+		if attempt == maxUpdateAttempts {
+			return Game{}, errors.New(apperrors.Conflict, err, "the game is being modified concurrently, try again", "update game has failed after several attempts")
+		}
+	}
 }
@@ -2,6 +2,49 @@ package game
 
 type Storage interface {
 	Create(g Game) error
+	// Update replaces the stored game, which must be at the same version as the given one, and increments its version.
+	// It fails with an apperrors.Conflict error when the stored game is at another version.
 	Update(g Game) error
 	GetByID(id string) (Game, error)
+	// Delete removes the stored game. It fails with an apperrors.NotFound error when the game is not stored.
+	Delete(id string) error
+}
+
+// Filter restricts the games to list, its empty fields match every game
+type Filter struct {
+	// PlayerID matches the games owned by the player or the player takes part in
+	PlayerID string
+	Mode     string
+	// Status matches either the status of versus games or the status of the board
+	Status string
+	Limit  int
+	Offset int
+}
+
+// Lister is implemented by the storages able to query their games. Listing games is only available
+// when the storage is a Lister.
+type Lister interface {
+	// List return the games matching the filter, the most recently created first
+	List(filter Filter) ([]Game, error)
+}
+
+// Walker is implemented by the storages able to go through all their games. Expiring games is only available
+// when the storage is a Walker.
+type Walker interface {
+	// Walk calls fn with every stored game, in no particular order, until fn returns an error. The storage
+	// may be modified by fn.
+	Walk(fn func(g Game) error) error
+}
+
+// Historian is implemented by the storages keeping the past versions of their games. Reading a game as it was
+// at a past version is only available when the storage is a Historian.
+type Historian interface {
+	// GetAt return the game as it was at the given version. It fails with an apperrors.NotFound error when the
+	// game is not stored or has not reached the version.
+	GetAt(id string, version int64) (Game, error)
+}
+
+// Compacter is implemented by the storages that must be compacted to reclaim the space of deleted games
+type Compacter interface {
+	Compact() error
 }
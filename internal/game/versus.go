@@ -0,0 +1,103 @@
+package game
+
+import (
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// Versus games are played by two players who take turns on the same board. Clicking a bomb claims it:
+// the player scores a point and keeps the turn. Clicking any other square reveals it and passes the turn.
+// The first player to claim the majority of the bombs wins.
+
+const (
+	VERSUS_STATUS_WAITING  string = "waiting_for_opponent"
+	VERSUS_STATUS_PLAYING  string = "playing"
+	VERSUS_STATUS_FINISHED string = "finished"
+
+	VersusPlayers = 2
+)
+
+// joinVersus adds the opponent and starts the game, the player who created it moves first
+func (g *Game) joinVersus(playerID string) error {
+	if len(g.Players) >= VersusPlayers {
+		return errors.New(apperrors.InvalidInput, nil, "the game already has an opponent", "")
+	}
+
+	g.Players = append(g.Players, Participant{ID: playerID})
+	g.Status = VERSUS_STATUS_PLAYING
+	g.Turn = g.Players[0].ID
+
+	return nil
+}
+
+// playVersus plays the square on behalf of the player who has the turn and return the positions of the squares it changed
+func (g *Game) playVersus(playerID string, pos board.SquarePosition) ([]board.SquarePosition, error) {
+	switch g.Status {
+	case VERSUS_STATUS_WAITING:
+		return nil, errors.New(apperrors.InvalidInput, nil, "the game is waiting for an opponent", "")
+	case VERSUS_STATUS_FINISHED:
+		return nil, errors.New(apperrors.InvalidInput, nil, "cannot play a square on a finished game", "")
+	}
+
+	if g.Turn != playerID {
+		return nil, errors.New(apperrors.OutOfTurn, nil, "it is not the turn of the player", "")
+	}
+
+	if !g.Board.VerifyRange(pos) {
+		return nil, errors.New(apperrors.InvalidInput, nil, "invalid square", "")
+	}
+
+	if g.Board.Get(pos).Revealed {
+		return []board.SquarePosition{}, nil
+	}
+
+	// bombs are placed on the first move, which is never a bomb
+	if !*g.Board.FirstMoveDone || !g.Board.Is(pos, board.BOMB) {
+		touched, err := g.Board.PlaySquare(pos)
+		if err != nil {
+			return nil, err
+		}
+
+		g.Turn = g.opponent(playerID)
+
+		return touched, nil
+	}
+
+	err := g.Board.RevealBomb(pos)
+	if err != nil {
+		return nil, err
+	}
+
+	p := g.participant(playerID)
+	p.Score++
+
+	claimed := 0
+	for _, p := range g.Players {
+		claimed += p.Score
+	}
+
+	if p.Score > g.Board.BombsNumber/2 {
+		g.Status = VERSUS_STATUS_FINISHED
+		g.Winner = playerID
+	} else if claimed == g.Board.BombsNumber {
+		// every bomb has been claimed and none of the players has the majority: it is a draw
+		g.Status = VERSUS_STATUS_FINISHED
+	}
+
+	if g.Status == VERSUS_STATUS_FINISHED {
+		g.Turn = ""
+	}
+
+	return []board.SquarePosition{pos}, nil
+}
+
+func (g *Game) opponent(playerID string) string {
+	for _, p := range g.Players {
+		if p.ID != playerID {
+			return p.ID
+		}
+	}
+
+	return playerID
+}
@@ -0,0 +1,188 @@
+package gql
+
+import (
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+)
+
+// The views are the only values exposed through the schema. They are built from obfuscated games,
+// so the hidden bombs cannot be queried whatever the query is.
+
+type gameView struct {
+	ID          string            `graphql:"id"`
+	Mode        string            `graphql:"mode"`
+	PlayerID    string            `graphql:"playerId"`
+	Status      string            `graphql:"status"`
+	Turn        string            `graphql:"turn"`
+	Winner      string            `graphql:"winner"`
+	StartedAt   int64             `graphql:"startedAt"`
+	FinishedAt  int64             `graphql:"finishedAt"`
+	ElapsedTime int64             `graphql:"elapsedTime"`
+	Version     int64             `graphql:"version"`
+	Board       boardView         `graphql:"board"`
+	Players     []participantView `graphql:"players"`
+	// moves are the game log, whose views are only built for the page of moves queried
+	moves []game.Move
+}
+
+type boardView struct {
+	Rows                 int          `graphql:"rows"`
+	Columns              int          `graphql:"columns"`
+	Status               string       `graphql:"status"`
+	RevealedSquaresCount int          `graphql:"revealedSquaresCount"`
+	Squares              []squareView `graphql:"squares"`
+}
+
+type squareView struct {
+	Row      int  `graphql:"row"`
+	Column   int  `graphql:"column"`
+	Type     int  `graphql:"type"`
+	Revealed bool `graphql:"revealed"`
+	Marked   bool `graphql:"marked"`
+	// Count is the number of bombs adjacent to a revealed square, nil for unrevealed squares
+	Count *int `graphql:"count"`
+}
+
+type participantView struct {
+	ID                   string `graphql:"id"`
+	RevealedSquaresCount int    `graphql:"revealedSquaresCount"`
+	MarkedSquaresCount   int    `graphql:"markedSquaresCount"`
+	Score                int    `graphql:"score"`
+}
+
+type moveView struct {
+	ID                   int64        `graphql:"id"`
+	Type                 string       `graphql:"type"`
+	PlayerID             string       `graphql:"playerId"`
+	Row                  int          `graphql:"row"`
+	Column               int          `graphql:"column"`
+	Squares              []squareView `graphql:"squares"`
+	Status               string       `graphql:"status"`
+	RevealedSquaresCount int          `graphql:"revealedSquaresCount"`
+	At                   int64        `graphql:"at"`
+}
+
+type leaderboardView struct {
+	Date    string       `graphql:"date"`
+	Results []resultView `graphql:"results"`
+}
+
+type resultView struct {
+	Rank                 int    `graphql:"rank"`
+	PlayerID             string `graphql:"playerId"`
+	GameID               string `graphql:"gameId"`
+	Status               string `graphql:"status"`
+	ElapsedTime          int64  `graphql:"elapsedTime"`
+	RevealedSquaresCount int    `graphql:"revealedSquaresCount"`
+}
+
+// newGameView return the view of the game, whose board is obfuscated before anything is taken from it
+func newGameView(g game.Game) gameView {
+	counts := revealedCounts(g.Board)
+
+	g.UpdateElapsedTime()
+	g.Board.Obfuscate()
+
+	view := gameView{
+		ID:          g.ID,
+		Mode:        g.Mode,
+		PlayerID:    g.PlayerID,
+		Status:      g.Status,
+		Turn:        g.Turn,
+		Winner:      g.Winner,
+		StartedAt:   g.StartedAt,
+		FinishedAt:  g.FinishedAt,
+		ElapsedTime: g.ElapsedTime,
+		Version:     g.Version,
+		Board: boardView{
+			Rows:                 len(g.Board.Squares),
+			Status:               g.Board.Status,
+			RevealedSquaresCount: g.Board.RevealedSquaresCount,
+			Squares:              []squareView{},
+		},
+		Players: []participantView{},
+		moves:   g.Moves,
+	}
+
+	for i := range g.Board.Squares {
+		view.Board.Columns = len(g.Board.Squares[i])
+
+		for j, square := range g.Board.Squares[i] {
+			s := squareView{Row: i, Column: j, Type: board.EMPTY, Revealed: square.Revealed, Marked: square.Marked}
+
+			if square.Revealed {
+				s.Type = square.Type
+				s.Count = counts[board.SquarePosition{Row: i, Column: j}]
+			}
+
+			view.Board.Squares = append(view.Board.Squares, s)
+		}
+	}
+
+	for _, p := range g.Players {
+		view.Players = append(view.Players, participantView(p))
+	}
+
+	return view
+}
+
+// newMoveView return the view of the move, which only holds the squares visible once it was made
+func newMoveView(m game.Move) moveView {
+	move := moveView{
+		ID:                   m.ID,
+		Type:                 m.Type,
+		PlayerID:             m.PlayerID,
+		Row:                  m.Position.Row,
+		Column:               m.Position.Column,
+		Squares:              []squareView{},
+		Status:               m.Status,
+		RevealedSquaresCount: m.RevealedSquaresCount,
+		At:                   m.At,
+	}
+
+	for _, change := range m.Squares {
+		s := squareView{Row: change.Row, Column: change.Column, Type: board.EMPTY, Revealed: change.Revealed, Marked: change.Marked}
+
+		if change.Revealed {
+			s.Type = change.Type
+
+			if change.Type != board.BOMB {
+				count := change.Count
+				s.Count = &count
+			}
+		}
+
+		move.Squares = append(move.Squares, s)
+	}
+
+	return move
+}
+
+// revealedCounts return the number of adjacent bombs of each revealed square that is not a bomb
+func revealedCounts(b board.Board) map[board.SquarePosition]*int {
+	counts := map[board.SquarePosition]*int{}
+
+	for i := range b.Squares {
+		for j, square := range b.Squares[i] {
+			pos := board.SquarePosition{Row: i, Column: j}
+
+			if square.Revealed && square.Type != board.BOMB {
+				count := b.CountNeighborBombs(pos)
+				counts[pos] = &count
+			}
+		}
+	}
+
+	return counts
+}
+
+func newLeaderboardView(leaderboard daily.Leaderboard) leaderboardView {
+	view := leaderboardView{Date: leaderboard.Date, Results: []resultView{}}
+
+	for _, r := range leaderboard.Results {
+		view.Results = append(view.Results, resultView(r))
+	}
+
+	return view
+}
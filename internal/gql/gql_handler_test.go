@@ -0,0 +1,318 @@
+package gql_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/gql"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	router           *gin.Engine
+	gameService      game.Service
+	dailyService     daily.Service
+	fakeGameStorage  *fakesto.GameStorage
+	fakeDailyStorage *fakesto.DailyStorage
+)
+
+func init() {
+	fakeGameStorage = fakesto.NewGameStorage()
+	fakeDailyStorage = fakesto.NewDailyStorage()
+	gameService = game.NewService(fakeGameStorage, game.NewHub())
+	dailyService = daily.NewService(gameService, fakeDailyStorage, "secret")
+
+	schema, err := gql.NewSchema(gameService, dailyService)
+	if err != nil {
+		panic(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	router = gin.New()
+	router.POST("/graphql", gql.NewHttpHandler(schema).Query)
+}
+
+func newOnGoingBoard() board.Board {
+	_true := true
+
+	return board.Board{
+		Status: board.STATUS_ON_GOING,
+		Squares: [][]board.Square{
+			{{Type: board.EMPTY}, {Type: board.EMPTY}, {Type: board.EMPTY}},
+			{{Type: board.EMPTY}, {Type: board.BOMB}, {Type: board.EMPTY}},
+			{{Type: board.EMPTY}, {Type: board.EMPTY}, {Type: board.BOMB}},
+		},
+		BombsPositions: &[]board.SquarePosition{{Row: 1, Column: 1}, {Row: 2, Column: 2}},
+		FirstMoveDone:  &_true,
+		BombsNumber:    2,
+	}
+}
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestQuery(t *testing.T) {
+	type input struct {
+		playerID string
+		query    string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func()
+		verify func(t *testing.T, in input, status int, res response)
+	}{
+		{
+			name:   "game with every square",
+			should: "never expose an unrevealed bomb",
+			input:  input{query: `{ game(id: "123") { id board { rows columns status squares { row column type revealed count } } } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Equal(t, 200, status)
+				assert.Empty(t, res.Errors)
+
+				b := res.Data["game"].(map[string]interface{})["board"].(map[string]interface{})
+				assert.Equal(t, float64(3), b["rows"])
+				assert.Equal(t, float64(3), b["columns"])
+				assert.Len(t, b["squares"], 9)
+
+				for _, s := range b["squares"].([]interface{}) {
+					assert.Equal(t, "EMPTY", s.(map[string]interface{})["type"])
+					assert.Nil(t, s.(map[string]interface{})["count"])
+				}
+			},
+		},
+		{
+			name:   "lost game",
+			should: "expose the bombs revealed by the loss",
+			input:  input{query: `{ squares(gameId: "123", revealed: true) { row column type } }`},
+			mock: func() {
+				gameService.PlaySquare("123", game.Action{Position: board.SquarePosition{Row: 1, Column: 1}})
+			},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+				assert.ElementsMatch(t, []interface{}{
+					map[string]interface{}{"row": float64(1), "column": float64(1), "type": "BOMB"},
+					map[string]interface{}{"row": float64(2), "column": float64(2), "type": "BOMB"},
+				}, res.Data["squares"])
+			},
+		},
+		{
+			name:   "move history",
+			should: "return the moves after the given one along with the revealed squares",
+			input:  input{query: `{ games(ids: ["123"]) { version moves(after: 1) { id type squares { row column revealed count } } } }`},
+			mock: func() {
+				gameService.MarkSquare("123", game.Action{Position: board.SquarePosition{Row: 1, Column: 1}})
+				gameService.PlaySquare("123", game.Action{Position: board.SquarePosition{Row: 0, Column: 0}})
+			},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+
+				g := res.Data["games"].([]interface{})[0].(map[string]interface{})
+				assert.Equal(t, float64(2), g["version"])
+				assert.Equal(t, []interface{}{
+					map[string]interface{}{"id": float64(2), "type": game.MOVE_PLAY_SQUARE, "squares": []interface{}{
+						map[string]interface{}{"row": float64(0), "column": float64(0), "revealed": true, "count": float64(1)},
+					}},
+				}, g["moves"])
+			},
+		},
+		{
+			name:   "moves without limit",
+			should: "return the first page of the moves instead of the whole log",
+			input:  input{query: `{ game(id: "123") { moves { id } } }`},
+			mock: func() {
+				for i := 0; i <= game.DefaultListLimit; i++ {
+					gameService.MarkSquare("123", game.Action{Position: board.SquarePosition{Row: 1, Column: 1}})
+				}
+			},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+
+				moves := res.Data["game"].(map[string]interface{})["moves"].([]interface{})
+				assert.Len(t, moves, game.DefaultListLimit)
+				assert.Equal(t, map[string]interface{}{"id": float64(game.DefaultListLimit)}, moves[len(moves)-1])
+			},
+		},
+		{
+			name:   "move page",
+			should: "return the moves after the given one up to the limit",
+			input:  input{query: `{ game(id: "123") { moves(after: 1, limit: 2) { id } } }`},
+			mock: func() {
+				for i := 0; i < 4; i++ {
+					gameService.MarkSquare("123", game.Action{Position: board.SquarePosition{Row: 1, Column: 1}})
+				}
+			},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+				assert.Equal(t, []interface{}{
+					map[string]interface{}{"id": float64(2)},
+					map[string]interface{}{"id": float64(3)},
+				}, res.Data["game"].(map[string]interface{})["moves"])
+			},
+		},
+		{
+			name:   "too many moves",
+			should: "return an invalid input error",
+			input:  input{query: fmt.Sprintf(`{ game(id: "123") { moves(limit: %d) { id } } }`, game.MaxListLimit+1)},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Len(t, res.Errors, 1)
+				assert.Equal(t, "invalid_input", res.Errors[0].Extensions["code"])
+			},
+		},
+		{
+			name:   "play square mutation",
+			should: "play the square on behalf of the player",
+			input:  input{query: `mutation { playSquare(gameId: "123", row: 0, column: 0) { version board { revealedSquaresCount } } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+				assert.Equal(t, map[string]interface{}{"version": float64(1), "board": map[string]interface{}{"revealedSquaresCount": float64(1)}}, res.Data["playSquare"])
+			},
+		},
+		{
+			name:   "mark square mutation",
+			should: "set the requested mark",
+			input:  input{query: `mutation { markSquare(gameId: "123", row: 1, column: 1, mark: FLAG) { board { squares(marked: true) { row column type } } } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+
+				b := res.Data["markSquare"].(map[string]interface{})["board"].(map[string]interface{})
+				assert.Equal(t, []interface{}{map[string]interface{}{"row": float64(1), "column": float64(1), "type": "EMPTY"}}, b["squares"])
+			},
+		},
+		{
+			name:   "create game mutation",
+			should: "create a game owned by the player",
+			input:  input{playerID: "alice", query: `mutation { createGame(rows: 4, columns: 4, bombs: 3, mode: "coop") { playerId mode players { id } } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+				assert.Equal(t, map[string]interface{}{
+					"playerId": "alice",
+					"mode":     game.MODE_COOP,
+					"players":  []interface{}{map[string]interface{}{"id": "alice"}},
+				}, res.Data["createGame"])
+			},
+		},
+		{
+			name:   "invalid configuration",
+			should: "return an invalid input error",
+			input:  input{query: `mutation { createGame(rows: 4, columns: 4, bombs: 30) { id } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Equal(t, 200, status)
+				assert.Len(t, res.Errors, 1)
+				assert.Equal(t, "invalid_input", res.Errors[0].Extensions["code"])
+			},
+		},
+		{
+			name:   "missing game",
+			should: "return a not found error",
+			input:  input{query: `{ game(id: "456") { id } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Len(t, res.Errors, 1)
+				assert.Equal(t, "not_found", res.Errors[0].Extensions["code"])
+			},
+		},
+		{
+			name:   "too many games",
+			should: "return an invalid input error instead of querying every game",
+			input:  input{query: `{ games(ids: [` + strings.Repeat(`"123", `, game.MaxListLimit+1) + `]) { id } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Len(t, res.Errors, 1)
+				assert.Equal(t, "invalid_input", res.Errors[0].Extensions["code"])
+				assert.Nil(t, res.Data["games"])
+			},
+		},
+		{
+			name:   "hidden bomb field",
+			should: "reject the query since bombs positions are not part of the schema",
+			input:  input{query: `{ game(id: "123") { board { bombsPositions { row } } } }`},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.NotEmpty(t, res.Errors)
+				assert.Nil(t, res.Data)
+			},
+		},
+		{
+			name:   "daily leaderboard",
+			should: "return the ranking without the games of the players, since the day is not over",
+			input:  input{query: `{ dailyLeaderboard { results { rank playerId gameId game { id board { rows } } } } }`},
+			mock: func() {
+				g, _ := dailyService.Get("alice")
+				g.Board.Status = board.STATUS_LOST
+				fakeGameStorage.Update(g)
+			},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Empty(t, res.Errors)
+
+				results := res.Data["dailyLeaderboard"].(map[string]interface{})["results"].([]interface{})
+				assert.Len(t, results, 1)
+
+				result := results[0].(map[string]interface{})
+				assert.Equal(t, "alice", result["playerId"])
+				assert.Nil(t, result["gameId"])
+				assert.Nil(t, result["game"])
+			},
+		},
+		{
+			name:   "empty query",
+			should: "return a bad request",
+			input:  input{query: ""},
+			mock:   func() {},
+			verify: func(t *testing.T, in input, status int, res response) {
+				assert.Equal(t, 400, status)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGameStorage.CleanDB()
+			fakeGameStorage.CleanErrors()
+			fakeDailyStorage.CleanDB()
+			fakeDailyStorage.CleanErrors()
+			fakeGameStorage.Create(game.Game{ID: "123", Board: newOnGoingBoard()})
+
+			tt.mock()
+
+			body, _ := json.Marshal(gql.Request{Query: tt.input.query})
+
+			req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+			if tt.input.playerID != "" {
+				req.Header.Set(player.Header, tt.input.playerID)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			res := response{}
+			json.Unmarshal(rec.Body.Bytes(), &res)
+
+			tt.verify(t, tt.input, rec.Code, res)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+type HttpHandler interface {
+	Query(*gin.Context)
+}
+
+type httpHandler struct {
+	schema graphql.Schema
+}
+
+// Request is a graphql request as sent by graphql clients
+type Request struct {
+	Query         string                 `json:"query" validate:"required"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+func NewHttpHandler(schema graphql.Schema) HttpHandler {
+	return &httpHandler{schema}
+}
+
+// Query executes the graphql request. Errors raised while resolving are part of the graphql result,
+// so only malformed requests are replied with an error status.
+func (h *httpHandler) Query(c *gin.Context) {
+	playerID, err := player.OptionalFromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	request := Request{}
+
+	err = c.BindJSON(&request)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "bind json has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	err = validate.Struct(request)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "validations has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        context.WithValue(c.Request.Context(), playerKey{}, playerID),
+	})
+
+	c.JSON(200, result)
+}
@@ -0,0 +1,432 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"gopkg.in/go-playground/validator.v8"
+)
+
+var (
+	validate *validator.Validate
+)
+
+func init() {
+	validate = validator.New(&validator.Config{TagName: "validate"})
+	validate.RegisterStructValidation(game.ConfigurationStructValidation, game.Configuration{})
+}
+
+// playerKey is the context key of the id of the player performing the request
+type playerKey struct{}
+
+// codedError exposes the code of an application error as an extension of the graphql error
+type codedError struct {
+	error
+}
+
+func (e codedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": errors.Code(e.error)}
+}
+
+var (
+	squareTypeEnum = graphql.NewEnum(graphql.EnumConfig{
+		Name: "SquareType",
+		Values: graphql.EnumValueConfigMap{
+			"EMPTY": &graphql.EnumValueConfig{Value: board.EMPTY},
+			"BOMB":  &graphql.EnumValueConfig{Value: board.BOMB},
+		},
+	})
+
+	markEnum = graphql.NewEnum(graphql.EnumConfig{
+		Name: "Mark",
+		Values: graphql.EnumValueConfigMap{
+			"TOGGLE": &graphql.EnumValueConfig{Value: ""},
+			"FLAG":   &graphql.EnumValueConfig{Value: game.MARK_FLAG},
+			"NONE":   &graphql.EnumValueConfig{Value: game.MARK_NONE},
+		},
+	})
+
+	squareType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Square",
+		Fields: graphql.Fields{
+			"row":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"column":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"type":     &graphql.Field{Type: graphql.NewNonNull(squareTypeEnum)},
+			"revealed": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"marked":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"count":    &graphql.Field{Type: graphql.Int, Description: "Number of bombs adjacent to a revealed square"},
+		},
+	})
+
+	squaresArgs = graphql.FieldConfigArgument{
+		"revealed": &graphql.ArgumentConfig{Type: graphql.Boolean},
+		"marked":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+	}
+
+	boardType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Board",
+		Fields: graphql.Fields{
+			"rows":                 &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"columns":              &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"status":               &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"revealedSquaresCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"squares": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(squareType))),
+				Description: "Squares of the board in row-major order, optionally filtered by their state",
+				Args:        squaresArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return filterSquares(p.Source.(boardView).Squares, p.Args), nil
+				},
+			},
+		},
+	})
+
+	participantType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Participant",
+		Fields: graphql.Fields{
+			"id":                   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"revealedSquaresCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"markedSquaresCount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"score":                &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	moveType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Move",
+		Fields: graphql.Fields{
+			"id":                   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"type":                 &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"playerId":             &graphql.Field{Type: graphql.String},
+			"row":                  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"column":               &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"squares":              &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(squareType)))},
+			"status":               &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"revealedSquaresCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"at":                   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	gameType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Game",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"mode":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"playerId":    &graphql.Field{Type: graphql.String},
+			"status":      &graphql.Field{Type: graphql.String},
+			"turn":        &graphql.Field{Type: graphql.String},
+			"winner":      &graphql.Field{Type: graphql.String},
+			"startedAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"finishedAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"elapsedTime": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"version":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"board":       &graphql.Field{Type: graphql.NewNonNull(boardType)},
+			"players":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(participantType)))},
+			"moves": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(moveType))),
+				Description: fmt.Sprintf("Page of the moves of the game log after the given move id, %d moves by default and %d at most", game.DefaultListLimit, game.MaxListLimit),
+				Args: graphql.FieldConfigArgument{
+					"after": &graphql.ArgumentConfig{Type: graphql.Int},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					after, _ := p.Args["after"].(int)
+					limit, _ := p.Args["limit"].(int)
+
+					if limit < 0 || limit > game.MaxListLimit || after < 0 {
+						return nil, codedError{errors.New(apperrors.InvalidInput, nil, fmt.Sprintf("the limit must be between 0 and %d and the move id cannot be negative", game.MaxListLimit), "")}
+					}
+
+					if limit == 0 {
+						limit = game.DefaultListLimit
+					}
+
+					// the moves are logged in order with consecutive ids starting at 1
+					moves := []game.Move{}
+					if all := p.Source.(gameView).moves; after < len(all) {
+						moves = all[after:]
+					}
+
+					if len(moves) > limit {
+						moves = moves[:limit]
+					}
+
+					views := []moveView{}
+					for _, m := range moves {
+						views = append(views, newMoveView(m))
+					}
+
+					return views, nil
+				},
+			},
+		},
+	})
+)
+
+// NewSchema return the graphql schema resolving games through the game service and leaderboards through the daily service
+func NewSchema(games game.Service, dailies daily.Service) (graphql.Schema, error) {
+	r := resolver{games, dailies}
+
+	resultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Result",
+		Fields: graphql.Fields{
+			"rank":                 &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"playerId":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"status":               &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"elapsedTime":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"revealedSquaresCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			// the games are only given once the day is over
+			"gameId": &graphql.Field{
+				Type: graphql.ID,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if id := p.Source.(resultView).GameID; id != "" {
+						return id, nil
+					}
+
+					return nil, nil
+				},
+			},
+			"game": &graphql.Field{
+				Type: gameType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if id := p.Source.(resultView).GameID; id != "" {
+						return r.game(id)
+					}
+
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	leaderboardType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Leaderboard",
+		Fields: graphql.Fields{
+			"date":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"results": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(resultType)))},
+		},
+	})
+
+	gameIDArgs := graphql.FieldConfigArgument{
+		"gameId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	moveArgs := graphql.FieldConfigArgument{
+		"gameId":         &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+		"row":            &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+		"column":         &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+		"ifVersion":      &graphql.ArgumentConfig{Type: graphql.Int},
+		"idempotencyKey": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+
+	markArgs := graphql.FieldConfigArgument{
+		"mark": &graphql.ArgumentConfig{Type: markEnum, DefaultValue: ""},
+	}
+	for name, arg := range moveArgs {
+		markArgs[name] = arg
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"game": &graphql.Field{
+				Type: gameType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.game(p.Args["id"].(string))
+				},
+			},
+			"games": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(gameType))),
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ids := p.Args["ids"].([]interface{})
+					if len(ids) > game.MaxListLimit {
+						return nil, codedError{errors.New(apperrors.InvalidInput, nil, fmt.Sprintf("no more than %d games can be queried at once", game.MaxListLimit), "")}
+					}
+
+					views := []gameView{}
+
+					for _, id := range ids {
+						view, err := r.game(id.(string))
+						if err != nil {
+							return nil, err
+						}
+
+						views = append(views, view)
+					}
+
+					return views, nil
+				},
+			},
+			"board": &graphql.Field{
+				Type: boardType,
+				Args: gameIDArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					view, err := r.game(p.Args["gameId"].(string))
+					if err != nil {
+						return nil, err
+					}
+
+					return view.Board, nil
+				},
+			},
+			"squares": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(squareType))),
+				Args: graphql.FieldConfigArgument{
+					"gameId":   gameIDArgs["gameId"],
+					"revealed": squaresArgs["revealed"],
+					"marked":   squaresArgs["marked"],
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					view, err := r.game(p.Args["gameId"].(string))
+					if err != nil {
+						return nil, err
+					}
+
+					return filterSquares(view.Board.Squares, p.Args), nil
+				},
+			},
+			"dailyLeaderboard": &graphql.Field{
+				Type:        graphql.NewNonNull(leaderboardType),
+				Description: "Leaderboard of the daily challenge of the given date, today when none is given",
+				Args: graphql.FieldConfigArgument{
+					"date": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					date, _ := p.Args["date"].(string)
+
+					leaderboard, err := r.dailies.GetLeaderboard(date)
+					if err != nil {
+						return nil, codedError{err}
+					}
+
+					return newLeaderboardView(leaderboard), nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createGame": &graphql.Field{
+				Type: graphql.NewNonNull(gameType),
+				Args: graphql.FieldConfigArgument{
+					"rows":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"columns": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"bombs":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"mode":    &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.createGame,
+			},
+			"playSquare": &graphql.Field{
+				Type: graphql.NewNonNull(gameType),
+				Args: moveArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.move(p, r.games.PlaySquare)
+				},
+			},
+			"markSquare": &graphql.Field{
+				Type: graphql.NewNonNull(gameType),
+				Args: markArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.move(p, r.games.MarkSquare)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+type resolver struct {
+	games   game.Service
+	dailies daily.Service
+}
+
+func (r resolver) game(id string) (gameView, error) {
+	g, err := r.games.Get(id)
+	if err != nil {
+		return gameView{}, codedError{err}
+	}
+
+	return newGameView(g), nil
+}
+
+func (r resolver) createGame(p graphql.ResolveParams) (interface{}, error) {
+	configuration := game.Configuration{
+		Rows:    p.Args["rows"].(int),
+		Columns: p.Args["columns"].(int),
+		Bombs:   p.Args["bombs"].(int),
+	}
+
+	configuration.Mode, _ = p.Args["mode"].(string)
+
+	err := validate.Struct(configuration)
+	if err != nil {
+		return nil, codedError{errors.New(apperrors.InvalidInput, err, "invalid configuration", "validations has failed")}
+	}
+
+	g, err := r.games.Create(configuration, playerFromContext(p.Context))
+	if err != nil {
+		return nil, codedError{err}
+	}
+
+	return newGameView(g), nil
+}
+
+// move performs the move of the mutation through the given service method
+func (r resolver) move(p graphql.ResolveParams, perform func(gameID string, action game.Action) (game.Game, error)) (interface{}, error) {
+	action := game.Action{
+		PlayerID: playerFromContext(p.Context),
+		Position: board.SquarePosition{Row: p.Args["row"].(int), Column: p.Args["column"].(int)},
+	}
+
+	action.Mark, _ = p.Args["mark"].(string)
+	action.IdempotencyKey, _ = p.Args["idempotencyKey"].(string)
+
+	if version, ok := p.Args["ifVersion"].(int); ok {
+		v := int64(version)
+		action.IfVersion = &v
+	}
+
+	g, err := perform(p.Args["gameId"].(string), action)
+	if err != nil {
+		return nil, codedError{err}
+	}
+
+	return newGameView(g), nil
+}
+
+// filterSquares return the squares matching the revealed and marked args, when given
+func filterSquares(squares []squareView, args map[string]interface{}) []squareView {
+	revealed, filterRevealed := args["revealed"].(bool)
+	marked, filterMarked := args["marked"].(bool)
+
+	filtered := []squareView{}
+	for _, s := range squares {
+		if (filterRevealed && s.Revealed != revealed) || (filterMarked && s.Marked != marked) {
+			continue
+		}
+
+		filtered = append(filtered, s)
+	}
+
+	return filtered
+}
+
+func playerFromContext(ctx context.Context) string {
+	playerID, _ := ctx.Value(playerKey{}).(string)
+
+	return playerID
+}
@@ -0,0 +1,102 @@
+package match
+
+import (
+	"reflect"
+
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"gopkg.in/go-playground/validator.v8"
+)
+
+const (
+	STATUS_WAITING   string = "waiting"
+	STATUS_COUNTDOWN string = "countdown"
+	STATUS_ON_GOING  string = "on_going"
+	STATUS_FINISHED  string = "finished"
+
+	PARTICIPANT_WAITING    string = "waiting"
+	PARTICIPANT_PLAYING    string = "playing"
+	PARTICIPANT_FINISHED   string = "finished"
+	PARTICIPANT_ELIMINATED string = "eliminated"
+
+	// ON_LOSS_ELIMINATE takes a player out of the match when a bomb is hit
+	ON_LOSS_ELIMINATE string = "eliminate"
+	// ON_LOSS_PENALTY gives a player a new board and adds a time penalty when a bomb is hit. The layout of the
+	// new board is not the one that has been revealed, but the one every participant gets on the same attempt.
+	ON_LOSS_PENALTY string = "penalty"
+
+	MinParticipants = 2
+)
+
+// Match is a race where every participant plays its own board, all of them with the same bombs layout
+type Match struct {
+	ID            string        `json:"id"`
+	OwnerID       string        `json:"owner_id"`
+	Configuration Configuration `json:"configuration"`
+	Seed          int64         `json:"seed,omitempty"`
+	Status        string        `json:"status"`
+	StartsAt      int64         `json:"starts_at,omitempty"`
+	Participants  []Participant `json:"participants"`
+	Ranking       []Result      `json:"ranking"`
+}
+
+// Obfuscate hide the seed of the match, which would give away the bombs layout, along with the games of the
+// participants but the given player, since a lost game reveals the layout played by the others
+func (m *Match) Obfuscate(playerID string) {
+	m.Seed = 0
+
+	for i := range m.Participants {
+		if m.Participants[i].PlayerID != playerID {
+			m.Participants[i].GameID = ""
+		}
+	}
+}
+
+func (m *Match) participant(playerID string) *Participant {
+	for i := range m.Participants {
+		if m.Participants[i].PlayerID == playerID {
+			return &m.Participants[i]
+		}
+	}
+
+	return nil
+}
+
+type Participant struct {
+	PlayerID       string `json:"player_id"`
+	GameID         string `json:"game_id,omitempty"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	PenaltySeconds int64  `json:"penalty_seconds"`
+	FinishingTime  int64  `json:"finishing_time,omitempty"`
+}
+
+// IsDone whether the participant can no longer play the match
+func (p *Participant) IsDone() bool {
+	return p.Status == PARTICIPANT_FINISHED || p.Status == PARTICIPANT_ELIMINATED
+}
+
+type Result struct {
+	Rank          int    `json:"rank"`
+	PlayerID      string `json:"player_id"`
+	Status        string `json:"status"`
+	FinishingTime int64  `json:"finishing_time,omitempty"`
+}
+
+type Configuration struct {
+	Game             game.Configuration `json:"game"`
+	OnLoss           string             `json:"on_loss" enums:"eliminate,penalty"`
+	PenaltySeconds   int64              `json:"penalty_seconds" validate:"gte=0"`
+	CountdownSeconds int64              `json:"countdown_seconds" validate:"gte=0"`
+}
+
+func ConfigurationStructValidation(v *validator.Validate, structLevel *validator.StructLevel) {
+	configuration := structLevel.CurrentStruct.Interface().(Configuration)
+
+	if configuration.OnLoss != ON_LOSS_ELIMINATE && configuration.OnLoss != ON_LOSS_PENALTY {
+		structLevel.ReportError(reflect.ValueOf(configuration.OnLoss), "OnLoss", "on_loss", "onloss")
+	}
+
+	if configuration.Game.Mode != "" && configuration.Game.Mode != game.MODE_CLASSIC {
+		structLevel.ReportError(reflect.ValueOf(configuration.Game.Mode), "Mode", "mode", "mode")
+	}
+}
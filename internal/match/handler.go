@@ -0,0 +1,131 @@
+package match
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"gopkg.in/go-playground/validator.v8"
+)
+
+type HttpHandler interface {
+	Create(*gin.Context)
+	Get(*gin.Context)
+	Join(*gin.Context)
+	Start(*gin.Context)
+}
+
+type httpHandler struct {
+	service Service
+}
+
+var (
+	validate *validator.Validate
+)
+
+func init() {
+	validate = validator.New(&validator.Config{TagName: "validate"})
+	validate.RegisterStructValidation(game.ConfigurationStructValidation, game.Configuration{})
+	validate.RegisterStructValidation(ConfigurationStructValidation, Configuration{})
+}
+
+func NewHttpHandler(service Service) HttpHandler {
+	return &httpHandler{service}
+}
+
+func (h *httpHandler) Get(c *gin.Context) {
+	playerID, err := player.OptionalFromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match, err := h.service.Get(c.Param("id"))
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match.Obfuscate(playerID)
+
+	c.JSON(200, match)
+}
+
+func (h *httpHandler) Create(c *gin.Context) {
+	playerID, err := player.FromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	configuration := Configuration{}
+
+	err = c.BindJSON(&configuration)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "bind json has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	err = validate.Struct(configuration)
+	if err != nil {
+		apierr := apperrors.ToApiError(errors.New(apperrors.InvalidInput, err, "invalid body", "validations has failed"))
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match, err := h.service.Create(configuration, playerID)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match.Obfuscate(playerID)
+
+	c.JSON(201, match)
+}
+
+func (h *httpHandler) Join(c *gin.Context) {
+	playerID, err := player.FromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match, err := h.service.Join(c.Param("id"), playerID)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match.Obfuscate(playerID)
+
+	c.JSON(200, match)
+}
+
+func (h *httpHandler) Start(c *gin.Context) {
+	playerID, err := player.FromContext(c)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match, err := h.service.Start(c.Param("id"), playerID)
+	if err != nil {
+		apierr := apperrors.ToApiError(err)
+		c.AbortWithStatusJSON(apierr.Status, apierr)
+		return
+	}
+
+	match.Obfuscate(playerID)
+
+	c.JSON(200, match)
+}
@@ -0,0 +1,359 @@
+package match_test
+
+import (
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/match"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	service          match.Service
+	gameService      game.Service
+	fakeGameStorage  *fakesto.GameStorage
+	fakeMatchStorage *fakesto.MatchStorage
+)
+
+func init() {
+	fakeGameStorage = fakesto.NewGameStorage()
+	fakeMatchStorage = fakesto.NewMatchStorage()
+	gameService = game.NewService(fakeGameStorage, game.NewHub())
+	service = match.NewService(gameService, fakeMatchStorage)
+}
+
+func newConfiguration(onLoss string, countdown int64) match.Configuration {
+	return match.Configuration{
+		Game:             game.Configuration{Rows: 5, Columns: 5, Bombs: 5},
+		OnLoss:           onLoss,
+		PenaltySeconds:   30,
+		CountdownSeconds: countdown,
+	}
+}
+
+// newStartedMatch creates a started match between alice and bob
+func newStartedMatch(configuration match.Configuration) match.Match {
+	m, _ := service.Create(configuration, "alice")
+	_, _ = service.Join(m.ID, "bob")
+	m, _ = service.Start(m.ID, "alice")
+
+	return m
+}
+
+// finishGame sets the final status of the game of the participant
+func finishGame(p match.Participant, status string, finishedAt int64) {
+	g, _ := fakeGameStorage.GetByID(p.GameID)
+	g.Board.Status = status
+	g.FinishedAt = finishedAt
+	_ = fakeGameStorage.Update(g)
+}
+
+func TestJoin(t *testing.T) {
+	type input struct {
+		playerID string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func() match.Match
+		verify func(t *testing.T, in input, m match.Match, err error)
+	}{
+		{
+			name:   "join success",
+			should: "add the player as a waiting participant",
+			input:  input{"bob"},
+			mock: func() match.Match {
+				m, _ := service.Create(newConfiguration(match.ON_LOSS_ELIMINATE, 0), "alice")
+				return m
+			},
+			verify: func(t *testing.T, in input, m match.Match, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, m.Participants, 2)
+				assert.Equal(t, in.playerID, m.Participants[1].PlayerID)
+				assert.Equal(t, match.PARTICIPANT_WAITING, m.Participants[1].Status)
+			},
+		},
+		{
+			name:   "join a started match",
+			should: "return an invalid input error",
+			input:  input{"carol"},
+			mock: func() match.Match {
+				return newStartedMatch(newConfiguration(match.ON_LOSS_ELIMINATE, 0))
+			},
+			verify: func(t *testing.T, in input, m match.Match, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGameStorage.CleanDB()
+			fakeMatchStorage.CleanDB()
+
+			m := tt.mock()
+
+			m, err := service.Join(m.ID, tt.input.playerID)
+
+			tt.verify(t, tt.input, m, err)
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	type input struct {
+		playerID string
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  input
+		mock   func() match.Match
+		verify func(t *testing.T, in input, m match.Match, err error)
+	}{
+		{
+			name:   "start success",
+			should: "create a game with the same bombs layout for every participant",
+			input:  input{"alice"},
+			mock: func() match.Match {
+				m, _ := service.Create(newConfiguration(match.ON_LOSS_ELIMINATE, 0), "alice")
+				m, _ = service.Join(m.ID, "bob")
+				return m
+			},
+			verify: func(t *testing.T, in input, m match.Match, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, match.STATUS_ON_GOING, m.Status)
+
+				alice, _ := gameService.Get(m.Participants[0].GameID)
+				bob, _ := gameService.Get(m.Participants[1].GameID)
+				assert.Equal(t, "alice", alice.PlayerID)
+				assert.Equal(t, "bob", bob.PlayerID)
+				assert.Equal(t, alice.Board.Squares, bob.Board.Squares)
+			},
+		},
+		{
+			name:   "start with countdown",
+			should: "not allow to play the games before the countdown is over",
+			input:  input{"alice"},
+			mock: func() match.Match {
+				m, _ := service.Create(newConfiguration(match.ON_LOSS_ELIMINATE, 60), "alice")
+				m, _ = service.Join(m.ID, "bob")
+				return m
+			},
+			verify: func(t *testing.T, in input, m match.Match, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, match.STATUS_COUNTDOWN, m.Status)
+
+				_, err = gameService.PlaySquare(m.Participants[0].GameID, game.Action{PlayerID: "alice", Position: board.SquarePosition{Row: 0, Column: 0}})
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "start by a participant",
+			should: "return a forbidden error",
+			input:  input{"bob"},
+			mock: func() match.Match {
+				m, _ := service.Create(newConfiguration(match.ON_LOSS_ELIMINATE, 0), "alice")
+				m, _ = service.Join(m.ID, "bob")
+				return m
+			},
+			verify: func(t *testing.T, in input, m match.Match, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.Forbidden))
+			},
+		},
+		{
+			name:   "start alone",
+			should: "return an invalid input error",
+			input:  input{"alice"},
+			mock: func() match.Match {
+				m, _ := service.Create(newConfiguration(match.ON_LOSS_ELIMINATE, 0), "alice")
+				return m
+			},
+			verify: func(t *testing.T, in input, m match.Match, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGameStorage.CleanDB()
+			fakeMatchStorage.CleanDB()
+
+			m := tt.mock()
+
+			m, err := service.Start(m.ID, tt.input.playerID)
+
+			tt.verify(t, tt.input, m, err)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		mock   func() match.Match
+		verify func(t *testing.T, m match.Match, err error)
+	}{
+		{
+			name:   "eliminate on loss",
+			should: "eliminate the player who lost and rank the one who won first",
+			mock: func() match.Match {
+				m := newStartedMatch(newConfiguration(match.ON_LOSS_ELIMINATE, 0))
+				finishGame(m.Participants[0], board.STATUS_LOST, m.StartsAt+10)
+				finishGame(m.Participants[1], board.STATUS_WON, m.StartsAt+100)
+				return m
+			},
+			verify: func(t *testing.T, m match.Match, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, match.STATUS_FINISHED, m.Status)
+				assert.Equal(t, match.PARTICIPANT_ELIMINATED, m.Participants[0].Status)
+				assert.Equal(t, match.PARTICIPANT_FINISHED, m.Participants[1].Status)
+
+				assert.Equal(t, []match.Result{
+					{Rank: 1, PlayerID: "bob", Status: match.PARTICIPANT_FINISHED, FinishingTime: 100},
+					{Rank: 2, PlayerID: "alice", Status: match.PARTICIPANT_ELIMINATED},
+				}, m.Ranking)
+			},
+		},
+		{
+			name:   "penalty on loss",
+			should: "give the player who lost a new game with another layout and a time penalty",
+			mock: func() match.Match {
+				m := newStartedMatch(newConfiguration(match.ON_LOSS_PENALTY, 0))
+				finishGame(m.Participants[0], board.STATUS_LOST, m.StartsAt+10)
+				return m
+			},
+			verify: func(t *testing.T, m match.Match, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, match.STATUS_ON_GOING, m.Status)
+
+				alice := m.Participants[0]
+				assert.Equal(t, match.PARTICIPANT_PLAYING, alice.Status)
+				assert.Equal(t, 2, alice.Attempts)
+				assert.Equal(t, int64(30), alice.PenaltySeconds)
+
+				// the layout revealed by the lost game is the one bob still plays
+				first, _ := gameService.Get(m.Participants[1].GameID)
+				second, _ := gameService.Get(alice.GameID)
+				assert.Equal(t, board.STATUS_ON_GOING, second.Board.Status)
+				assert.NotEqual(t, *first.Board.BombsPositions, *second.Board.BombsPositions)
+
+				// bob gets the same layout as alice on his second attempt
+				finishGame(m.Participants[1], board.STATUS_LOST, m.StartsAt+20)
+
+				m, err = service.Get(m.ID)
+				assert.Nil(t, err)
+
+				bob, _ := gameService.Get(m.Participants[1].GameID)
+				assert.Equal(t, 2, m.Participants[1].Attempts)
+				assert.Equal(t, second.Board.Squares, bob.Board.Squares)
+
+				finishGame(m.Participants[0], board.STATUS_WON, m.StartsAt+50)
+
+				m, err = service.Get(m.ID)
+				assert.Nil(t, err)
+				assert.Equal(t, int64(80), m.Participants[0].FinishingTime)
+			},
+		},
+		{
+			name:   "expired game",
+			should: "eliminate the player whose game no longer exists",
+			mock: func() match.Match {
+				m := newStartedMatch(newConfiguration(match.ON_LOSS_PENALTY, 0))
+				_ = fakeGameStorage.Delete(m.Participants[0].GameID)
+				finishGame(m.Participants[1], board.STATUS_WON, m.StartsAt+100)
+				return m
+			},
+			verify: func(t *testing.T, m match.Match, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, match.STATUS_FINISHED, m.Status)
+				assert.Equal(t, match.PARTICIPANT_ELIMINATED, m.Participants[0].Status)
+
+				assert.Equal(t, []match.Result{
+					{Rank: 1, PlayerID: "bob", Status: match.PARTICIPANT_FINISHED, FinishingTime: 100},
+					{Rank: 2, PlayerID: "alice", Status: match.PARTICIPANT_ELIMINATED},
+				}, m.Ranking)
+			},
+		},
+		{
+			name:   "match not found",
+			should: "return a not found error",
+			mock: func() match.Match {
+				return match.Match{ID: "123"}
+			},
+			verify: func(t *testing.T, m match.Match, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGameStorage.CleanDB()
+			fakeMatchStorage.CleanDB()
+
+			m := tt.mock()
+
+			m, err := service.Get(m.ID)
+
+			tt.verify(t, m, err)
+		})
+	}
+}
+
+func TestObfuscate(t *testing.T) {
+	m := match.Match{
+		ID:   "123",
+		Seed: 42,
+		Participants: []match.Participant{
+			{PlayerID: "alice", GameID: "1"},
+			{PlayerID: "bob", GameID: "2"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		should   string
+		playerID string
+		expected []string
+	}{
+		{
+			name:     "participant",
+			should:   "keep only the game of the player",
+			playerID: "bob",
+			expected: []string{"", "2"},
+		},
+		{
+			name:     "spectator",
+			should:   "hide every game",
+			playerID: "",
+			expected: []string{"", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obfuscated := m
+			obfuscated.Participants = append([]match.Participant{}, m.Participants...)
+
+			obfuscated.Obfuscate(tt.playerID)
+
+			assert.Zero(t, obfuscated.Seed)
+			assert.Equal(t, tt.expected, []string{obfuscated.Participants[0].GameID, obfuscated.Participants[1].GameID})
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package match
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/matiasvarela/minesweeper/pkg/uuid"
+)
+
+type Service interface {
+	Get(id string) (Match, error)
+	Create(configuration Configuration, ownerID string) (Match, error)
+	Join(id string, playerID string) (Match, error)
+	Start(id string, playerID string) (Match, error)
+}
+
+type service struct {
+	games   game.Service
+	storage Storage
+	mutex   sync.Mutex
+}
+
+func NewService(games game.Service, storage Storage) Service {
+	return &service{games: games, storage: storage}
+}
+
+// Get return the match after bringing the participants up to date with their games
+func (s *service) Get(id string) (Match, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	m, err := s.get(id)
+	if err != nil {
+		return Match{}, err
+	}
+
+	changed, err := s.refresh(&m)
+	if err != nil {
+		return Match{}, err
+	}
+
+	if !changed {
+		return m, nil
+	}
+
+	err = s.storage.Update(m)
+	if err != nil {
+		return Match{}, errors.New(apperrors.Internal, err, "internal error", "update match into storage has failed")
+	}
+
+	return m, nil
+}
+
+// Create creates a new match, whose owner is its first participant
+func (s *service) Create(configuration Configuration, ownerID string) (Match, error) {
+	id, err := uuid.New()
+	if err != nil {
+		return Match{}, errors.New(apperrors.Internal, err, "internal error", "generate new uuid has fail")
+	}
+
+	configuration.Game.Mode = game.MODE_CLASSIC
+
+	m := Match{
+		ID:            id,
+		OwnerID:       ownerID,
+		Configuration: configuration,
+		Seed:          rand.New(rand.NewSource(time.Now().UnixNano())).Int63(),
+		Status:        STATUS_WAITING,
+		Participants:  []Participant{{PlayerID: ownerID, Status: PARTICIPANT_WAITING}},
+		Ranking:       []Result{},
+	}
+
+	err = s.storage.Create(m)
+	if err != nil {
+		return Match{}, errors.New(apperrors.Internal, err, "internal error", "create match into storage has failed")
+	}
+
+	return m, nil
+}
+
+// Join adds the player to a match that has not started yet. Joining twice has no effect.
+func (s *service) Join(id string, playerID string) (Match, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	m, err := s.get(id)
+	if err != nil {
+		return Match{}, err
+	}
+
+	if m.participant(playerID) != nil {
+		return m, nil
+	}
+
+	if m.Status != STATUS_WAITING {
+		return Match{}, errors.New(apperrors.InvalidInput, nil, "cannot join a match that has already started", "")
+	}
+
+	m.Participants = append(m.Participants, Participant{PlayerID: playerID, Status: PARTICIPANT_WAITING})
+
+	err = s.storage.Update(m)
+	if err != nil {
+		return Match{}, errors.New(apperrors.Internal, err, "internal error", "update match into storage has failed")
+	}
+
+	return m, nil
+}
+
+// Start creates the games of every participant, all of them with the same bombs layout,
+// which can be played once the countdown is over. Only the owner can start a match.
+func (s *service) Start(id string, playerID string) (Match, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	m, err := s.get(id)
+	if err != nil {
+		return Match{}, err
+	}
+
+	if m.OwnerID != playerID {
+		return Match{}, errors.New(apperrors.Forbidden, nil, "only the owner can start the match", "")
+	}
+
+	if m.Status != STATUS_WAITING {
+		return Match{}, errors.New(apperrors.InvalidInput, nil, "the match has already started", "")
+	}
+
+	if len(m.Participants) < MinParticipants {
+		return Match{}, errors.New(apperrors.InvalidInput, nil, "not enough participants to start the match", "")
+	}
+
+	m.Status = STATUS_COUNTDOWN
+	m.StartsAt = time.Now().Unix() + m.Configuration.CountdownSeconds
+
+	for i := range m.Participants {
+		err = s.newAttempt(&m, &m.Participants[i], m.StartsAt)
+		if err != nil {
+			return Match{}, err
+		}
+	}
+
+	_, err = s.refresh(&m)
+	if err != nil {
+		return Match{}, err
+	}
+
+	err = s.storage.Update(m)
+	if err != nil {
+		return Match{}, errors.New(apperrors.Internal, err, "internal error", "update match into storage has failed")
+	}
+
+	return m, nil
+}
+
+// refresh updates the status of the match and its participants from their games and return whether anything changed.
+// Participants who lost are either eliminated or given a new game with a time penalty, according to the configuration,
+// and the ones whose game no longer exists are eliminated.
+func (s *service) refresh(m *Match) (bool, error) {
+	if m.Status == STATUS_WAITING || m.Status == STATUS_FINISHED {
+		return false, nil
+	}
+
+	changed := false
+
+	if m.Status == STATUS_COUNTDOWN && time.Now().Unix() >= m.StartsAt {
+		m.Status = STATUS_ON_GOING
+		changed = true
+	}
+
+	for i := range m.Participants {
+		p := &m.Participants[i]
+		if p.IsDone() {
+			continue
+		}
+
+		g, err := s.games.Get(p.GameID)
+		if errors.Is(err, apperrors.NotFound) {
+			// the game has expired or has been deleted, so it can no longer be finished
+			p.Status = PARTICIPANT_ELIMINATED
+			changed = true
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrap(err, err.Error())
+		}
+
+		switch g.Board.Status {
+		case board.STATUS_WON:
+			p.Status = PARTICIPANT_FINISHED
+			p.FinishingTime = g.FinishedAt - m.StartsAt + p.PenaltySeconds
+		case board.STATUS_LOST:
+			if m.Configuration.OnLoss == ON_LOSS_ELIMINATE {
+				p.Status = PARTICIPANT_ELIMINATED
+				break
+			}
+
+			p.PenaltySeconds += m.Configuration.PenaltySeconds
+
+			err = s.newAttempt(m, p, 0)
+			if err != nil {
+				return false, err
+			}
+		default:
+			continue
+		}
+
+		changed = true
+	}
+
+	done := true
+	for i := range m.Participants {
+		done = done && m.Participants[i].IsDone()
+	}
+
+	if done {
+		m.Status = STATUS_FINISHED
+		changed = true
+	}
+
+	if changed {
+		m.Ranking = rank(m.Participants)
+	}
+
+	return changed, nil
+}
+
+// newAttempt creates a new game for the participant with the bombs layout of its next attempt
+func (s *service) newAttempt(m *Match, p *Participant, startsAt int64) error {
+	g, err := s.games.CreateSeeded(m.Configuration.Game, attemptSeed(m.Seed, p.Attempts+1), p.PlayerID, startsAt)
+	if err != nil {
+		return errors.Wrap(err, err.Error())
+	}
+
+	p.GameID = g.ID
+	p.Status = PARTICIPANT_PLAYING
+	p.Attempts++
+
+	return nil
+}
+
+func (s *service) get(id string) (Match, error) {
+	m, err := s.storage.GetByID(id)
+	if err != nil {
+		if errors.Is(err, apperrors.NotFound) {
+			return Match{}, errors.New(apperrors.NotFound, err, "match has not been found", "match not found in storage")
+		}
+
+		return Match{}, errors.New(apperrors.Internal, err, "internal error", "get match from storage has failed")
+	}
+
+	return m, nil
+}
+
+// Helper
+
+// attemptSeed return the seed of the bombs layout of the given attempt, starting at 1. Every participant gets the
+// same layouts in the same order, while a retry never gets the layout revealed by the game that was lost.
+func attemptSeed(seed int64, attempt int) int64 {
+	if attempt == 1 {
+		return seed
+	}
+
+	sum := sha256.Sum256([]byte(strconv.FormatInt(seed, 10) + ":" + strconv.Itoa(attempt)))
+
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+// rank sort the participants that finished by finishing time, followed by the eliminated ones
+func rank(participants []Participant) []Result {
+	results := []Result{}
+
+	for _, p := range participants {
+		if p.IsDone() {
+			results = append(results, Result{PlayerID: p.PlayerID, Status: p.Status, FinishingTime: p.FinishingTime})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Status != results[j].Status {
+			return results[i].Status == PARTICIPANT_FINISHED
+		}
+
+		return results[i].FinishingTime < results[j].FinishingTime
+	})
+
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+
+	return results
+}
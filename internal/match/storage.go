@@ -0,0 +1,7 @@
+package match
+
+type Storage interface {
+	Create(m Match) error
+	Update(m Match) error
+	GetByID(id string) (Match, error)
+}
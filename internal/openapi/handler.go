@@ -0,0 +1,21 @@
+package openapi
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type HttpHandler interface {
+	Get(*gin.Context)
+}
+
+type httpHandler struct {
+	document *Document
+}
+
+func NewHttpHandler(document *Document) HttpHandler {
+	return &httpHandler{document}
+}
+
+func (h *httpHandler) Get(c *gin.Context) {
+	c.JSON(200, h.document)
+}
@@ -0,0 +1,233 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// New return an empty document for the api with the given title and version
+func New(title string, version string) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       Info{Title: title, Version: version},
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+}
+
+// Add describes the operation served on the given gin route, whose path parameters are documented as required strings
+func (d *Document) Add(method string, route string, operation Operation) {
+	path, params := Path(route)
+
+	for _, param := range params {
+		operation.Parameters = append([]Parameter{{Name: param, In: "path", Required: true, Schema: &Schema{Type: "string"}}}, operation.Parameters...)
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	(*item)[strings.ToLower(method)] = &operation
+}
+
+// Has whether the operation served on the given gin route has been described
+func (d *Document) Has(method string, route string) bool {
+	path, _ := Path(route)
+
+	item, ok := d.Paths[path]
+	if !ok {
+		return false
+	}
+
+	_, ok = (*item)[strings.ToLower(method)]
+
+	return ok
+}
+
+// Schema registers the schema of the given value as a component and return a reference to it. Structs registered
+// beforehand are referenced from the schemas of the values holding them.
+func (d *Document) Schema(name string, v interface{}) *Schema {
+	d.Components.Schemas[name] = d.schemaOf(reflect.TypeOf(v), false)
+
+	return Ref(name)
+}
+
+// Ref return a reference to the component with the given name
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Path return the OpenAPI path of the given gin route along with the names of its parameters
+func Path(route string) (string, []string) {
+	segments := strings.Split(route, "/")
+	params := []string{}
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// JSON return the content of a json body with the given schema
+func JSON(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+func (d *Document) schemaOf(t reflect.Type, reference bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		schema := d.schemaOf(t.Elem(), true)
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+
+		return schema
+	}
+
+	if reference && t.Kind() == reflect.Struct {
+		for name, component := range d.Components.Schemas {
+			if component.goType == t {
+				return Ref(name)
+			}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem(), true)}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return d.structSchema(t)
+	default:
+		// interfaces hold any value
+		return &Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}, goType: t}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		// embedded structs without a name have their fields promoted
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := d.structSchema(field.Type)
+
+			for key, property := range embedded.Properties {
+				if _, ok := schema.Properties[key]; !ok {
+					schema.Properties[key] = property
+				}
+			}
+
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schemaOf(field.Type, true)
+		if enums := field.Tag.Get("enums"); enums != "" && property.Ref == "" {
+			property.Enum = enumValues(enums, property.Type)
+		}
+
+		if constraint(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// constraint applies the validations of the given validate tag to the schema and return whether the value is required
+func constraint(schema *Schema, tag string) bool {
+	required := false
+
+	for _, rule := range strings.Split(tag, ",") {
+		// the rules after dive apply to the items of the value
+		if rule == "dive" {
+			break
+		}
+
+		parts := strings.SplitN(rule, "=", 2)
+		if parts[0] == "required" {
+			required = true
+			continue
+		}
+
+		if len(parts) != 2 {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case schema.Type == "array" && (parts[0] == "min" || parts[0] == "gte"):
+			n := int(value)
+			schema.MinItems = &n
+		case schema.Type == "array" && (parts[0] == "max" || parts[0] == "lte"):
+			n := int(value)
+			schema.MaxItems = &n
+		case parts[0] == "min" || parts[0] == "gte":
+			schema.Minimum = &value
+		case parts[0] == "max" || parts[0] == "lte":
+			schema.Maximum = &value
+		}
+	}
+
+	return required
+}
+
+func enumValues(tag string, kind string) []interface{} {
+	values := []interface{}{}
+
+	for _, value := range strings.Split(tag, ",") {
+		if kind == "integer" {
+			n, err := strconv.Atoi(value)
+			if err == nil {
+				values = append(values, n)
+				continue
+			}
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
@@ -0,0 +1,27 @@
+package player
+
+import (
+	"context"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the gRPC metadata key carrying the id of the player performing the call
+const MetadataKey = "x-player-id"
+
+// OptionalFromIncomingContext return the id of the player performing the gRPC call or an empty string for anonymous calls
+func OptionalFromIncomingContext(ctx context.Context) (string, error) {
+	id := ""
+
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataKey); len(values) > 0 {
+		id = values[0]
+	}
+
+	if len(id) > MaxIDLength {
+		return "", errors.New(apperrors.InvalidInput, nil, "invalid player id", "player id is too long")
+	}
+
+	return id, nil
+}
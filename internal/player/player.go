@@ -0,0 +1,46 @@
+package player
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+const (
+	// Header is the request header carrying the id of the player performing the request
+	Header = "X-Player-ID"
+
+	// QueryParam carries the player id on requests that cannot set headers, such as web socket handshakes
+	QueryParam = "player_id"
+
+	// MaxIDLength is the maximum length accepted for a player id
+	MaxIDLength = 40
+)
+
+// FromContext return the id of the player performing the request
+func FromContext(c *gin.Context) (string, error) {
+	id, err := OptionalFromContext(c)
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" {
+		return "", errors.New(apperrors.Unauthorized, nil, "player id is required", "missing "+Header+" header")
+	}
+
+	return id, nil
+}
+
+// OptionalFromContext return the id of the player performing the request or an empty string for anonymous requests
+func OptionalFromContext(c *gin.Context) (string, error) {
+	id := c.GetHeader(Header)
+	if id == "" {
+		id = c.Query(QueryParam)
+	}
+
+	if len(id) > MaxIDLength {
+		return "", errors.New(apperrors.InvalidInput, nil, "invalid player id", "player id is too long")
+	}
+
+	return id, nil
+}
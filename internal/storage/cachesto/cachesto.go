@@ -0,0 +1,281 @@
+package cachesto
+
+import (
+	"container/list"
+	"expvar"
+	"fmt"
+	"sync"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// GameStorage keeps the most recently used games of another storage in memory, so that reading them again
+// does not hit the storage. Writes go through to the storage before being cached, which remains the source
+// of truth: games updated by other processes are read stale until they are evicted, or until a conflicting
+// update evicts them.
+type GameStorage struct {
+	next game.Storage
+	size int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	// recency holds the cached games, the most recently used at the front
+	recency *list.List
+	// loads are the reads of the storage in progress, by game
+	loads map[string]*load
+
+	metrics *Metrics
+}
+
+// Metrics are the counters of a cache. It is an expvar.Var, so it can be published along with the hit rate.
+type Metrics struct {
+	Hits      expvar.Int
+	Misses    expvar.Int
+	Evictions expvar.Int
+}
+
+// load is the reads of a game from the storage in progress. A game deleted while it is read must not be cached
+// by them, since they may have read it before it was deleted.
+type load struct {
+	readers int
+	deleted bool
+}
+
+// NewGameStorage return a cache of at most size games in front of the given storage
+func NewGameStorage(next game.Storage, size int) *GameStorage {
+	return &GameStorage{
+		next:    next,
+		size:    size,
+		entries: map[string]*list.Element{},
+		recency: list.New(),
+		loads:   map[string]*load{},
+		metrics: &Metrics{},
+	}
+}
+
+// Metrics return the counters of the cache
+func (sto *GameStorage) Metrics() *Metrics {
+	return sto.metrics
+}
+
+// Len return the number of cached games
+func (sto *GameStorage) Len() int {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	return sto.recency.Len()
+}
+
+func (sto *GameStorage) Create(gameToCreate game.Game) error {
+	err := sto.next.Create(gameToCreate)
+	if err != nil {
+		return errors.Wrap(err, err.Error())
+	}
+
+	sto.put(gameToCreate)
+
+	return nil
+}
+
+func (sto *GameStorage) Update(gameToUpdate game.Game) error {
+	err := sto.next.Update(gameToUpdate)
+	if err != nil {
+		// the cached game is stale or gone, the next read must hit the storage
+		sto.evict(gameToUpdate.ID)
+		return errors.Wrap(err, err.Error())
+	}
+
+	gameToUpdate.Version++
+	sto.put(gameToUpdate)
+
+	return nil
+}
+
+func (sto *GameStorage) GetByID(id string) (game.Game, error) {
+	if g, ok := sto.get(id); ok {
+		sto.metrics.Hits.Add(1)
+		return g, nil
+	}
+
+	sto.metrics.Misses.Add(1)
+
+	sto.startLoad(id)
+
+	g, err := sto.next.GetByID(id)
+	if err != nil {
+		sto.endLoad(id, nil)
+		return game.Game{}, errors.Wrap(err, err.Error())
+	}
+
+	sto.endLoad(id, &g)
+
+	return g, nil
+}
+
+// Delete deletes the game from the storage, then evicts it. The game is evicted even when the deletion fails,
+// since it may have been deleted anyway, and the reads in progress are kept from caching it again.
+func (sto *GameStorage) Delete(id string) error {
+	err := sto.next.Delete(id)
+
+	sto.mutex.Lock()
+	if l, ok := sto.loads[id]; ok {
+		l.deleted = true
+	}
+	sto.mutex.Unlock()
+
+	sto.evict(id)
+
+	if err != nil {
+		return errors.Wrap(err, err.Error())
+	}
+
+	return nil
+}
+
+// List lists the games of the storage, it is only supported when the storage is a game.Lister
+func (sto *GameStorage) List(filter game.Filter) ([]game.Game, error) {
+	lister, ok := sto.next.(game.Lister)
+	if !ok {
+		return nil, errors.New(apperrors.Unsupported, nil, "listing games is not supported by the storage", "")
+	}
+
+	return lister.List(filter)
+}
+
+// Walk walks the games of the storage, it is only supported when the storage is a game.Walker
+func (sto *GameStorage) Walk(fn func(g game.Game) error) error {
+	walker, ok := sto.next.(game.Walker)
+	if !ok {
+		return errors.New(apperrors.Unsupported, nil, "walking games is not supported by the storage", "")
+	}
+
+	return walker.Walk(fn)
+}
+
+// GetAt reads the game at a past version from the storage, it is only supported when the storage is a game.Historian.
+// Past versions never change, so they are not cached.
+func (sto *GameStorage) GetAt(id string, version int64) (game.Game, error) {
+	historian, ok := sto.next.(game.Historian)
+	if !ok {
+		return game.Game{}, errors.New(apperrors.Unsupported, nil, "reading past versions of games is not supported by the storage", "")
+	}
+
+	return historian.GetAt(id, version)
+}
+
+// Compact compacts the storage when it is a game.Compacter
+func (sto *GameStorage) Compact() error {
+	compacter, ok := sto.next.(game.Compacter)
+	if !ok {
+		return nil
+	}
+
+	return compacter.Compact()
+}
+
+// get return a copy of the cached game and mark it as the most recently used
+func (sto *GameStorage) get(id string) (game.Game, bool) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	element, ok := sto.entries[id]
+	if !ok {
+		return game.Game{}, false
+	}
+
+	sto.recency.MoveToFront(element)
+
+	return element.Value.(game.Game).Clone(), true
+}
+
+// startLoad registers a read of the game from the storage
+func (sto *GameStorage) startLoad(id string) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	l, ok := sto.loads[id]
+	if !ok {
+		l = &load{}
+		sto.loads[id] = l
+	}
+
+	l.readers++
+}
+
+// endLoad ends a read of the game from the storage, caching the game read unless it has been deleted meanwhile
+func (sto *GameStorage) endLoad(id string, g *game.Game) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	l := sto.loads[id]
+
+	l.readers--
+	if l.readers == 0 {
+		delete(sto.loads, id)
+	}
+
+	if g != nil && !l.deleted {
+		sto.add(*g)
+	}
+}
+
+// put caches a copy of the game, evicting the least recently used games beyond the size of the cache.
+// A game is never replaced by an older version of itself.
+func (sto *GameStorage) put(g game.Game) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	sto.add(g)
+}
+
+// add is the same as put for callers already holding the mutex
+func (sto *GameStorage) add(g game.Game) {
+	if element, ok := sto.entries[g.ID]; ok {
+		if element.Value.(game.Game).Version <= g.Version {
+			element.Value = g.Clone()
+		}
+
+		sto.recency.MoveToFront(element)
+
+		return
+	}
+
+	sto.entries[g.ID] = sto.recency.PushFront(g.Clone())
+
+	for sto.recency.Len() > sto.size {
+		oldest := sto.recency.Back()
+		sto.recency.Remove(oldest)
+		delete(sto.entries, oldest.Value.(game.Game).ID)
+
+		sto.metrics.Evictions.Add(1)
+	}
+}
+
+// evict removes the game from the cache
+func (sto *GameStorage) evict(id string) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	if element, ok := sto.entries[id]; ok {
+		sto.recency.Remove(element)
+		delete(sto.entries, id)
+	}
+}
+
+// HitRate return the ratio of reads served by the cache, zero before any read
+func (m *Metrics) HitRate() float64 {
+	hits, misses := m.Hits.Value(), m.Misses.Value()
+	if hits+misses == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(hits+misses)
+}
+
+// String return the metrics as a json object
+func (m *Metrics) String() string {
+	return fmt.Sprintf(`{"hits": %d, "misses": %d, "evictions": %d, "hit_rate": %g}`,
+		m.Hits.Value(), m.Misses.Value(), m.Evictions.Value(), m.HitRate())
+}
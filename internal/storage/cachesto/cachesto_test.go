@@ -0,0 +1,272 @@
+package cachesto_test
+
+import (
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/cachesto"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/internal/storage/storagetest"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) game.Storage {
+		return cachesto.NewGameStorage(fakesto.NewGameStorage(), 100)
+	})
+}
+
+func TestGameStorage_Cache(t *testing.T) {
+	failure := errors.New(apperrors.Internal, nil, "fail", "")
+
+	tests := []struct {
+		name   string
+		should string
+		verify func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage)
+	}{
+		{
+			name:   "read twice",
+			should: "read the storage once and count a miss then a hit",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				assert.Nil(t, next.Create(storagetest.NewPlayedGame("1")))
+
+				first, err := cache.GetByID("1")
+				assert.Nil(t, err)
+
+				second, err := cache.GetByID("1")
+				assert.Nil(t, err)
+
+				assert.Equal(t, first, second)
+				assert.Len(t, next.CallsOf(fakesto.METHOD_GET_BY_ID), 1)
+				assert.Equal(t, int64(1), cache.Metrics().Hits.Value())
+				assert.Equal(t, int64(1), cache.Metrics().Misses.Value())
+				assert.Equal(t, 0.5, cache.Metrics().HitRate())
+			},
+		},
+		{
+			name:   "created game",
+			should: "be read from the cache",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				assert.Nil(t, cache.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC)))
+
+				_, err := cache.GetByID("1")
+				assert.Nil(t, err)
+
+				assert.Len(t, next.CallsOf(fakesto.METHOD_GET_BY_ID), 0)
+			},
+		},
+		{
+			name:   "failed creation",
+			should: "not cache the game",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				next.AddErrorOnCreate(failure)
+
+				assert.Equal(t, failure, errors.Cause(cache.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC))))
+				assert.Equal(t, 0, cache.Len())
+			},
+		},
+		{
+			name:   "missing game",
+			should: "not be cached",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				_, err := cache.GetByID("1")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+
+				assert.Nil(t, next.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC)))
+
+				_, err = cache.GetByID("1")
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:   "more games than the size",
+			should: "evict the least recently used games",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				for _, id := range []string{"1", "2", "3"} {
+					assert.Nil(t, cache.Create(storagetest.NewGame(id, "", game.MODE_CLASSIC)))
+				}
+
+				// reading 1 makes 2 the least recently used
+				_, _ = cache.GetByID("1")
+				assert.Nil(t, cache.Create(storagetest.NewGame("4", "", game.MODE_CLASSIC)))
+
+				assert.Equal(t, 3, cache.Len())
+				assert.Equal(t, int64(1), cache.Metrics().Evictions.Value())
+
+				next.CleanCalls()
+
+				for _, id := range []string{"1", "3", "4", "2"} {
+					_, err := cache.GetByID(id)
+					assert.Nil(t, err)
+				}
+
+				calls := next.CallsOf(fakesto.METHOD_GET_BY_ID)
+				assert.Len(t, calls, 1)
+				assert.Equal(t, "2", calls[0].ID)
+			},
+		},
+		{
+			name:   "update",
+			should: "write through the storage and cache the new version",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				g := storagetest.NewGame("1", "", game.MODE_CLASSIC)
+				assert.Nil(t, cache.Create(g))
+
+				g.Board.Status = board.STATUS_ON_GOING
+				assert.Nil(t, cache.Update(g))
+
+				stored, err := next.GetByID("1")
+				assert.Nil(t, err)
+
+				cached, err := cache.GetByID("1")
+				assert.Nil(t, err)
+
+				assert.Equal(t, stored, cached)
+				assert.Equal(t, int64(1), cached.Version)
+				assert.Equal(t, int64(0), cache.Metrics().Misses.Value())
+			},
+		},
+		{
+			name:   "conflicting update",
+			should: "evict the game so that it is read again from the storage",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				g := storagetest.NewGame("1", "", game.MODE_CLASSIC)
+				assert.Nil(t, cache.Create(g))
+
+				// another process updates the game behind the cache
+				assert.Nil(t, next.Update(g))
+
+				stale, _ := cache.GetByID("1")
+				assert.True(t, errors.Is(cache.Update(stale), apperrors.Conflict))
+
+				fresh, err := cache.GetByID("1")
+				assert.Nil(t, err)
+				assert.Equal(t, int64(1), fresh.Version)
+				assert.Nil(t, cache.Update(fresh))
+			},
+		},
+		{
+			name:   "delete",
+			should: "evict the game",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				assert.Nil(t, cache.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC)))
+				assert.Nil(t, cache.Delete("1"))
+
+				_, err := cache.GetByID("1")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+				assert.Equal(t, 0, cache.Len())
+			},
+		},
+		{
+			name:   "failed delete",
+			should: "evict the game anyway",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				assert.Nil(t, cache.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC)))
+				next.AddErrorOnDelete(failure)
+
+				assert.Equal(t, failure, errors.Cause(cache.Delete("1")))
+				assert.Equal(t, 0, cache.Len())
+			},
+		},
+		{
+			name:   "mutated game",
+			should: "not change the cached game",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				g := storagetest.NewPlayedGame("1")
+				created := g.Clone()
+				assert.Nil(t, cache.Create(g))
+				g.Board.Squares[0][0].Revealed = true
+				g.Moves[0].Squares[0].Marked = true
+
+				read, _ := cache.GetByID("1")
+				read.Board.Squares[0][1].Marked = true
+				read.Players = append(read.Players, game.Participant{ID: "mallory"})
+
+				cached, err := cache.GetByID("1")
+				assert.Nil(t, err)
+				assert.Equal(t, created, cached)
+			},
+		},
+		{
+			name:   "past version of a game",
+			should: "return an unsupported error when the storage is not a historian",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				_, err := cache.GetAt("1", 0)
+				assert.True(t, errors.Is(err, apperrors.Unsupported))
+			},
+		},
+		{
+			name:   "listing games",
+			should: "return an unsupported error when the storage is not a lister",
+			verify: func(t *testing.T, cache *cachesto.GameStorage, next *fakesto.GameStorage) {
+				_, err := cache.List(game.Filter{Limit: 10})
+				assert.True(t, errors.Is(err, apperrors.Unsupported))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := fakesto.NewGameStorage()
+			tt.verify(t, cachesto.NewGameStorage(next, 3), next)
+		})
+	}
+}
+
+// pausingStorage is a game storage whose reads wait to be released once they have read the game
+type pausingStorage struct {
+	*fakesto.GameStorage
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (sto *pausingStorage) GetByID(id string) (game.Game, error) {
+	g, err := sto.GameStorage.GetByID(id)
+
+	sto.read <- struct{}{}
+	<-sto.release
+
+	return g, err
+}
+
+func TestGameStorage_ConcurrentDelete(t *testing.T) {
+	next := &pausingStorage{GameStorage: fakesto.NewGameStorage(), read: make(chan struct{}, 1), release: make(chan struct{})}
+	cache := cachesto.NewGameStorage(next, 3)
+
+	assert.Nil(t, next.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC)))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		_, err := cache.GetByID("1")
+		assert.Nil(t, err)
+	}()
+
+	// the game is deleted once read by the cache miss, but before it is cached
+	<-next.read
+	assert.Nil(t, cache.Delete("1"))
+
+	close(next.release)
+	<-done
+
+	assert.Equal(t, 0, cache.Len())
+
+	_, err := cache.GetByID("1")
+	assert.True(t, errors.Is(err, apperrors.NotFound))
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &cachesto.Metrics{}
+	assert.Equal(t, float64(0), metrics.HitRate())
+
+	metrics.Hits.Add(3)
+	metrics.Misses.Add(1)
+	metrics.Evictions.Add(2)
+
+	assert.Equal(t, 0.75, metrics.HitRate())
+	assert.JSONEq(t, `{"hits": 3, "misses": 1, "evictions": 2, "hit_rate": 0.75}`, metrics.String())
+}
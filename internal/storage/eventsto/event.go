@@ -0,0 +1,128 @@
+package eventsto
+
+import (
+	"encoding/json"
+
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/schema"
+)
+
+const (
+	// EVENT_CREATED is the first event of a game, it holds the whole game
+	EVENT_CREATED string = "created"
+	// EVENT_UPDATED holds the moves of an update along with the squares they changed
+	EVENT_UPDATED string = "updated"
+	// EVENT_REPLACED holds the whole game, for the updates which are not a sequence of moves on the same board
+	EVENT_REPLACED string = "replaced"
+)
+
+// Event is a change of a game. Events are never changed once stored, so they are the history of the game.
+type Event struct {
+	// Seq is the position of the event in the history of the game, starting at 1
+	Seq  int64  `json:"seq"`
+	Type string `json:"type"`
+	// Version is the version of the game once changed
+	Version int64 `json:"version"`
+	// At is the unix time the event has been stored at
+	At int64 `json:"at"`
+	// Moves are the moves of the update
+	Moves []game.Move `json:"moves,omitempty"`
+	// Squares are the squares changed by the update, bombs and marks included
+	Squares []SquareChange `json:"squares,omitempty"`
+}
+
+// SquareChange is the square of the board at a position once changed
+type SquareChange struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+	board.Square
+}
+
+// storedEvent is an event as it is stored, along with the game it leads to: the whole game for creations and
+// replacements, and the game without its squares and moves for updates. The game is stored at the current schema
+// version, so that it is upgraded when read like the games of any other document storage.
+type storedEvent struct {
+	Event
+	Game json.RawMessage `json:"game"`
+}
+
+// newEvent return the event changing the stored game into the updated one
+func newEvent(stored game.Game, updated game.Game) (storedEvent, error) {
+	if !isSequel(stored, updated) {
+		bytes, err := schema.MarshalGame(updated)
+		if err != nil {
+			return storedEvent{}, err
+		}
+
+		return storedEvent{Event: Event{Type: EVENT_REPLACED, Version: updated.Version}, Game: bytes}, nil
+	}
+
+	event := storedEvent{Event: Event{Type: EVENT_UPDATED, Version: updated.Version}}
+
+	for i := range updated.Board.Squares {
+		for j := range updated.Board.Squares[i] {
+			if updated.Board.Squares[i][j] != stored.Board.Squares[i][j] {
+				event.Squares = append(event.Squares, SquareChange{Row: i, Column: j, Square: updated.Board.Squares[i][j]})
+			}
+		}
+	}
+
+	if len(updated.Moves) > len(stored.Moves) {
+		event.Moves = updated.Moves[len(stored.Moves):]
+	}
+
+	header := updated
+	header.Board.Squares = nil
+	header.Moves = nil
+
+	bytes, err := schema.MarshalGame(header)
+	if err != nil {
+		return storedEvent{}, err
+	}
+
+	event.Game = bytes
+
+	return event, nil
+}
+
+// isSequel whether the updated game is the stored one played further: the same board with the same moves,
+// along with new ones
+func isSequel(stored game.Game, updated game.Game) bool {
+	if len(updated.Board.Squares) != len(stored.Board.Squares) || len(updated.Moves) < len(stored.Moves) {
+		return false
+	}
+
+	for i := range updated.Board.Squares {
+		if len(updated.Board.Squares[i]) != len(stored.Board.Squares[i]) {
+			return false
+		}
+	}
+
+	if len(stored.Moves) > 0 && updated.Moves[len(stored.Moves)-1].ID != stored.Moves[len(stored.Moves)-1].ID {
+		return false
+	}
+
+	return true
+}
+
+// apply return the game once changed by the event
+func apply(g game.Game, event storedEvent) (game.Game, error) {
+	changed, _, err := schema.UnmarshalGame(event.Game)
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	if event.Type != EVENT_UPDATED {
+		return changed, nil
+	}
+
+	changed.Board.Squares = g.Board.Squares
+	for _, square := range event.Squares {
+		changed.Board.Squares[square.Row][square.Column] = square.Square
+	}
+
+	changed.Moves = append(g.Moves, event.Moves...)
+
+	return changed, nil
+}
@@ -0,0 +1,432 @@
+package eventsto
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/localsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/schema"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/prologic/bitcask"
+)
+
+// GameStorageDir is the name of the directory of the database within the data directory
+const GameStorageDir = "minesweeper-events-db"
+
+// DefaultSnapshotInterval is the default number of events between two snapshots of a game
+const DefaultSnapshotInterval = 50
+
+// The database holds three kinds of records per game:
+//
+//	g/<id>        the head of the game: the number of events of its history
+//	e/<id>/<seq>  the events of the game
+//	s/<id>        the latest snapshot of the game along with the event it was taken at
+//
+// The head is written once the event is, so an event is only part of the history once the head counts it.
+// Events left beyond the head by an interrupted write are overwritten by the next update.
+const (
+	headPrefix     = "g/"
+	eventPrefix    = "e/"
+	snapshotPrefix = "s/"
+)
+
+type head struct {
+	Events int64 `json:"events"`
+}
+
+type snapshot struct {
+	Seq  int64           `json:"seq"`
+	Game json.RawMessage `json:"game"`
+}
+
+// GameStorage stores each game as the history of its changes rather than overwriting it on each update. A game is
+// read by replaying its events from its latest snapshot, snapshots being taken every snapshot interval events.
+type GameStorage struct {
+	db               *bitcask.Bitcask
+	snapshotInterval int64
+	// mutex makes the version check and the writes of an update atomic
+	mutex sync.Mutex
+	// compaction is held exclusively while the database is compacted and shared by any other access,
+	// since bitcask does not support accesses during a merge
+	compaction sync.RWMutex
+}
+
+// NewGameStorage opens the game database in the given directory, creating it when missing. A snapshot of a game
+// is taken every snapshotInterval events.
+func NewGameStorage(dir string, snapshotInterval int) (*GameStorage, error) {
+	if snapshotInterval <= 0 {
+		return nil, errors.New(apperrors.InvalidInput, nil, "the snapshot interval must be positive", "")
+	}
+
+	db, err := bitcask.Open(filepath.Join(dir, GameStorageDir))
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "open game database has failed")
+	}
+
+	return &GameStorage{db: db, snapshotInterval: int64(snapshotInterval)}, nil
+}
+
+// Close flushes and closes the database
+func (sto *GameStorage) Close() error {
+	err := sto.db.Close()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "close game database has failed")
+	}
+
+	return nil
+}
+
+// Create starts the history of the game with its creation event. Creating a game again starts its history over.
+func (sto *GameStorage) Create(gameToCreate game.Game) error {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	bytes, err := schema.MarshalGame(gameToCreate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
+	}
+
+	previous, err := sto.head(gameToCreate.ID)
+	if err != nil && !errors.Is(err, apperrors.NotFound) {
+		return err
+	}
+
+	err = sto.remove(snapshotKey(gameToCreate.ID))
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "delete snapshot of game has failed")
+	}
+
+	event := storedEvent{Event: Event{Type: EVENT_CREATED, Version: gameToCreate.Version}, Game: bytes}
+
+	err = sto.append(gameToCreate.ID, head{}, event)
+	if err != nil {
+		return err
+	}
+
+	return sto.deleteEvents(gameToCreate.ID, 2, previous.Events)
+}
+
+// Update appends the change of the game to its history
+func (sto *GameStorage) Update(gameToUpdate game.Game) error {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	storedGame, h, err := sto.load(gameToUpdate.ID, -1)
+	if err != nil {
+		return err
+	}
+
+	if storedGame.Version != gameToUpdate.Version {
+		return errors.New(apperrors.Conflict, nil, "game has been modified", "game version does not match the stored one")
+	}
+
+	gameToUpdate.Version++
+
+	event, err := newEvent(storedGame, gameToUpdate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal event into json has failed")
+	}
+
+	err = sto.append(gameToUpdate.ID, h, event)
+	if err != nil {
+		return err
+	}
+
+	if (h.Events+1)%sto.snapshotInterval == 0 {
+		return sto.snapshot(gameToUpdate, h.Events+1)
+	}
+
+	return nil
+}
+
+func (sto *GameStorage) GetByID(id string) (game.Game, error) {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	g, _, err := sto.load(id, -1)
+
+	return g, err
+}
+
+// GetAt return the game as it was at the given version, by replaying its history from its creation
+func (sto *GameStorage) GetAt(id string, version int64) (game.Game, error) {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	g, _, err := sto.load(id, version)
+
+	return g, err
+}
+
+func (sto *GameStorage) Delete(id string) error {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	h, err := sto.head(id)
+	if err != nil {
+		return err
+	}
+
+	// the game is gone once its head is, its other records are only garbage from then on
+	err = sto.db.Delete(headKey(id))
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "delete game from event storage has failed")
+	}
+
+	err = sto.remove(snapshotKey(id))
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "delete snapshot of game has failed")
+	}
+
+	return sto.deleteEvents(id, 1, h.Events)
+}
+
+// Walk calls fn with the games stored when it is called, but the ones deleted meanwhile
+func (sto *GameStorage) Walk(fn func(g game.Game) error) error {
+	ids := []string{}
+
+	sto.compaction.RLock()
+	err := sto.db.Fold(func(key []byte) error {
+		if strings.HasPrefix(string(key), headPrefix) {
+			ids = append(ids, strings.TrimPrefix(string(key), headPrefix))
+		}
+
+		return nil
+	})
+	sto.compaction.RUnlock()
+
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "fold game keys has failed")
+	}
+
+	for _, id := range ids {
+		g, err := sto.GetByID(id)
+		if errors.Is(err, apperrors.NotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = fn(g)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Compact merges the data files of the database, which reclaims the space of the deleted games
+func (sto *GameStorage) Compact() error {
+	sto.compaction.Lock()
+	defer sto.compaction.Unlock()
+
+	return localsto.Merge(sto.db)
+}
+
+// load return the game at the given version, or its latest version when negative, along with its head.
+// The latest version is replayed from the latest snapshot, while older ones are replayed from the creation.
+// The compaction lock must be held.
+func (sto *GameStorage) load(id string, version int64) (game.Game, head, error) {
+	h, err := sto.head(id)
+	if err != nil {
+		return game.Game{}, head{}, err
+	}
+
+	g := game.Game{}
+	from := int64(1)
+
+	if version < 0 {
+		s, ok, err := sto.latestSnapshot(id)
+		if err != nil {
+			return game.Game{}, head{}, err
+		}
+
+		if ok && s.Seq <= h.Events {
+			g, _, err = schema.UnmarshalGame(s.Game)
+			if err != nil {
+				return game.Game{}, head{}, errors.New(apperrors.Internal, err, "internal error", "unmarshal snapshot of game has failed")
+			}
+
+			from = s.Seq + 1
+		}
+	}
+
+	for seq := from; seq <= h.Events; seq++ {
+		event, err := sto.event(id, seq)
+		if err != nil {
+			return game.Game{}, head{}, err
+		}
+
+		if version >= 0 && event.Version > version {
+			break
+		}
+
+		g, err = apply(g, event)
+		if err != nil {
+			return game.Game{}, head{}, errors.New(apperrors.Internal, err, "internal error", "replay event of game has failed")
+		}
+	}
+
+	if version >= 0 && g.Version != version {
+		return game.Game{}, head{}, errors.New(apperrors.NotFound, nil, "game version has not been found", "game never had the requested version")
+	}
+
+	return g, h, nil
+}
+
+// append stores the event following the given head, then the head counting it
+func (sto *GameStorage) append(id string, h head, event storedEvent) error {
+	event.Seq = h.Events + 1
+	event.At = time.Now().Unix()
+
+	bytes, err := json.Marshal(&event)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal event into json has failed")
+	}
+
+	err = sto.db.Put(eventKey(id, event.Seq), bytes)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "put event into event storage has failed")
+	}
+
+	bytes, err = json.Marshal(head{Events: event.Seq})
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal head into json has failed")
+	}
+
+	err = sto.db.Put(headKey(id), bytes)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "put head into event storage has failed")
+	}
+
+	return nil
+}
+
+// snapshot stores the game as it is once the given event is applied
+func (sto *GameStorage) snapshot(g game.Game, seq int64) error {
+	bytes, err := schema.MarshalGame(g)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
+	}
+
+	bytes, err = json.Marshal(snapshot{Seq: seq, Game: bytes})
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal snapshot into json has failed")
+	}
+
+	err = sto.db.Put(snapshotKey(g.ID), bytes)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "put snapshot into event storage has failed")
+	}
+
+	return nil
+}
+
+func (sto *GameStorage) head(id string) (head, error) {
+	h := head{}
+
+	err := sto.getJSON(headKey(id), &h)
+	if err != nil {
+		return head{}, err
+	}
+
+	return h, nil
+}
+
+func (sto *GameStorage) event(id string, seq int64) (storedEvent, error) {
+	event := storedEvent{}
+
+	err := sto.getJSON(eventKey(id, seq), &event)
+	if errors.Is(err, apperrors.NotFound) {
+		return storedEvent{}, errors.New(apperrors.Internal, err, "internal error", "event of the history of the game is missing")
+	}
+	if err != nil {
+		return storedEvent{}, err
+	}
+
+	return event, nil
+}
+
+func (sto *GameStorage) latestSnapshot(id string) (snapshot, bool, error) {
+	s := snapshot{}
+
+	err := sto.getJSON(snapshotKey(id), &s)
+	if errors.Is(err, apperrors.NotFound) {
+		return snapshot{}, false, nil
+	}
+	if err != nil {
+		return snapshot{}, false, err
+	}
+
+	return s, true, nil
+}
+
+// deleteEvents deletes the events of the game from the first one to the last one, both included
+func (sto *GameStorage) deleteEvents(id string, first int64, last int64) error {
+	for seq := first; seq <= last; seq++ {
+		err := sto.remove(eventKey(id, seq))
+		if err != nil {
+			return errors.New(apperrors.Internal, err, "internal error", "delete event of game has failed")
+		}
+	}
+
+	return nil
+}
+
+// remove deletes the record of the key when there is one
+func (sto *GameStorage) remove(key []byte) error {
+	if !sto.db.Has(key) {
+		return nil
+	}
+
+	return sto.db.Delete(key)
+}
+
+// getJSON decodes the record of the key into v
+func (sto *GameStorage) getJSON(key []byte, v interface{}) error {
+	if !sto.db.Has(key) {
+		return errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in event storage")
+	}
+
+	bytes, err := sto.db.Get(key)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "get record from event storage has failed")
+	}
+
+	err = json.Unmarshal(bytes, v)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "unmarshal record has failed")
+	}
+
+	return nil
+}
+
+func headKey(id string) []byte {
+	return []byte(headPrefix + id)
+}
+
+func eventKey(id string, seq int64) []byte {
+	return []byte(eventPrefix + id + "/" + strconv.FormatInt(seq, 10))
+}
+
+func snapshotKey(id string) []byte {
+	return []byte(snapshotPrefix + id)
+}
@@ -0,0 +1,315 @@
+package eventsto_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/eventsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/storagetest"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/prologic/bitcask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) game.Storage {
+		sto, err := eventsto.NewGameStorage(t.TempDir(), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { sto.Close() })
+
+		return sto
+	})
+}
+
+func TestGameStorage_Compact(t *testing.T) {
+	dir := t.TempDir()
+
+	sto, err := eventsto.NewGameStorage(dir, 2)
+	assert.Nil(t, err)
+
+	versions := history(t, sto)
+	assert.Nil(t, sto.Delete("1"))
+	assert.Nil(t, sto.Create(versions[0]))
+	assert.Nil(t, sto.Compact())
+
+	// the database is still locked once compacted
+	_, err = eventsto.NewGameStorage(dir, 2)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, sto.Close())
+
+	reopened, err := eventsto.NewGameStorage(dir, 2)
+	assert.Nil(t, err)
+	defer reopened.Close()
+
+	g, err := reopened.GetByID("1")
+	assert.Nil(t, err)
+	assert.Equal(t, versions[0], g)
+}
+
+// play return the game once the square has been played or marked, along with the move
+func play(g game.Game, moveType string, pos board.SquarePosition) game.Game {
+	g = g.Clone()
+
+	if moveType == game.MOVE_MARK_SQUARE {
+		g.Board.MarkSquare(pos)
+	} else {
+		_, _ = g.Board.PlaySquare(pos)
+	}
+
+	g.Moves = append(g.Moves, game.Move{
+		ID:                   int64(len(g.Moves) + 1),
+		Type:                 moveType,
+		Position:             pos,
+		Squares:              []game.SquareChange{},
+		Status:               g.Board.Status,
+		RevealedSquaresCount: g.Board.RevealedSquaresCount,
+		At:                   1588291200 + int64(len(g.Moves)),
+	})
+
+	return g
+}
+
+// history stores the game and updates it with a few moves, then a whole new board. It return the game at each
+// of its versions.
+func history(t *testing.T, sto *eventsto.GameStorage) []game.Game {
+	g := storagetest.NewGame("1", "alice", game.MODE_COOP)
+
+	// the bombs are placed beforehand, so that the played square never reveals the marked ones
+	firstMoveDone := true
+	for _, pos := range []board.SquarePosition{{Row: 1, Column: 1}, {Row: 3, Column: 3}, {Row: 0, Column: 4}} {
+		g.Board.Get(pos).Type = board.BOMB
+		*g.Board.BombsPositions = append(*g.Board.BombsPositions, pos)
+	}
+	g.Board.Status = board.STATUS_ON_GOING
+	g.Board.FirstMoveDone = &firstMoveDone
+
+	assert.Nil(t, sto.Create(g))
+
+	versions := []game.Game{g.Clone()}
+
+	for _, move := range []struct {
+		moveType string
+		pos      board.SquarePosition
+	}{
+		{game.MOVE_PLAY_SQUARE, board.SquarePosition{Row: 2, Column: 2}},
+		{game.MOVE_MARK_SQUARE, board.SquarePosition{Row: 0, Column: 0}},
+		{game.MOVE_MARK_SQUARE, board.SquarePosition{Row: 4, Column: 4}},
+		{game.MOVE_MARK_SQUARE, board.SquarePosition{Row: 0, Column: 0}},
+	} {
+		g = play(g, move.moveType, move.pos)
+		assert.Nil(t, sto.Update(g))
+
+		g.Version++
+		versions = append(versions, g.Clone())
+	}
+
+	g = g.Clone()
+	g.Board = board.NewBoard(3, 3, 1)
+	g.Moves = nil
+	assert.Nil(t, sto.Update(g))
+
+	g.Version++
+	versions = append(versions, g.Clone())
+
+	return versions
+}
+
+// events return the events stored for the game, reading the database once the storage is closed
+func events(t *testing.T, dir string, id string) []eventsto.Event {
+	db, err := bitcask.Open(filepath.Join(dir, eventsto.GameStorageDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	bytes, err := db.Get([]byte("g/" + id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := struct {
+		Events int64 `json:"events"`
+	}{}
+	assert.Nil(t, json.Unmarshal(bytes, &h))
+
+	events := []eventsto.Event{}
+	for seq := int64(1); seq <= h.Events; seq++ {
+		bytes, err := db.Get([]byte(fmt.Sprintf("e/%s/%d", id, seq)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		event := eventsto.Event{}
+		assert.Nil(t, json.Unmarshal(bytes, &event))
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestGameStorage_History(t *testing.T) {
+	dir := t.TempDir()
+
+	sto, err := eventsto.NewGameStorage(dir, 2)
+	assert.Nil(t, err)
+
+	versions := history(t, sto)
+
+	for version, expected := range versions {
+		g, err := sto.GetAt("1", int64(version))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, g)
+	}
+
+	g, err := sto.GetByID("1")
+	assert.Nil(t, err)
+	assert.Equal(t, versions[len(versions)-1], g)
+
+	_, err = sto.GetAt("1", 6)
+	assert.True(t, errors.Is(err, apperrors.NotFound))
+
+	_, err = sto.GetAt("2", 0)
+	assert.True(t, errors.Is(err, apperrors.NotFound))
+
+	assert.Nil(t, sto.Close())
+
+	events := events(t, dir, "1")
+	assert.Len(t, events, 6)
+
+	types := []string{}
+	for i, event := range events {
+		types = append(types, event.Type)
+
+		assert.Equal(t, int64(i+1), event.Seq)
+		assert.Equal(t, int64(i), event.Version)
+		assert.NotZero(t, event.At)
+	}
+
+	assert.Equal(t, []string{
+		eventsto.EVENT_CREATED,
+		eventsto.EVENT_UPDATED,
+		eventsto.EVENT_UPDATED,
+		eventsto.EVENT_UPDATED,
+		eventsto.EVENT_UPDATED,
+		eventsto.EVENT_REPLACED,
+	}, types)
+
+	// marking a square changes it alone
+	assert.Equal(t, []eventsto.SquareChange{{Row: 4, Column: 4, Square: versions[3].Board.Squares[4][4]}}, events[3].Squares)
+	assert.Equal(t, versions[3].Moves[2:], events[3].Moves)
+}
+
+func TestGameStorage_Events(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		verify func(t *testing.T, dir string)
+	}{
+		{
+			name:   "snapshot",
+			should: "read the latest version from the latest snapshot",
+			verify: func(t *testing.T, dir string) {
+				sto, err := eventsto.NewGameStorage(dir, 2)
+				assert.Nil(t, err)
+
+				versions := history(t, sto)
+				assert.Nil(t, sto.Close())
+
+				// the events before the latest snapshot, taken at the 6th event, are no longer needed
+				db, err := bitcask.Open(filepath.Join(dir, eventsto.GameStorageDir))
+				assert.Nil(t, err)
+				assert.Nil(t, db.Delete([]byte("e/1/1")))
+				assert.Nil(t, db.Close())
+
+				sto, err = eventsto.NewGameStorage(dir, 2)
+				assert.Nil(t, err)
+				defer sto.Close()
+
+				g, err := sto.GetByID("1")
+				assert.Nil(t, err)
+				assert.Equal(t, versions[len(versions)-1], g)
+
+				_, err = sto.GetAt("1", 0)
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+		{
+			name:   "interrupted update",
+			should: "ignore the event stored beyond the history and overwrite it",
+			verify: func(t *testing.T, dir string) {
+				sto, err := eventsto.NewGameStorage(dir, 10)
+				assert.Nil(t, err)
+
+				g := storagetest.NewGame("1", "", game.MODE_CLASSIC)
+				assert.Nil(t, sto.Create(g))
+				assert.Nil(t, sto.Close())
+
+				db, err := bitcask.Open(filepath.Join(dir, eventsto.GameStorageDir))
+				assert.Nil(t, err)
+				assert.Nil(t, db.Put([]byte("e/1/2"), []byte(`{"seq": 2, "type": "replaced", "version": 1, "game": {}}`)))
+				assert.Nil(t, db.Close())
+
+				sto, err = eventsto.NewGameStorage(dir, 10)
+				assert.Nil(t, err)
+
+				stored, err := sto.GetByID("1")
+				assert.Nil(t, err)
+				assert.Equal(t, g, stored)
+
+				g = play(g, game.MOVE_MARK_SQUARE, board.SquarePosition{Row: 1, Column: 1})
+				assert.Nil(t, sto.Update(g))
+				assert.Nil(t, sto.Close())
+
+				events := events(t, dir, "1")
+				assert.Len(t, events, 2)
+				assert.Equal(t, eventsto.EVENT_UPDATED, events[1].Type)
+			},
+		},
+		{
+			name:   "created again",
+			should: "start the history over",
+			verify: func(t *testing.T, dir string) {
+				sto, err := eventsto.NewGameStorage(dir, 2)
+				assert.Nil(t, err)
+
+				history(t, sto)
+
+				g := storagetest.NewGame("1", "bob", game.MODE_CLASSIC)
+				assert.Nil(t, sto.Create(g))
+
+				stored, err := sto.GetByID("1")
+				assert.Nil(t, err)
+				assert.Equal(t, g, stored)
+
+				_, err = sto.GetAt("1", 1)
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+				assert.Nil(t, sto.Close())
+
+				assert.Len(t, events(t, dir, "1"), 1)
+			},
+		},
+		{
+			name:   "invalid snapshot interval",
+			should: "return an invalid input error",
+			verify: func(t *testing.T, dir string) {
+				_, err := eventsto.NewGameStorage(dir, 0)
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.verify(t, t.TempDir())
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package fakesto
+
+import (
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+
+	"github.com/matiasvarela/minesweeper/internal/daily"
+)
+
+type DailyStorage struct {
+	db     map[string]daily.Entry
+	errors map[string]error
+}
+
+func NewDailyStorage() *DailyStorage {
+	return &DailyStorage{
+		map[string]daily.Entry{},
+		map[string]error{},
+	}
+}
+
+func (sto *DailyStorage) CleanErrors() {
+	sto.errors = map[string]error{}
+}
+
+func (sto *DailyStorage) CleanDB() {
+	sto.db = map[string]daily.Entry{}
+}
+
+func (sto *DailyStorage) AddErrorOnCreate(err error) {
+	sto.errors["on_create"] = err
+}
+
+func (sto *DailyStorage) AddErrorOnGet(err error) {
+	sto.errors["on_get"] = err
+}
+
+func (sto *DailyStorage) AddErrorOnGetByDate(err error) {
+	sto.errors["on_get_by_date"] = err
+}
+
+func (sto *DailyStorage) Create(entry daily.Entry) error {
+	if err, ok := sto.errors["on_create"]; ok {
+		return err
+	}
+
+	sto.db[entry.Date+":"+entry.PlayerID] = entry
+
+	return nil
+}
+
+func (sto *DailyStorage) Get(date string, playerID string) (daily.Entry, error) {
+	if err, ok := sto.errors["on_get"]; ok {
+		return daily.Entry{}, err
+	}
+
+	entry, ok := sto.db[date+":"+playerID]
+	if !ok {
+		return daily.Entry{}, errors.New(apperrors.NotFound, nil, "daily entry has not been found", "daily entry not found in db")
+	}
+
+	return entry, nil
+}
+
+func (sto *DailyStorage) GetByDate(date string) ([]daily.Entry, error) {
+	if err, ok := sto.errors["on_get_by_date"]; ok {
+		return nil, err
+	}
+
+	entries := []daily.Entry{}
+
+	for _, entry := range sto.db {
+		if entry.Date == date {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
@@ -2,52 +2,172 @@ package fakesto
 
 import (
 	"encoding/json"
+	"sync"
+	"time"
+
 	"github.com/matiasvarela/errors"
 	"github.com/matiasvarela/minesweeper/pkg/apperrors"
 
 	"github.com/matiasvarela/minesweeper/internal/game"
 )
 
+const (
+	METHOD_CREATE    string = "create"
+	METHOD_UPDATE    string = "update"
+	METHOD_GET_BY_ID string = "get_by_id"
+	METHOD_DELETE    string = "delete"
+	METHOD_WALK      string = "walk"
+)
+
+// Call is a call made to the game storage along with its arguments and the error it has returned
+type Call struct {
+	Method string
+	ID     string
+	// Game is the game given to Create and Update
+	Game game.Game
+	Err  error
+}
+
+// GameStorage keeps the games in memory. It is safe for concurrent use, and lets tests inject errors,
+// simulate latency and assert the calls it has received.
 type GameStorage struct {
-	db     map[string][]byte
-	errors map[string]error
+	mutex sync.Mutex
+
+	db map[string][]byte
+	// errors are returned by every call of a method, errorsByID by the calls of a method on a game
+	// and errorsByCall by the nth call of a method
+	errors       map[string]error
+	errorsByID   map[string]map[string]error
+	errorsByCall map[string]map[int]error
+
+	calls   []Call
+	counts  map[string]int
+	latency time.Duration
 }
 
 func NewGameStorage() *GameStorage {
 	return &GameStorage{
-		map[string][]byte{},
-		map[string]error{},
+		db:           map[string][]byte{},
+		errors:       map[string]error{},
+		errorsByID:   map[string]map[string]error{},
+		errorsByCall: map[string]map[int]error{},
+		counts:       map[string]int{},
 	}
 }
 
 func (sto *GameStorage) CleanErrors() {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
 	sto.errors = map[string]error{}
+	sto.errorsByID = map[string]map[string]error{}
+	sto.errorsByCall = map[string]map[int]error{}
 }
 
 func (sto *GameStorage) CleanDB() {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
 	sto.db = map[string][]byte{}
 }
 
+// CleanCalls forgets the recorded calls and restarts the count of calls
+func (sto *GameStorage) CleanCalls() {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	sto.calls = nil
+	sto.counts = map[string]int{}
+}
+
 func (sto *GameStorage) AddErrorOnCreate(err error) {
-	sto.errors["on_create"] = err
+	sto.addError(METHOD_CREATE, err)
 }
 
 func (sto *GameStorage) AddErrorOnUpdate(err error) {
-	sto.errors["on_update"] = err
+	sto.addError(METHOD_UPDATE, err)
 }
 
 func (sto *GameStorage) AddErrorOnGetByID(err error) {
-	sto.errors["on_get_by_id"] = err
+	sto.addError(METHOD_GET_BY_ID, err)
+}
+
+func (sto *GameStorage) AddErrorOnDelete(err error) {
+	sto.addError(METHOD_DELETE, err)
+}
+
+func (sto *GameStorage) addError(method string, err error) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	sto.errors[method] = err
+}
+
+// AddErrorOnID makes every call of the method on the given game fail with the given error
+func (sto *GameStorage) AddErrorOnID(method string, id string, err error) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	if sto.errorsByID[method] == nil {
+		sto.errorsByID[method] = map[string]error{}
+	}
+
+	sto.errorsByID[method][id] = err
+}
+
+// AddErrorOnCall makes the nth call of the method from now on fail with the given error, n starting at 1
+func (sto *GameStorage) AddErrorOnCall(method string, n int, err error) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	if sto.errorsByCall[method] == nil {
+		sto.errorsByCall[method] = map[int]error{}
+	}
+
+	sto.errorsByCall[method][sto.counts[method]+n] = err
+}
+
+// SetLatency makes every call wait the given duration before being served
+func (sto *GameStorage) SetLatency(latency time.Duration) {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	sto.latency = latency
+}
+
+// Calls return the calls received so far, in order
+func (sto *GameStorage) Calls() []Call {
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	return append([]Call{}, sto.calls...)
+}
+
+// CallsOf return the calls of the given method received so far, in order
+func (sto *GameStorage) CallsOf(method string) []Call {
+	calls := []Call{}
+
+	for _, call := range sto.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
 }
 
 func (sto *GameStorage) Create(gameToCreate game.Game) error {
-	if err, ok := sto.errors["on_create"]; ok {
-		return err
+	call := sto.begin(METHOD_CREATE, gameToCreate.ID, gameToCreate)
+	defer sto.end(call)
+
+	if call.Err != nil {
+		return call.Err
 	}
 
 	bytes, err := json.Marshal(&gameToCreate)
 	if err != nil {
-		return errors.New(apperrors.Internal,err,"internal error", "marshal game struct into json has failed")
+		call.Err = errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
+		return call.Err
 	}
 
 	sto.db[gameToCreate.ID] = bytes
@@ -56,17 +176,30 @@ func (sto *GameStorage) Create(gameToCreate game.Game) error {
 }
 
 func (sto *GameStorage) Update(gameToUpdate game.Game) error {
-	if err, ok := sto.errors["on_update"]; ok {
+	call := sto.begin(METHOD_UPDATE, gameToUpdate.ID, gameToUpdate)
+	defer sto.end(call)
+
+	if call.Err != nil {
+		return call.Err
+	}
+
+	storedGame, err := sto.get(gameToUpdate.ID)
+	if err != nil {
+		call.Err = err
 		return err
 	}
 
-	if _, ok := sto.db[gameToUpdate.ID]; !ok {
-		return errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in db")
+	if storedGame.Version != gameToUpdate.Version {
+		call.Err = errors.New(apperrors.Conflict, nil, "game has been modified", "game version does not match the stored one")
+		return call.Err
 	}
 
+	gameToUpdate.Version++
+
 	bytes, err := json.Marshal(&gameToUpdate)
 	if err != nil {
-		return errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
+		call.Err = errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
+		return call.Err
 	}
 
 	sto.db[gameToUpdate.ID] = bytes
@@ -75,22 +208,120 @@ func (sto *GameStorage) Update(gameToUpdate game.Game) error {
 }
 
 func (sto *GameStorage) GetByID(id string) (game.Game, error) {
-	if err, ok := sto.errors["on_get_by_id"]; ok {
-		return game.Game{}, err
+	call := sto.begin(METHOD_GET_BY_ID, id, game.Game{})
+	defer sto.end(call)
+
+	if call.Err != nil {
+		return game.Game{}, call.Err
+	}
+
+	requestedGame, err := sto.get(id)
+	call.Err = err
+
+	return requestedGame, err
+}
+
+func (sto *GameStorage) Delete(id string) error {
+	call := sto.begin(METHOD_DELETE, id, game.Game{})
+	defer sto.end(call)
+
+	if call.Err != nil {
+		return call.Err
 	}
 
 	if _, ok := sto.db[id]; !ok {
-		return game.Game{}, errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in db")
+		call.Err = errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in db")
+		return call.Err
+	}
+
+	delete(sto.db, id)
+
+	return nil
+}
+
+// Walk calls fn with the games stored when it is called, fn being free to use the storage
+func (sto *GameStorage) Walk(fn func(g game.Game) error) error {
+	call := sto.begin(METHOD_WALK, "", game.Game{})
+
+	games := []game.Game{}
+
+	for id := range sto.db {
+		if call.Err != nil {
+			break
+		}
+
+		g, err := sto.get(id)
+		if err != nil {
+			call.Err = err
+			break
+		}
+
+		games = append(games, g)
 	}
 
-	bytes := sto.db[id]
+	sto.end(call)
+
+	if call.Err != nil {
+		return call.Err
+	}
+
+	for _, g := range games {
+		err := fn(g)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// begin waits for the latency, then locks the storage and counts the call. The returned call holds
+// the injected error, if any.
+func (sto *GameStorage) begin(method string, id string, g game.Game) *Call {
+	sto.mutex.Lock()
+	latency := sto.latency
+	sto.mutex.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	sto.mutex.Lock()
+
+	sto.counts[method]++
+
+	call := &Call{Method: method, ID: id, Game: g}
+
+	if err, ok := sto.errorsByCall[method][sto.counts[method]]; ok {
+		call.Err = err
+	} else if err, ok := sto.errorsByID[method][id]; ok {
+		call.Err = err
+	} else if err, ok := sto.errors[method]; ok {
+		call.Err = err
+	}
+
+	return call
+}
+
+// end records the call and unlocks the storage
+func (sto *GameStorage) end(call *Call) {
+	sto.calls = append(sto.calls, *call)
+	sto.mutex.Unlock()
+}
+
+// get return the stored game, the storage must be locked
+func (sto *GameStorage) get(id string) (game.Game, error) {
+	bytes, ok := sto.db[id]
+	if !ok {
+		return game.Game{}, errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in db")
+	}
 
 	requestedGame := game.Game{}
 
 	err := json.Unmarshal(bytes, &requestedGame)
 	if err != nil {
-		return game.Game{}, errors.New(apperrors.Internal, err,"internal error", "unmarshal game into struct has failed")
+		return game.Game{}, errors.New(apperrors.Internal, err, "internal error", "unmarshal game into struct has failed")
 	}
 
 	return requestedGame, nil
-}
\ No newline at end of file
+}
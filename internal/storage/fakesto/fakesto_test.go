@@ -0,0 +1,125 @@
+package fakesto_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/internal/storage/storagetest"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) game.Storage {
+		return fakesto.NewGameStorage()
+	})
+}
+
+func TestGameStorage_Fakes(t *testing.T) {
+	failure := errors.New(apperrors.Internal, nil, "fail", "")
+
+	tests := []struct {
+		name   string
+		should string
+		mock   func(sto *fakesto.GameStorage)
+		verify func(t *testing.T, sto *fakesto.GameStorage)
+	}{
+		{
+			name:   "error on a method",
+			should: "fail every call of the method",
+			mock: func(sto *fakesto.GameStorage) {
+				sto.AddErrorOnGetByID(failure)
+			},
+			verify: func(t *testing.T, sto *fakesto.GameStorage) {
+				_, err := sto.GetByID("1")
+				assert.Equal(t, failure, err)
+
+				_, err = sto.GetByID("2")
+				assert.Equal(t, failure, err)
+
+				assert.Nil(t, sto.Update(storagetest.NewGame("1", "", game.MODE_CLASSIC)))
+			},
+		},
+		{
+			name:   "error on a game",
+			should: "fail the calls of the method on the game only",
+			mock: func(sto *fakesto.GameStorage) {
+				sto.AddErrorOnID(fakesto.METHOD_GET_BY_ID, "2", failure)
+			},
+			verify: func(t *testing.T, sto *fakesto.GameStorage) {
+				_, err := sto.GetByID("1")
+				assert.Nil(t, err)
+
+				_, err = sto.GetByID("2")
+				assert.Equal(t, failure, err)
+			},
+		},
+		{
+			name:   "error on a call",
+			should: "fail the nth call of the method only",
+			mock: func(sto *fakesto.GameStorage) {
+				sto.AddErrorOnCall(fakesto.METHOD_UPDATE, 2, failure)
+			},
+			verify: func(t *testing.T, sto *fakesto.GameStorage) {
+				g, _ := sto.GetByID("1")
+				assert.Nil(t, sto.Update(g))
+
+				g, _ = sto.GetByID("1")
+				assert.Equal(t, failure, sto.Update(g))
+				assert.Nil(t, sto.Update(g))
+			},
+		},
+		{
+			name:   "calls",
+			should: "record every call along with its arguments and result",
+			mock:   func(sto *fakesto.GameStorage) {},
+			verify: func(t *testing.T, sto *fakesto.GameStorage) {
+				g, _ := sto.GetByID("1")
+				sto.Update(g)
+				sto.Update(g)
+
+				calls := sto.Calls()
+				assert.Equal(t, 3, len(calls))
+				assert.Equal(t, fakesto.Call{Method: fakesto.METHOD_GET_BY_ID, ID: "1"}, calls[0])
+				assert.Equal(t, fakesto.Call{Method: fakesto.METHOD_UPDATE, ID: "1", Game: g}, calls[1])
+				assert.Equal(t, fakesto.METHOD_UPDATE, calls[2].Method)
+				assert.True(t, errors.Is(calls[2].Err, apperrors.Conflict))
+
+				assert.Equal(t, calls[1:], sto.CallsOf(fakesto.METHOD_UPDATE))
+
+				sto.CleanCalls()
+				assert.Empty(t, sto.Calls())
+			},
+		},
+		{
+			name:   "latency",
+			should: "delay every call",
+			mock: func(sto *fakesto.GameStorage) {
+				sto.SetLatency(20 * time.Millisecond)
+			},
+			verify: func(t *testing.T, sto *fakesto.GameStorage) {
+				start := time.Now()
+
+				_, err := sto.GetByID("1")
+				assert.Nil(t, err)
+				assert.True(t, time.Since(start) >= 20*time.Millisecond)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sto := fakesto.NewGameStorage()
+			sto.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC))
+			sto.Create(storagetest.NewGame("2", "", game.MODE_CLASSIC))
+			sto.CleanCalls()
+
+			tt.mock(sto)
+
+			tt.verify(t, sto)
+		})
+	}
+}
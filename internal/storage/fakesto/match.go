@@ -0,0 +1,96 @@
+package fakesto
+
+import (
+	"encoding/json"
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+
+	"github.com/matiasvarela/minesweeper/internal/match"
+)
+
+type MatchStorage struct {
+	db     map[string][]byte
+	errors map[string]error
+}
+
+func NewMatchStorage() *MatchStorage {
+	return &MatchStorage{
+		map[string][]byte{},
+		map[string]error{},
+	}
+}
+
+func (sto *MatchStorage) CleanErrors() {
+	sto.errors = map[string]error{}
+}
+
+func (sto *MatchStorage) CleanDB() {
+	sto.db = map[string][]byte{}
+}
+
+func (sto *MatchStorage) AddErrorOnCreate(err error) {
+	sto.errors["on_create"] = err
+}
+
+func (sto *MatchStorage) AddErrorOnUpdate(err error) {
+	sto.errors["on_update"] = err
+}
+
+func (sto *MatchStorage) AddErrorOnGetByID(err error) {
+	sto.errors["on_get_by_id"] = err
+}
+
+func (sto *MatchStorage) Create(matchToCreate match.Match) error {
+	if err, ok := sto.errors["on_create"]; ok {
+		return err
+	}
+
+	bytes, err := json.Marshal(&matchToCreate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal match struct into json has failed")
+	}
+
+	sto.db[matchToCreate.ID] = bytes
+
+	return nil
+}
+
+func (sto *MatchStorage) Update(matchToUpdate match.Match) error {
+	if err, ok := sto.errors["on_update"]; ok {
+		return err
+	}
+
+	if _, ok := sto.db[matchToUpdate.ID]; !ok {
+		return errors.New(apperrors.NotFound, nil, "match has not been found", "match not found in db")
+	}
+
+	bytes, err := json.Marshal(&matchToUpdate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal match struct into json has failed")
+	}
+
+	sto.db[matchToUpdate.ID] = bytes
+
+	return nil
+}
+
+func (sto *MatchStorage) GetByID(id string) (match.Match, error) {
+	if err, ok := sto.errors["on_get_by_id"]; ok {
+		return match.Match{}, err
+	}
+
+	if _, ok := sto.db[id]; !ok {
+		return match.Match{}, errors.New(apperrors.NotFound, nil, "match has not been found", "match not found in db")
+	}
+
+	bytes := sto.db[id]
+
+	requestedMatch := match.Match{}
+
+	err := json.Unmarshal(bytes, &requestedMatch)
+	if err != nil {
+		return match.Match{}, errors.New(apperrors.Internal, err, "internal error", "unmarshal match into struct has failed")
+	}
+
+	return requestedMatch, nil
+}
@@ -0,0 +1,104 @@
+package localsto
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/prologic/bitcask"
+)
+
+// DailyStorageDir is the name of the directory of the database within the data directory
+const DailyStorageDir = "minesweeper-api-daily-db"
+
+type DailyStorage struct {
+	db *bitcask.Bitcask
+}
+
+// NewDailyStorage opens the daily database in the given directory, creating it when missing
+func NewDailyStorage(dir string) (*DailyStorage, error) {
+	db, err := bitcask.Open(filepath.Join(dir, DailyStorageDir))
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "open daily database has failed")
+	}
+
+	return &DailyStorage{db}, nil
+}
+
+// Close flushes and closes the database
+func (sto *DailyStorage) Close() error {
+	err := sto.db.Close()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "close daily database has failed")
+	}
+
+	return nil
+}
+
+func (sto *DailyStorage) Create(entry daily.Entry) error {
+	bytes, err := json.Marshal(&entry)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal daily entry struct into json has failed")
+	}
+
+	err = sto.db.Put(dailyKey(entry.Date, entry.PlayerID), bytes)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "put daily entry into memory storage has failed")
+	}
+
+	return nil
+}
+
+func (sto *DailyStorage) Get(date string, playerID string) (daily.Entry, error) {
+	key := dailyKey(date, playerID)
+
+	has := sto.db.Has(key)
+	if !has {
+		return daily.Entry{}, errors.New(apperrors.NotFound, nil, "daily entry has not been found", "daily entry not found in memory storage")
+	}
+
+	return sto.get(key)
+}
+
+func (sto *DailyStorage) GetByDate(date string) ([]daily.Entry, error) {
+	entries := []daily.Entry{}
+
+	err := sto.db.Scan([]byte(date+":"), func(key []byte) error {
+		entry, err := sto.get(key)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "scan daily entries from memory storage has failed")
+	}
+
+	return entries, nil
+}
+
+func (sto *DailyStorage) get(key []byte) (daily.Entry, error) {
+	bytes, err := sto.db.Get(key)
+	if err != nil {
+		return daily.Entry{}, errors.New(apperrors.Internal, err, "internal error", "get daily entry from memory storage has failed")
+	}
+
+	entry := daily.Entry{}
+
+	err = json.Unmarshal(bytes, &entry)
+	if err != nil {
+		return daily.Entry{}, errors.New(apperrors.Internal, err, "internal error", "unmarshal daily entry into struct has failed")
+	}
+
+	return entry, nil
+}
+
+func dailyKey(date string, playerID string) []byte {
+	return []byte(date + ":" + playerID)
+}
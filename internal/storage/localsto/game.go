@@ -1,29 +1,53 @@
 package localsto
 
 import (
-	"encoding/json"
+	"path/filepath"
+	"sync"
 	"github.com/matiasvarela/errors"
 	"github.com/matiasvarela/minesweeper/pkg/apperrors"
 
 	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/schema"
 	"github.com/prologic/bitcask"
 )
 
+// GameStorageDir is the name of the directory of the database within the data directory
+const GameStorageDir = "minesweeper-api-db"
+
 type GameStorage struct {
 	db *bitcask.Bitcask
+	// mutex makes the version check and the write of an update atomic
+	mutex sync.Mutex
+	// compaction is held exclusively while the database is compacted and shared by any other access,
+	// since bitcask does not support accesses during a merge
+	compaction sync.RWMutex
+}
+
+// NewGameStorage opens the game database in the given directory, creating it when missing
+func NewGameStorage(dir string) (*GameStorage, error) {
+	db, err := bitcask.Open(filepath.Join(dir, GameStorageDir))
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "open game database has failed")
+	}
+
+	return &GameStorage{db: db}, nil
 }
 
-func NewGameStorage() *GameStorage {
-	db, err := bitcask.Open("/tmp/minesweeper-api-db")
+// Close flushes and closes the database
+func (sto *GameStorage) Close() error {
+	err := sto.db.Close()
 	if err != nil {
-		panic(err)
+		return errors.New(apperrors.Internal, err, "internal error", "close game database has failed")
 	}
 
-	return &GameStorage{db}
+	return nil
 }
 
 func (sto *GameStorage) Create(gameToCreate game.Game) error {
-	bytes, err := json.Marshal(&gameToCreate)
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	bytes, err := marshalGame(gameToCreate)
 	if err != nil {
 		return errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
 	}
@@ -37,12 +61,24 @@ func (sto *GameStorage) Create(gameToCreate game.Game) error {
 }
 
 func (sto *GameStorage) Update(gameToUpdate game.Game) error {
-	has := sto.db.Has([]byte(gameToUpdate.ID))
-	if !has {
-		return errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in memory storage")
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	storedGame, err := sto.get(gameToUpdate.ID)
+	if err != nil {
+		return err
+	}
+
+	if storedGame.Version != gameToUpdate.Version {
+		return errors.New(apperrors.Conflict, nil, "game has been modified", "game version does not match the stored one")
 	}
 
-	bytes, err := json.Marshal(&gameToUpdate)
+	gameToUpdate.Version++
+
+	bytes, err := marshalGame(gameToUpdate)
 	if err != nil {
 		return errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
 	}
@@ -56,22 +92,167 @@ func (sto *GameStorage) Update(gameToUpdate game.Game) error {
 }
 
 func (sto *GameStorage) GetByID(id string) (game.Game, error) {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	return sto.get(id)
+}
+
+func (sto *GameStorage) Delete(id string) error {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	// deletes are serialized with updates, so that a game being updated is not stored again once deleted
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	if !sto.db.Has([]byte(id)) {
+		return errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in memory storage")
+	}
+
+	err := sto.db.Delete([]byte(id))
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "delete game from memory storage has failed")
+	}
+
+	return nil
+}
+
+// Walk calls fn with the games stored when it is called, but the ones deleted meanwhile
+func (sto *GameStorage) Walk(fn func(g game.Game) error) error {
+	ids, err := sto.ids()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		g, err := sto.GetByID(id)
+		if errors.Is(err, apperrors.NotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = fn(g)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Migrate stores again the games stored at an older schema version, upgraded to the current one, and return how many
+// have been upgraded. Games are upgraded when read anyway, so it only saves upgrading them again on every read.
+func (sto *GameStorage) Migrate() (int, error) {
+	ids, err := sto.ids()
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+
+	for _, id := range ids {
+		upgraded, err := sto.migrate(id)
+		if err != nil {
+			return migrated, err
+		}
+
+		if upgraded {
+			migrated++
+		}
+	}
+
+	return migrated, nil
+}
+
+// migrate stores again the game when it is outdated, keeping its version since the game itself is unchanged
+func (sto *GameStorage) migrate(id string) (bool, error) {
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	sto.mutex.Lock()
+	defer sto.mutex.Unlock()
+
+	g, outdated, err := sto.load(id)
+	if errors.Is(err, apperrors.NotFound) || (err == nil && !outdated) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	bytes, err := marshalGame(g)
+	if err != nil {
+		return false, errors.New(apperrors.Internal, err, "internal error", "marshal game struct into json has failed")
+	}
+
+	err = sto.db.Put([]byte(id), bytes)
+	if err != nil {
+		return false, errors.New(apperrors.Internal, err, "internal error", "save game into memory storage has failed")
+	}
+
+	return true, nil
+}
+
+// Compact merges the data files of the database, which reclaims the space of the deleted and overwritten games
+func (sto *GameStorage) Compact() error {
+	sto.compaction.Lock()
+	defer sto.compaction.Unlock()
+
+	return Merge(sto.db)
+}
+
+// ids return the ids of the stored games. The games are read afterwards, since the database cannot be read
+// while its keys are folded.
+func (sto *GameStorage) ids() ([]string, error) {
+	ids := []string{}
+
+	sto.compaction.RLock()
+	defer sto.compaction.RUnlock()
+
+	err := sto.db.Fold(func(key []byte) error {
+		ids = append(ids, string(key))
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "fold game keys has failed")
+	}
+
+	return ids, nil
+}
+
+// get return the stored game, the compaction lock must be held
+func (sto *GameStorage) get(id string) (game.Game, error) {
+	requestedGame, _, err := sto.load(id)
+
+	return requestedGame, err
+}
+
+// load return the stored game, upgraded to the current schema version, along with whether it was stored at an
+// older version. The compaction lock must be held.
+func (sto *GameStorage) load(id string) (game.Game, bool, error) {
 	has := sto.db.Has([]byte(id))
 	if !has {
-		return game.Game{}, errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in memory storage")
+		return game.Game{}, false, errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in memory storage")
 	}
 
 	bytes, err := sto.db.Get([]byte(id))
 	if err != nil {
-		return game.Game{}, errors.New(apperrors.Internal, err, "internal error", "get game by id from memory storage has failed")
+		return game.Game{}, false, errors.New(apperrors.Internal, err, "internal error", "get game by id from memory storage has failed")
 	}
 
-	requestedGame := game.Game{}
-
-	err = json.Unmarshal(bytes, &requestedGame)
+	requestedGame, outdated, err := schema.UnmarshalGame(bytes)
 	if err != nil {
-		return game.Game{}, errors.New(apperrors.Internal, err,"internal error", "unmarshal game into struct has failed")
+		return game.Game{}, false, errors.New(apperrors.Internal, err, "internal error", "unmarshal game into struct has failed")
 	}
 
-	return requestedGame, nil
+	return requestedGame, outdated, nil
+}
+
+// marshalGame encode the game to be stored at the current schema version
+func marshalGame(g game.Game) ([]byte, error) {
+	return schema.MarshalGame(g)
 }
@@ -0,0 +1,121 @@
+package localsto_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/localsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/storagetest"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/prologic/bitcask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) game.Storage {
+		sto, err := localsto.NewGameStorage(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { sto.Close() })
+
+		return sto
+	})
+}
+
+func TestGameStorage_Compact(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		verify func(t *testing.T, dir string)
+	}{
+		{
+			name:   "reopen",
+			should: "keep the games and lock the database once compacted",
+			verify: func(t *testing.T, dir string) {
+				sto, err := localsto.NewGameStorage(dir)
+				assert.Nil(t, err)
+
+				assert.Nil(t, sto.Create(storagetest.NewGame("1", "", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Create(storagetest.NewGame("2", "", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Delete("2"))
+				assert.Nil(t, sto.Compact())
+
+				_, err = localsto.NewGameStorage(dir)
+				assert.NotNil(t, err)
+
+				assert.Nil(t, sto.Close())
+
+				reopened, err := localsto.NewGameStorage(dir)
+				assert.Nil(t, err)
+				defer reopened.Close()
+
+				_, err = reopened.GetByID("1")
+				assert.Nil(t, err)
+
+				_, err = reopened.GetByID("2")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.verify(t, t.TempDir())
+		})
+	}
+}
+
+func TestGameStorage_Migrate(t *testing.T) {
+	dir := t.TempDir()
+
+	// the games are stored as they were by the older releases, one per schema version
+	db, err := bitcask.Open(filepath.Join(dir, localsto.GameStorageDir))
+	assert.Nil(t, err)
+
+	versions := []string{"v1", "v2", "v3", "v4"}
+	for _, version := range versions {
+		bytes, err := os.ReadFile(filepath.Join("..", "schema", "testdata", "game_"+version+".json"))
+		assert.Nil(t, err)
+		assert.Nil(t, db.Put([]byte(version), bytes))
+	}
+
+	assert.Nil(t, db.Close())
+
+	sto, err := localsto.NewGameStorage(dir)
+	assert.Nil(t, err)
+	defer sto.Close()
+
+	read := map[string]game.Game{}
+	for _, version := range versions {
+		g, err := sto.GetByID(version)
+		assert.Nil(t, err)
+		assert.Equal(t, version, g.ID)
+		assert.NotEmpty(t, g.Mode)
+		assert.Len(t, g.Board.Squares, 3)
+		assert.NotZero(t, g.CreatedAt)
+
+		read[version] = g
+	}
+
+	migrated, err := sto.Migrate()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, migrated)
+
+	migrated, err = sto.Migrate()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, migrated)
+
+	for _, version := range versions {
+		g, err := sto.GetByID(version)
+		assert.Nil(t, err)
+		assert.Equal(t, read[version], g)
+	}
+
+	// the version of the games is kept, so that concurrent updates still succeed
+	assert.Nil(t, sto.Update(read["v2"]))
+}
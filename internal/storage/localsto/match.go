@@ -0,0 +1,93 @@
+package localsto
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+
+	"github.com/matiasvarela/minesweeper/internal/match"
+	"github.com/prologic/bitcask"
+)
+
+// MatchStorageDir is the name of the directory of the database within the data directory
+const MatchStorageDir = "minesweeper-api-match-db"
+
+type MatchStorage struct {
+	db *bitcask.Bitcask
+}
+
+// NewMatchStorage opens the match database in the given directory, creating it when missing
+func NewMatchStorage(dir string) (*MatchStorage, error) {
+	db, err := bitcask.Open(filepath.Join(dir, MatchStorageDir))
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "open match database has failed")
+	}
+
+	return &MatchStorage{db}, nil
+}
+
+// Close flushes and closes the database
+func (sto *MatchStorage) Close() error {
+	err := sto.db.Close()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "close match database has failed")
+	}
+
+	return nil
+}
+
+func (sto *MatchStorage) Create(matchToCreate match.Match) error {
+	bytes, err := json.Marshal(&matchToCreate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal match struct into json has failed")
+	}
+
+	err = sto.db.Put([]byte(matchToCreate.ID), bytes)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "put match into memory storage has failed")
+	}
+
+	return nil
+}
+
+func (sto *MatchStorage) Update(matchToUpdate match.Match) error {
+	has := sto.db.Has([]byte(matchToUpdate.ID))
+	if !has {
+		return errors.New(apperrors.NotFound, nil, "match has not been found", "match not found in memory storage")
+	}
+
+	bytes, err := json.Marshal(&matchToUpdate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal match struct into json has failed")
+	}
+
+	err = sto.db.Put([]byte(matchToUpdate.ID), bytes)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "save match into memory storage has failed")
+	}
+
+	return nil
+}
+
+func (sto *MatchStorage) GetByID(id string) (match.Match, error) {
+	has := sto.db.Has([]byte(id))
+	if !has {
+		return match.Match{}, errors.New(apperrors.NotFound, nil, "match has not been found", "match not found in memory storage")
+	}
+
+	bytes, err := sto.db.Get([]byte(id))
+	if err != nil {
+		return match.Match{}, errors.New(apperrors.Internal, err, "internal error", "get match by id from memory storage has failed")
+	}
+
+	requestedMatch := match.Match{}
+
+	err = json.Unmarshal(bytes, &requestedMatch)
+	if err != nil {
+		return match.Match{}, errors.New(apperrors.Internal, err, "internal error", "unmarshal match into struct has failed")
+	}
+
+	return requestedMatch, nil
+}
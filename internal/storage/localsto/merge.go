@@ -0,0 +1,28 @@
+package localsto
+
+import (
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/prologic/bitcask"
+)
+
+// Merge merges the data files of the database, which reclaims the space of the deleted and overwritten records.
+// The merge closes and reopens the database, which releases its lock: the lock is taken again, failing when
+// another process has opened the database in the meantime, since both would then write to it.
+func Merge(db *bitcask.Bitcask) error {
+	err := db.Merge()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "merge database has failed")
+	}
+
+	locked, err := db.Flock.TryLock()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "lock database has failed")
+	}
+
+	if !locked {
+		return errors.New(apperrors.Internal, bitcask.ErrDatabaseLocked, "internal error", "database has been locked by another process while merged")
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package pgsto
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+
+	// registers the pgx driver as "pgx"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Config is the connection to the database along with the configuration of its pool. Zero values keep
+// the defaults of database/sql.
+type Config struct {
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// migrationsLock is the key of the advisory lock held while migrating, so that instances started
+// at the same time do not apply the same migration twice
+const migrationsLock = 7357001
+
+// migrations are the changes of the schema, in order. They are applied at startup and must never be edited
+// once released: changes are made by appending a new migration.
+var migrations = []string{
+	`CREATE TABLE games (
+		seq                    BIGINT GENERATED ALWAYS AS IDENTITY,
+		id                     TEXT PRIMARY KEY,
+		mode                   TEXT NOT NULL,
+		owner_id               TEXT NOT NULL,
+		status                 TEXT NOT NULL,
+		board_status           TEXT NOT NULL,
+		turn                   TEXT NOT NULL,
+		winner                 TEXT NOT NULL,
+		board_rows             INTEGER NOT NULL,
+		board_columns          INTEGER NOT NULL,
+		bombs                  INTEGER NOT NULL,
+		revealed_squares_count INTEGER NOT NULL,
+		players                JSONB NOT NULL,
+		moves                  JSONB NOT NULL,
+		board                  JSONB NOT NULL,
+		version                BIGINT NOT NULL,
+		started_at             BIGINT NOT NULL,
+		finished_at            BIGINT NOT NULL,
+		elapsed_time           BIGINT NOT NULL,
+		created_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at             TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE INDEX games_owner_id ON games (owner_id, created_at);
+	CREATE INDEX games_status ON games (status, created_at);
+	CREATE INDEX games_board_status ON games (board_status, created_at);
+	CREATE INDEX games_created_at ON games (created_at, seq);
+	CREATE INDEX games_players ON games USING GIN (players jsonb_path_ops);`,
+	// created_at is the time the row was inserted, game_created_at the creation time of the game
+	`ALTER TABLE games ADD COLUMN game_created_at BIGINT NOT NULL DEFAULT 0;
+	CREATE INDEX games_seq ON games (seq);`,
+	// the games stored without creation time were created when they started
+	`UPDATE games SET game_created_at = started_at WHERE game_created_at = 0;`,
+}
+
+// Open connects to the database with the given configuration and applies the pending migrations
+func Open(config Config) (*sql.DB, error) {
+	db, err := sql.Open("pgx", config.DSN)
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "open postgres database has failed")
+	}
+
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	if config.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(config.MaxIdleConns)
+	}
+
+	err = migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migrate applies the migrations that have not been applied yet, each one within its own transaction
+func migrate(db *sql.DB) error {
+	ctx := context.Background()
+
+	// the advisory lock belongs to the session, so the whole migration runs on the same connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "connect to postgres has failed")
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLock)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "lock schema migrations has failed")
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationsLock)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT now())`)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "create schema migrations table has failed")
+	}
+
+	var current int
+
+	err = conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "get schema version has failed")
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return errors.New(apperrors.Internal, err, "internal error", "begin migration transaction has failed")
+		}
+
+		_, err = tx.Exec(migrations[version-1])
+		if err == nil {
+			_, err = tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, version)
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return errors.New(apperrors.Internal, err, "internal error", "apply schema migration has failed")
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return errors.New(apperrors.Internal, err, "internal error", "commit schema migration has failed")
+		}
+	}
+
+	return nil
+}
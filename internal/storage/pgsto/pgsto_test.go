@@ -0,0 +1,156 @@
+package pgsto_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/pgsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// DSNEnv is the environment variable holding the connection string of the database the integration tests run
+// against, they are skipped when it is not set or the database is not available
+const DSNEnv = "MINESWEEPER_TEST_POSTGRES_DSN"
+
+// newConfig return the configuration of a connection to an empty schema of the test database, which is dropped
+// once the test finishes
+func newConfig(t *testing.T) pgsto.Config {
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skip(DSNEnv + " is not set")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Skip("postgres is not available: " + err.Error())
+	}
+
+	if err = admin.Ping(); err != nil {
+		admin.Close()
+		t.Skip("postgres is not available: " + err.Error())
+	}
+
+	schema := fmt.Sprintf("minesweeper_test_%d", time.Now().UnixNano())
+
+	_, err = admin.Exec(`CREATE SCHEMA ` + schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		admin.Close()
+	})
+
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+
+		return pgsto.Config{DSN: dsn + separator + "search_path=" + schema, MaxOpenConns: 5}
+	}
+
+	return pgsto.Config{DSN: dsn + " search_path=" + schema, MaxOpenConns: 5}
+}
+
+func newStorage(t *testing.T, config pgsto.Config) *pgsto.GameStorage {
+	sto, err := pgsto.NewGameStorage(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { sto.Close() })
+
+	return sto
+}
+
+func TestGameStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) game.Storage {
+		return newStorage(t, newConfig(t))
+	})
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		verify func(t *testing.T, sto *pgsto.GameStorage, config pgsto.Config)
+	}{
+		{
+			name:   "reopen",
+			should: "keep the games and not apply the migrations again",
+			verify: func(t *testing.T, sto *pgsto.GameStorage, config pgsto.Config) {
+				assert.Nil(t, sto.Create(storagetest.NewGame("123", "", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Close())
+
+				reopened, err := pgsto.NewGameStorage(config)
+				assert.Nil(t, err)
+				defer reopened.Close()
+
+				_, err = reopened.GetByID("123")
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:   "concurrent startups",
+			should: "apply the migrations once",
+			verify: func(t *testing.T, sto *pgsto.GameStorage, config pgsto.Config) {
+				var wg sync.WaitGroup
+
+				for i := 0; i < 3; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+
+						other, err := pgsto.NewGameStorage(config)
+						assert.Nil(t, err)
+
+						if err == nil {
+							other.Close()
+						}
+					}()
+				}
+
+				wg.Wait()
+
+				assert.Nil(t, sto.Create(storagetest.NewGame("123", "", game.MODE_CLASSIC)))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newConfig(t)
+			sto := newStorage(t, config)
+
+			tt.verify(t, sto, config)
+		})
+	}
+}
+
+func TestCreationTime(t *testing.T) {
+	storagetest.RunCreationTime(t, func(t *testing.T, g game.Game) game.Storage {
+		config := newConfig(t)
+
+		sto := newStorage(t, config)
+		assert.Nil(t, sto.Create(g))
+		assert.Nil(t, sto.Close())
+
+		// the migration recording the creation time of the games is applied again on the next start
+		db, err := pgsto.Open(config)
+		assert.Nil(t, err)
+		_, err = db.Exec(`DELETE FROM schema_migrations WHERE version = 3`)
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+
+		return newStorage(t, config)
+	})
+}
@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"encoding/json"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// Games are the migrations of the stored games. The versions are:
+//
+//  1. the games stored before the game modes, whose board squares are json objects
+//  2. the games along with their mode, and later on their players and version
+//  3. the games whose board is in the compact encoding
+//  4. the games holding their schema version, along with the time they were created at
+//
+// The versions 1 to 3 were stored without version, which is detected from the shape of the document.
+var Games = NewRegistry(detectGame,
+	Migration{
+		From:        1,
+		Description: "games stored before the game modes are classic games",
+		Up: func(doc Document) error {
+			if _, ok := doc["mode"]; !ok {
+				doc["mode"] = json.RawMessage(`"` + game.MODE_CLASSIC + `"`)
+			}
+
+			return nil
+		},
+	},
+	Migration{
+		From:        2,
+		Description: "encode the board in the compact encoding",
+		Up: func(doc Document) error {
+			b := board.Board{}
+
+			err := json.Unmarshal(doc["board"], &b)
+			if err != nil {
+				return err
+			}
+
+			doc["board"], err = json.Marshal(b.Compact())
+
+			return err
+		},
+	},
+	Migration{
+		From:        3,
+		Description: "record the creation time of the games stored without it, from the time they started at",
+		Up: func(doc Document) error {
+			createdAt := int64(0)
+			if raw, ok := doc["created_at"]; ok {
+				err := json.Unmarshal(raw, &createdAt)
+				if err != nil {
+					return err
+				}
+			}
+
+			if raw, ok := doc["started_at"]; ok && createdAt == 0 {
+				doc["created_at"] = raw
+			}
+
+			return nil
+		},
+	},
+)
+
+// detectGame return the version of a game stored without version
+func detectGame(doc Document) (int, error) {
+	squares := struct {
+		Squares []json.RawMessage `json:"squares"`
+	}{}
+
+	err := json.Unmarshal(doc["board"], &squares)
+	if err != nil {
+		return 0, errors.New(apperrors.Internal, err, "internal error", "unmarshal board of game has failed")
+	}
+
+	// rows of compact boards are strings, while the squares of the older ones are arrays of objects
+	if len(squares.Squares) > 0 && len(squares.Squares[0]) > 0 && squares.Squares[0][0] == '"' {
+		return 3, nil
+	}
+
+	if _, ok := doc["mode"]; ok {
+		return 2, nil
+	}
+
+	if _, ok := doc["version"]; ok {
+		return 2, nil
+	}
+
+	return 1, nil
+}
+
+// storedGame is a game as it is stored: in the compact encoding, along with its schema version
+type storedGame struct {
+	game.CompactGame
+	SchemaVersion int `json:"schema_version"`
+}
+
+// MarshalGame encode the game to be stored at the current version
+func MarshalGame(g game.Game) ([]byte, error) {
+	return json.Marshal(storedGame{CompactGame: game.CompactGame{Game: g, Board: g.Board.Compact()}, SchemaVersion: Games.Current()})
+}
+
+// UnmarshalGame decode a stored game, upgrading it when it has been stored at an older version. It also return
+// whether the game was outdated, in which case it should be stored again.
+func UnmarshalGame(bytes []byte) (game.Game, bool, error) {
+	doc, from, err := Games.Upgrade(bytes)
+	if err != nil {
+		return game.Game{}, false, err
+	}
+
+	if from < Games.Current() {
+		bytes, err = json.Marshal(doc)
+		if err != nil {
+			return game.Game{}, false, errors.New(apperrors.Internal, err, "internal error", "marshal upgraded game has failed")
+		}
+	}
+
+	stored := storedGame{}
+
+	err = json.Unmarshal(bytes, &stored)
+	if err != nil {
+		return game.Game{}, false, errors.New(apperrors.Internal, err, "internal error", "unmarshal game into struct has failed")
+	}
+
+	g := stored.Game
+
+	g.Board, err = stored.Board.Board()
+	if err != nil {
+		return game.Game{}, false, errors.New(apperrors.Internal, err, "internal error", "decode compact board has failed")
+	}
+
+	return g, from < Games.Current(), nil
+}
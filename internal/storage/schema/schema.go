@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// VersionField is the field of the stored documents holding their schema version
+const VersionField = "schema_version"
+
+// Document is a stored json object, its fields being kept as is until a migration changes them
+type Document map[string]json.RawMessage
+
+// Migration upgrades the documents of the version From to the next version
+type Migration struct {
+	From        int
+	Description string
+	Up          func(doc Document) error
+}
+
+// Registry holds the migrations of a kind of document. The current version is the one following the last
+// migration, documents being upgraded one version after the other until they reach it.
+type Registry struct {
+	migrations map[int]Migration
+	current    int
+	// detect return the version of the documents stored before their version was recorded
+	detect func(doc Document) (int, error)
+}
+
+// Migrator is implemented by the storages able to upgrade their stored documents to the current version at once,
+// rather than one after the other when they are read
+type Migrator interface {
+	// Migrate upgrades the outdated documents and return how many have been upgraded
+	Migrate() (int, error)
+}
+
+// NewRegistry return a registry of the given migrations, which must follow one another starting at version 1.
+// detect is given the documents without version.
+func NewRegistry(detect func(doc Document) (int, error), migrations ...Migration) *Registry {
+	r := &Registry{migrations: map[int]Migration{}, current: 1, detect: detect}
+
+	for _, m := range migrations {
+		if m.From != r.current {
+			panic(fmt.Sprintf("migration from version %d does not follow version %d", m.From, r.current))
+		}
+
+		r.migrations[m.From] = m
+		r.current++
+	}
+
+	return r
+}
+
+// Current return the version the documents are upgraded to
+func (r *Registry) Current() int {
+	return r.current
+}
+
+// Version return the version of the document
+func (r *Registry) Version(doc Document) (int, error) {
+	raw, ok := doc[VersionField]
+	if !ok {
+		return r.detect(doc)
+	}
+
+	version := 0
+
+	err := json.Unmarshal(raw, &version)
+	if err != nil {
+		return 0, errors.New(apperrors.Internal, err, "internal error", "invalid schema version of document")
+	}
+
+	return version, nil
+}
+
+// Upgrade decodes the document and upgrades it to the current version, returning the version it was stored with.
+// Documents of a newer version than the current one, written by a newer release, are not supported.
+func (r *Registry) Upgrade(bytes []byte) (Document, int, error) {
+	doc := Document{}
+
+	err := json.Unmarshal(bytes, &doc)
+	if err != nil {
+		return nil, 0, errors.New(apperrors.Internal, err, "internal error", "unmarshal document has failed")
+	}
+
+	from, err := r.Version(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if from > r.current {
+		return nil, 0, errors.New(apperrors.Unsupported, nil, "internal error", fmt.Sprintf("schema version %d of document is newer than the supported %d", from, r.current))
+	}
+
+	for version := from; version < r.current; version++ {
+		migration, ok := r.migrations[version]
+		if !ok {
+			return nil, 0, errors.New(apperrors.Unsupported, nil, "internal error", fmt.Sprintf("no migration from schema version %d", version))
+		}
+
+		err = migration.Up(doc)
+		if err != nil {
+			return nil, 0, errors.New(apperrors.Internal, err, "internal error", fmt.Sprintf("migrate document from schema version %d has failed", version))
+		}
+	}
+
+	doc[VersionField] = json.RawMessage(fmt.Sprint(r.current))
+
+	return doc, from, nil
+}
@@ -0,0 +1,264 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/schema"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixture return the game stored at the given schema version in testdata
+func fixture(t *testing.T, version string) []byte {
+	bytes, err := os.ReadFile(filepath.Join("testdata", "game_"+version+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes
+}
+
+// fixtureGame return the game held by the fixtures, as it is once upgraded
+func fixtureGame(id string) game.Game {
+	firstMoveDone := true
+
+	return game.Game{
+		ID:       id,
+		Mode:     game.MODE_COOP,
+		PlayerID: "alice",
+		Players:  []game.Participant{{ID: "alice", RevealedSquaresCount: 1, MarkedSquaresCount: 1}},
+		Board: board.Board{
+			Squares: [][]board.Square{
+				{{Type: board.BOMB, Marked: true}, {}, {}},
+				{{}, {Revealed: true}, {}},
+				{{}, {}, {}},
+			},
+			BombsNumber:          1,
+			BombsPositions:       &[]board.SquarePosition{{Row: 0, Column: 0}},
+			Status:               board.STATUS_ON_GOING,
+			FirstMoveDone:        &firstMoveDone,
+			RevealedSquaresCount: 1,
+		},
+		CreatedAt:   1588291200,
+		StartedAt:   1588291200,
+		ElapsedTime: 60,
+		Version:     2,
+		Moves: []game.Move{
+			{
+				ID:                   1,
+				Type:                 game.MOVE_PLAY_SQUARE,
+				PlayerID:             "alice",
+				Position:             board.SquarePosition{Row: 1, Column: 1},
+				Squares:              []game.SquareChange{{Row: 1, Column: 1, Revealed: true, Count: 1}},
+				Status:               board.STATUS_ON_GOING,
+				StatusChanged:        true,
+				RevealedSquaresCount: 1,
+				At:                   1588291200,
+			},
+			{
+				ID:                   2,
+				Type:                 game.MOVE_MARK_SQUARE,
+				PlayerID:             "alice",
+				Position:             board.SquarePosition{Row: 0, Column: 0},
+				Squares:              []game.SquareChange{},
+				Status:               board.STATUS_ON_GOING,
+				RevealedSquaresCount: 1,
+				At:                   1588291230,
+			},
+		},
+	}
+}
+
+func TestUnmarshalGame(t *testing.T) {
+	tests := []struct {
+		name     string
+		should   string
+		version  string
+		expected func() game.Game
+		outdated bool
+	}{
+		{
+			name:    "version 1",
+			should:  "return a classic game whose creation time is its start",
+			version: "v1",
+			expected: func() game.Game {
+				g := fixtureGame("v1")
+				g.Mode = game.MODE_CLASSIC
+				g.PlayerID = ""
+				g.Players = nil
+				g.Version = 0
+				g.Moves = nil
+				return g
+			},
+			outdated: true,
+		},
+		{
+			name:     "version 2",
+			should:   "return the game with its board and its creation time upgraded",
+			version:  "v2",
+			expected: func() game.Game { return fixtureGame("v2") },
+			outdated: true,
+		},
+		{
+			name:     "version 3",
+			should:   "return the game whose creation time is its start",
+			version:  "v3",
+			expected: func() game.Game { return fixtureGame("v3") },
+			outdated: true,
+		},
+		{
+			name:    "version 4",
+			should:  "return the game as it is stored",
+			version: "v4",
+			expected: func() game.Game {
+				g := fixtureGame("v4")
+				g.CreatedAt = 1588291100
+				return g
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, outdated, err := schema.UnmarshalGame(fixture(t, tt.version))
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected(), g)
+			assert.Equal(t, tt.outdated, outdated)
+		})
+	}
+}
+
+func TestMarshalGame(t *testing.T) {
+	g := fixtureGame("1")
+
+	bytes, err := schema.MarshalGame(g)
+	assert.Nil(t, err)
+
+	doc := schema.Document{}
+	assert.Nil(t, json.Unmarshal(bytes, &doc))
+	assert.Equal(t, "4", string(doc[schema.VersionField]))
+	assert.Equal(t, `["500","020","000"]`, compactSquares(t, doc))
+
+	stored, outdated, err := schema.UnmarshalGame(bytes)
+	assert.Nil(t, err)
+	assert.False(t, outdated)
+	assert.Equal(t, g, stored)
+}
+
+func compactSquares(t *testing.T, doc schema.Document) string {
+	b := map[string]json.RawMessage{}
+	assert.Nil(t, json.Unmarshal(doc["board"], &b))
+
+	return string(b["squares"])
+}
+
+func TestRegistry(t *testing.T) {
+	// the documents hold the migrations they went through
+	appendStep := func(step string) func(doc schema.Document) error {
+		return func(doc schema.Document) error {
+			steps := []string{}
+			if raw, ok := doc["steps"]; ok {
+				if err := json.Unmarshal(raw, &steps); err != nil {
+					return err
+				}
+			}
+
+			var err error
+			doc["steps"], err = json.Marshal(append(steps, step))
+
+			return err
+		}
+	}
+
+	newRegistry := func() *schema.Registry {
+		return schema.NewRegistry(
+			func(doc schema.Document) (int, error) { return 1, nil },
+			schema.Migration{From: 1, Up: appendStep("1 to 2")},
+			schema.Migration{From: 2, Up: appendStep("2 to 3")},
+		)
+	}
+
+	tests := []struct {
+		name   string
+		should string
+		input  string
+		verify func(t *testing.T, doc schema.Document, from int, err error)
+	}{
+		{
+			name:   "document without version",
+			should: "detect its version and apply every migration in order",
+			input:  `{"id": "1"}`,
+			verify: func(t *testing.T, doc schema.Document, from int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, from)
+				assert.JSONEq(t, `["1 to 2", "2 to 3"]`, string(doc["steps"]))
+				assert.Equal(t, "3", string(doc[schema.VersionField]))
+			},
+		},
+		{
+			name:   "outdated document",
+			should: "apply the migrations from its version",
+			input:  `{"id": "1", "schema_version": 2}`,
+			verify: func(t *testing.T, doc schema.Document, from int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 2, from)
+				assert.JSONEq(t, `["2 to 3"]`, string(doc["steps"]))
+			},
+		},
+		{
+			name:   "current document",
+			should: "keep it as is",
+			input:  `{"id": "1", "schema_version": 3}`,
+			verify: func(t *testing.T, doc schema.Document, from int, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 3, from)
+				assert.NotContains(t, doc, "steps")
+			},
+		},
+		{
+			name:   "newer document",
+			should: "return an unsupported error",
+			input:  `{"id": "1", "schema_version": 4}`,
+			verify: func(t *testing.T, doc schema.Document, from int, err error) {
+				assert.True(t, errors.Is(err, apperrors.Unsupported))
+			},
+		},
+		{
+			name:   "invalid version",
+			should: "return an internal error",
+			input:  `{"id": "1", "schema_version": "two"}`,
+			verify: func(t *testing.T, doc schema.Document, from int, err error) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+		{
+			name:   "not a document",
+			should: "return an internal error",
+			input:  `["1"]`,
+			verify: func(t *testing.T, doc schema.Document, from int, err error) {
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, from, err := newRegistry().Upgrade([]byte(tt.input))
+
+			tt.verify(t, doc, from, err)
+		})
+	}
+
+	t.Run("migrations with a gap", func(t *testing.T) {
+		assert.Panics(t, func() {
+			schema.NewRegistry(nil, schema.Migration{From: 1}, schema.Migration{From: 3})
+		})
+	})
+}
@@ -0,0 +1,392 @@
+package sqlsto
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// GameStorage stores the games in sqlite. The metadata of the games is stored in columns, so that games can
+// be listed and filtered, while the board is stored as a blob in the compact encoding.
+type GameStorage struct {
+	db *sql.DB
+}
+
+// walkBatchSize is the number of games read at once while walking the storage
+const walkBatchSize = 100
+
+const gameColumns = `id, mode, owner_id, status, board_status, turn, winner, board_rows, board_columns, bombs,
+	revealed_squares_count, players, moves, board, version, started_at, finished_at, elapsed_time, game_created_at`
+
+// NewGameStorage opens the sqlite database at the given path, creating it when missing
+func NewGameStorage(path string) (*GameStorage, error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GameStorage{db}, nil
+}
+
+// Close closes the database
+func (sto *GameStorage) Close() error {
+	err := sto.db.Close()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "close sqlite database has failed")
+	}
+
+	return nil
+}
+
+func (sto *GameStorage) Create(gameToCreate game.Game) error {
+	row, err := newGameRow(gameToCreate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal game into row has failed")
+	}
+
+	now := time.Now().UnixNano()
+
+	_, err = sto.db.Exec(`INSERT INTO games (`+gameColumns+`, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		append(row.values(), now, now)...,
+	)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "insert game into sqlite has failed")
+	}
+
+	return nil
+}
+
+func (sto *GameStorage) Update(gameToUpdate game.Game) error {
+	expected := gameToUpdate.Version
+	gameToUpdate.Version++
+
+	row, err := newGameRow(gameToUpdate)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "marshal game into row has failed")
+	}
+
+	result, err := sto.db.Exec(`UPDATE games SET mode = ?, owner_id = ?, status = ?, board_status = ?, turn = ?, winner = ?,
+		board_rows = ?, board_columns = ?, bombs = ?, revealed_squares_count = ?, players = ?, moves = ?, board = ?,
+		version = ?, started_at = ?, finished_at = ?, elapsed_time = ?, game_created_at = ?, updated_at = ?
+		WHERE id = ? AND version = ?`,
+		append(row.values()[1:], time.Now().UnixNano(), row.ID, expected)...,
+	)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "update game in sqlite has failed")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "get updated rows from sqlite has failed")
+	}
+
+	if affected == 0 {
+		// either the game does not exist or it is at another version
+		_, err = sto.GetByID(gameToUpdate.ID)
+		if err != nil {
+			return err
+		}
+
+		return errors.New(apperrors.Conflict, nil, "game has been modified", "game version does not match the stored one")
+	}
+
+	return nil
+}
+
+func (sto *GameStorage) GetByID(id string) (game.Game, error) {
+	row := gameRow{}
+
+	err := row.scan(sto.db.QueryRow(`SELECT `+gameColumns+` FROM games WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return game.Game{}, errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in sqlite")
+	}
+
+	if err != nil {
+		return game.Game{}, errors.New(apperrors.Internal, err, "internal error", "get game by id from sqlite has failed")
+	}
+
+	requestedGame, err := row.game()
+	if err != nil {
+		return game.Game{}, errors.New(apperrors.Internal, err, "internal error", "unmarshal game row has failed")
+	}
+
+	return requestedGame, nil
+}
+
+func (sto *GameStorage) Delete(id string) error {
+	result, err := sto.db.Exec(`DELETE FROM games WHERE id = ?`, id)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "delete game from sqlite has failed")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "get deleted rows from sqlite has failed")
+	}
+
+	if affected == 0 {
+		return errors.New(apperrors.NotFound, nil, "game has not been found", "game not found in sqlite")
+	}
+
+	return nil
+}
+
+// Walk calls fn with every stored game in the order they were created. The games are read in batches
+// and fn is only called between batches, so that it is free to use the storage.
+func (sto *GameStorage) Walk(fn func(g game.Game) error) error {
+	after := int64(0)
+
+	for {
+		games, last, err := sto.walkBatch(after)
+		if err != nil {
+			return err
+		}
+
+		for _, g := range games {
+			err = fn(g)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(games) < walkBatchSize {
+			return nil
+		}
+
+		after = last
+	}
+}
+
+// walkBatch return the games created after the one at the given position, along with the position of the last one
+func (sto *GameStorage) walkBatch(after int64) ([]game.Game, int64, error) {
+	rows, err := sto.db.Query(`SELECT rowid, `+gameColumns+` FROM games WHERE rowid > ? ORDER BY rowid LIMIT ?`, after, walkBatchSize)
+	if err != nil {
+		return nil, 0, errors.New(apperrors.Internal, err, "internal error", "walk games of sqlite has failed")
+	}
+	defer rows.Close()
+
+	games := []game.Game{}
+
+	for rows.Next() {
+		row := gameRow{}
+
+		err = rows.Scan(append([]interface{}{&after}, row.dest()...)...)
+		if err != nil {
+			return nil, 0, errors.New(apperrors.Internal, err, "internal error", "scan game row has failed")
+		}
+
+		g, err := row.game()
+		if err != nil {
+			return nil, 0, errors.New(apperrors.Internal, err, "internal error", "unmarshal game row has failed")
+		}
+
+		games = append(games, g)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, 0, errors.New(apperrors.Internal, err, "internal error", "walk games of sqlite has failed")
+	}
+
+	return games, after, nil
+}
+
+// List return the games matching the filter, the most recently created first
+func (sto *GameStorage) List(filter game.Filter) ([]game.Game, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.PlayerID != "" {
+		conditions = append(conditions, `(owner_id = ? OR EXISTS (SELECT 1 FROM json_each(games.players) WHERE json_extract(value, '$.id') = ?))`)
+		args = append(args, filter.PlayerID, filter.PlayerID)
+	}
+
+	if filter.Mode != "" {
+		conditions = append(conditions, `mode = ?`)
+		args = append(args, filter.Mode)
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, `(status = ? OR board_status = ?)`)
+		args = append(args, filter.Status, filter.Status)
+	}
+
+	query := `SELECT ` + gameColumns + ` FROM games`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	query += ` ORDER BY created_at DESC, rowid DESC LIMIT ? OFFSET ?`
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := sto.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "list games from sqlite has failed")
+	}
+	defer rows.Close()
+
+	games := []game.Game{}
+
+	for rows.Next() {
+		row := gameRow{}
+
+		err = row.scan(rows)
+		if err != nil {
+			return nil, errors.New(apperrors.Internal, err, "internal error", "scan game row has failed")
+		}
+
+		g, err := row.game()
+		if err != nil {
+			return nil, errors.New(apperrors.Internal, err, "internal error", "unmarshal game row has failed")
+		}
+
+		games = append(games, g)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "list games from sqlite has failed")
+	}
+
+	return games, nil
+}
+
+// gameRow is a game as stored in the games table
+type gameRow struct {
+	ID                   string
+	Mode                 string
+	OwnerID              string
+	Status               string
+	BoardStatus          string
+	Turn                 string
+	Winner               string
+	Rows                 int
+	Columns              int
+	Bombs                int
+	RevealedSquaresCount int
+	Players              string
+	Moves                string
+	Board                []byte
+	Version              int64
+	StartedAt            int64
+	FinishedAt           int64
+	ElapsedTime          int64
+	CreatedAt            int64
+}
+
+func newGameRow(g game.Game) (gameRow, error) {
+	players, err := json.Marshal(g.Players)
+	if err != nil {
+		return gameRow{}, err
+	}
+
+	moves, err := json.Marshal(g.Moves)
+	if err != nil {
+		return gameRow{}, err
+	}
+
+	compact, err := json.Marshal(g.Board.Compact())
+	if err != nil {
+		return gameRow{}, err
+	}
+
+	row := gameRow{
+		ID:                   g.ID,
+		Mode:                 g.Mode,
+		OwnerID:              g.PlayerID,
+		Status:               g.Status,
+		BoardStatus:          g.Board.Status,
+		Turn:                 g.Turn,
+		Winner:               g.Winner,
+		Rows:                 len(g.Board.Squares),
+		Bombs:                g.Board.BombsNumber,
+		RevealedSquaresCount: g.Board.RevealedSquaresCount,
+		Players:              string(players),
+		Moves:                string(moves),
+		Board:                compact,
+		Version:              g.Version,
+		StartedAt:            g.StartedAt,
+		FinishedAt:           g.FinishedAt,
+		ElapsedTime:          g.ElapsedTime,
+		CreatedAt:            g.CreatedAt,
+	}
+
+	if row.Rows > 0 {
+		row.Columns = len(g.Board.Squares[0])
+	}
+
+	return row, nil
+}
+
+// values return the values of the row in the order of gameColumns
+func (r gameRow) values() []interface{} {
+	return []interface{}{
+		r.ID, r.Mode, r.OwnerID, r.Status, r.BoardStatus, r.Turn, r.Winner, r.Rows, r.Columns, r.Bombs,
+		r.RevealedSquaresCount, r.Players, r.Moves, r.Board, r.Version, r.StartedAt, r.FinishedAt, r.ElapsedTime,
+		r.CreatedAt,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *gameRow) scan(s scanner) error {
+	return s.Scan(r.dest()...)
+}
+
+// dest return the destinations of the columns of the row in the order of gameColumns
+func (r *gameRow) dest() []interface{} {
+	return []interface{}{
+		&r.ID, &r.Mode, &r.OwnerID, &r.Status, &r.BoardStatus, &r.Turn, &r.Winner, &r.Rows, &r.Columns, &r.Bombs,
+		&r.RevealedSquaresCount, &r.Players, &r.Moves, &r.Board, &r.Version, &r.StartedAt, &r.FinishedAt, &r.ElapsedTime,
+		&r.CreatedAt,
+	}
+}
+
+func (r gameRow) game() (game.Game, error) {
+	g := game.Game{
+		ID:          r.ID,
+		Mode:        r.Mode,
+		PlayerID:    r.OwnerID,
+		Status:      r.Status,
+		Turn:        r.Turn,
+		Winner:      r.Winner,
+		Version:     r.Version,
+		StartedAt:   r.StartedAt,
+		FinishedAt:  r.FinishedAt,
+		ElapsedTime: r.ElapsedTime,
+		CreatedAt:   r.CreatedAt,
+	}
+
+	err := json.Unmarshal([]byte(r.Players), &g.Players)
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	err = json.Unmarshal([]byte(r.Moves), &g.Moves)
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	compact := board.CompactBoard{}
+
+	err = json.Unmarshal(r.Board, &compact)
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	g.Board, err = compact.Board()
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	return g, nil
+}
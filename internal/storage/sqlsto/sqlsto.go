@@ -0,0 +1,105 @@
+package sqlsto
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+
+	// registers the pure go sqlite driver, which does not require cgo
+	_ "modernc.org/sqlite"
+)
+
+// migrations are the changes of the schema, in order. They are applied at startup and must never be edited
+// once released: changes are made by appending a new migration.
+var migrations = []string{
+	`CREATE TABLE games (
+		id                     TEXT PRIMARY KEY,
+		mode                   TEXT NOT NULL,
+		owner_id               TEXT NOT NULL,
+		status                 TEXT NOT NULL,
+		board_status           TEXT NOT NULL,
+		turn                   TEXT NOT NULL,
+		winner                 TEXT NOT NULL,
+		board_rows             INTEGER NOT NULL,
+		board_columns          INTEGER NOT NULL,
+		bombs                  INTEGER NOT NULL,
+		revealed_squares_count INTEGER NOT NULL,
+		players                TEXT NOT NULL,
+		moves                  TEXT NOT NULL,
+		board                  BLOB NOT NULL,
+		version                INTEGER NOT NULL,
+		started_at             INTEGER NOT NULL,
+		finished_at            INTEGER NOT NULL,
+		elapsed_time           INTEGER NOT NULL,
+		created_at             INTEGER NOT NULL,
+		updated_at             INTEGER NOT NULL
+	);
+	CREATE INDEX games_owner_id ON games (owner_id, created_at);
+	CREATE INDEX games_status ON games (status, created_at);
+	CREATE INDEX games_board_status ON games (board_status, created_at);
+	CREATE INDEX games_created_at ON games (created_at);`,
+	// created_at is the time the row was inserted, game_created_at the creation time of the game
+	`ALTER TABLE games ADD COLUMN game_created_at INTEGER NOT NULL DEFAULT 0;`,
+	// the games stored without creation time were created when they started
+	`UPDATE games SET game_created_at = started_at WHERE game_created_at = 0;`,
+}
+
+// Open opens the sqlite database at the given path, creating it when missing, and applies the pending migrations
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "open sqlite database has failed")
+	}
+
+	// sqlite allows a single writer, sharing one connection avoids busy errors between writers of the same process
+	db.SetMaxOpenConns(1)
+
+	err = migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migrate applies the migrations that have not been applied yet, each one within its own transaction
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, applied_at INTEGER NOT NULL)`)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "create schema migrations table has failed")
+	}
+
+	var current int
+
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return errors.New(apperrors.Internal, err, "internal error", "get schema version has failed")
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return errors.New(apperrors.Internal, err, "internal error", "begin migration transaction has failed")
+		}
+
+		_, err = tx.Exec(migrations[version-1])
+		if err == nil {
+			_, err = tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().Unix())
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return errors.New(apperrors.Internal, err, "internal error", "apply schema migration has failed")
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return errors.New(apperrors.Internal, err, "internal error", "commit schema migration has failed")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package sqlsto_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/storage/sqlsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStorage(t *testing.T) (*sqlsto.GameStorage, string) {
+	path := filepath.Join(t.TempDir(), "minesweeper.db")
+
+	sto, err := sqlsto.NewGameStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { sto.Close() })
+
+	return sto, path
+}
+
+func TestGameStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) game.Storage {
+		sto, _ := newStorage(t)
+		return sto
+	})
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		verify func(t *testing.T, sto *sqlsto.GameStorage, path string)
+	}{
+		{
+			name:   "reopen",
+			should: "keep the games and not apply the migrations again",
+			verify: func(t *testing.T, sto *sqlsto.GameStorage, path string) {
+				assert.Nil(t, sto.Create(storagetest.NewGame("123", "", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Close())
+
+				reopened, err := sqlsto.NewGameStorage(path)
+				assert.Nil(t, err)
+				defer reopened.Close()
+
+				_, err = reopened.GetByID("123")
+				assert.Nil(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sto, path := newStorage(t)
+
+			tt.verify(t, sto, path)
+		})
+	}
+}
+
+func TestCreationTime(t *testing.T) {
+	storagetest.RunCreationTime(t, func(t *testing.T, g game.Game) game.Storage {
+		sto, path := newStorage(t)
+		assert.Nil(t, sto.Create(g))
+		assert.Nil(t, sto.Close())
+
+		// the migration recording the creation time of the games is applied again on the next start
+		db, err := sqlsto.Open(path)
+		assert.Nil(t, err)
+		_, err = db.Exec(`DELETE FROM schema_migrations WHERE version = 3`)
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+
+		reopened, err := sqlsto.NewGameStorage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { reopened.Close() })
+
+		return reopened
+	})
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/match"
+	"github.com/matiasvarela/minesweeper/internal/storage/eventsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/matiasvarela/minesweeper/internal/storage/localsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/pgsto"
+	"github.com/matiasvarela/minesweeper/internal/storage/sqlsto"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+const (
+	// BACKEND_BITCASK stores the data in bitcask databases within the data directory
+	BACKEND_BITCASK string = "bitcask"
+	// BACKEND_SQLITE stores the games in a sqlite database within the data directory, which allows listing them.
+	// The daily entries and the matches are stored in bitcask databases.
+	BACKEND_SQLITE string = "sqlite"
+	// BACKEND_POSTGRES stores the games in postgres, which allows listing them. The daily entries and the matches
+	// are stored in bitcask databases within the data directory.
+	BACKEND_POSTGRES string = "postgres"
+	// BACKEND_EVENTS stores every change of the games as an event in a bitcask database within the data directory,
+	// which keeps the history of the games. The daily entries and the matches are stored in bitcask databases.
+	BACKEND_EVENTS string = "events"
+	// BACKEND_MEMORY keeps the data in memory, it is lost on shutdown
+	BACKEND_MEMORY string = "memory"
+)
+
+// SqliteFile is the name of the sqlite database within the data directory
+const SqliteFile = "minesweeper.db"
+
+// Config selects the storage backend along with its options
+type Config struct {
+	Backend  string
+	DataPath string
+	Postgres pgsto.Config
+	// SnapshotInterval is the number of events between the snapshots of the games of the events backend
+	SnapshotInterval int
+}
+
+// Storages are the storages of the application, backed by the same backend
+type Storages struct {
+	Games   game.Storage
+	Dailies daily.Storage
+	Matches match.Storage
+
+	closers []io.Closer
+}
+
+// Open return the storages of the configured backend
+func Open(config Config) (*Storages, error) {
+	switch config.Backend {
+	case BACKEND_BITCASK:
+		return openBitcask(config.DataPath)
+	case BACKEND_SQLITE:
+		return openSqlite(config.DataPath)
+	case BACKEND_POSTGRES:
+		return openPostgres(config.DataPath, config.Postgres)
+	case BACKEND_EVENTS:
+		return openEvents(config.DataPath, config.SnapshotInterval)
+	case BACKEND_MEMORY:
+		return &Storages{
+			Games:   fakesto.NewGameStorage(),
+			Dailies: fakesto.NewDailyStorage(),
+			Matches: fakesto.NewMatchStorage(),
+		}, nil
+	default:
+		return nil, errors.New(apperrors.InvalidInput, nil, "unknown storage backend "+config.Backend, "")
+	}
+}
+
+// Close closes the storages, it must be called once they are no longer used so that every write is flushed
+func (s *Storages) Close() error {
+	var result error
+
+	for _, closer := range s.closers {
+		err := closer.Close()
+		if err != nil && result == nil {
+			result = err
+		}
+	}
+
+	s.closers = nil
+
+	return result
+}
+
+func openBitcask(dir string) (*Storages, error) {
+	games, err := localsto.NewGameStorage(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, err.Error())
+	}
+
+	return openWithGames(dir, games)
+}
+
+func openSqlite(dir string) (*Storages, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, errors.New(apperrors.Internal, err, "internal error", "create data directory has failed")
+	}
+
+	games, err := sqlsto.NewGameStorage(filepath.Join(dir, SqliteFile))
+	if err != nil {
+		return nil, errors.Wrap(err, err.Error())
+	}
+
+	return openWithGames(dir, games)
+}
+
+func openPostgres(dir string, config pgsto.Config) (*Storages, error) {
+	games, err := pgsto.NewGameStorage(config)
+	if err != nil {
+		return nil, errors.Wrap(err, err.Error())
+	}
+
+	return openWithGames(dir, games)
+}
+
+func openEvents(dir string, snapshotInterval int) (*Storages, error) {
+	games, err := eventsto.NewGameStorage(dir, snapshotInterval)
+	if err != nil {
+		return nil, errors.Wrap(err, err.Error())
+	}
+
+	return openWithGames(dir, games)
+}
+
+// openWithGames return the storages with the given game storage, along with the daily and match storages
+// in bitcask databases within the data directory
+func openWithGames(dir string, games interface {
+	game.Storage
+	io.Closer
+}) (*Storages, error) {
+	s := &Storages{Games: games, closers: []io.Closer{games}}
+
+	dailies, err := localsto.NewDailyStorage(dir)
+	if err != nil {
+		s.Close()
+		return nil, errors.Wrap(err, err.Error())
+	}
+	s.Dailies = dailies
+	s.closers = append(s.closers, dailies)
+
+	matches, err := localsto.NewMatchStorage(dir)
+	if err != nil {
+		s.Close()
+		return nil, errors.Wrap(err, err.Error())
+	}
+	s.Matches = matches
+	s.closers = append(s.closers, matches)
+
+	return s, nil
+}
@@ -0,0 +1,147 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/match"
+	"github.com/matiasvarela/minesweeper/internal/storage"
+	"github.com/matiasvarela/minesweeper/internal/storage/cachesto"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		should string
+		config func(t *testing.T) storage.Config
+		verify func(t *testing.T, config storage.Config, storages *storage.Storages, err error)
+	}{
+		{
+			name:   "bitcask backend",
+			should: "keep the data once closed and opened again",
+			config: func(t *testing.T) storage.Config {
+				return storage.Config{Backend: storage.BACKEND_BITCASK, DataPath: t.TempDir()}
+			},
+			verify: func(t *testing.T, config storage.Config, storages *storage.Storages, err error) {
+				assert.Nil(t, err)
+				assert.Nil(t, storages.Games.Create(game.Game{ID: "123", Version: 2}))
+				assert.Nil(t, storages.Dailies.Create(daily.Entry{Date: "2020-05-01", PlayerID: "alice", GameID: "123"}))
+				assert.Nil(t, storages.Close())
+
+				reopened, err := storage.Open(config)
+				assert.Nil(t, err)
+				defer reopened.Close()
+
+				g, err := reopened.Games.GetByID("123")
+				assert.Nil(t, err)
+				assert.Equal(t, int64(2), g.Version)
+
+				entry, err := reopened.Dailies.Get("2020-05-01", "alice")
+				assert.Nil(t, err)
+				assert.Equal(t, "123", entry.GameID)
+			},
+		},
+		{
+			name:   "bitcask backend already in use",
+			should: "return an error instead of panicking",
+			config: func(t *testing.T) storage.Config {
+				return storage.Config{Backend: storage.BACKEND_BITCASK, DataPath: t.TempDir()}
+			},
+			verify: func(t *testing.T, config storage.Config, storages *storage.Storages, err error) {
+				assert.Nil(t, err)
+				defer storages.Close()
+
+				_, err = storage.Open(config)
+				assert.True(t, errors.Is(err, apperrors.Internal))
+			},
+		},
+		{
+			name:   "sqlite backend",
+			should: "store the games in sqlite, which can list them",
+			config: func(t *testing.T) storage.Config {
+				return storage.Config{Backend: storage.BACKEND_SQLITE, DataPath: filepath.Join(t.TempDir(), "data")}
+			},
+			verify: func(t *testing.T, config storage.Config, storages *storage.Storages, err error) {
+				assert.Nil(t, err)
+				defer storages.Close()
+
+				assert.Nil(t, storages.Games.Create(game.Game{ID: "123"}))
+				assert.Nil(t, storages.Matches.Create(match.Match{ID: "456"}))
+
+				games, err := storages.Games.(game.Lister).List(game.Filter{Limit: 10})
+				assert.Nil(t, err)
+				assert.Len(t, games, 1)
+			},
+		},
+		{
+			name:   "events backend",
+			should: "store the games along with their past versions, read through the cache",
+			config: func(t *testing.T) storage.Config {
+				return storage.Config{Backend: storage.BACKEND_EVENTS, DataPath: t.TempDir(), SnapshotInterval: 2}
+			},
+			verify: func(t *testing.T, config storage.Config, storages *storage.Storages, err error) {
+				assert.Nil(t, err)
+				defer storages.Close()
+
+				assert.Nil(t, storages.Games.Create(game.Game{ID: "123"}))
+				assert.Nil(t, storages.Games.Update(game.Game{ID: "123", ElapsedTime: 10}))
+
+				history := cachesto.NewGameStorage(storages.Games, 10)
+
+				g, err := history.GetAt("123", 0)
+				assert.Nil(t, err)
+				assert.Equal(t, int64(0), g.ElapsedTime)
+			},
+		},
+		{
+			name:   "events backend without snapshot interval",
+			should: "return an invalid input error",
+			config: func(t *testing.T) storage.Config {
+				return storage.Config{Backend: storage.BACKEND_EVENTS, DataPath: t.TempDir()}
+			},
+			verify: func(t *testing.T, config storage.Config, storages *storage.Storages, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+		{
+			name:   "memory backend",
+			should: "return working storages",
+			config: func(t *testing.T) storage.Config {
+				return storage.Config{Backend: storage.BACKEND_MEMORY}
+			},
+			verify: func(t *testing.T, config storage.Config, storages *storage.Storages, err error) {
+				assert.Nil(t, err)
+				assert.Nil(t, storages.Games.Create(game.Game{ID: "123"}))
+
+				_, err = storages.Games.GetByID("123")
+				assert.Nil(t, err)
+				assert.Nil(t, storages.Close())
+			},
+		},
+		{
+			name:   "unknown backend",
+			should: "return an invalid input error",
+			config: func(t *testing.T) storage.Config {
+				return storage.Config{Backend: "dynamo"}
+			},
+			verify: func(t *testing.T, config storage.Config, storages *storage.Storages, err error) {
+				assert.True(t, errors.Is(err, apperrors.InvalidInput))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config(t)
+
+			storages, err := storage.Open(config)
+
+			tt.verify(t, config, storages, err)
+		})
+	}
+}
@@ -0,0 +1,525 @@
+// Package storagetest provides the conformance suite of game.Storage, which every adapter runs from its own tests
+// so that their behaviors do not drift.
+package storagetest
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/matiasvarela/errors"
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+// WalkedGames is the number of games stored by the walking tests, enough to span several batches of the adapters
+const WalkedGames = 250
+
+// ConcurrentUpdates is the number of updates made at the same time by the concurrency tests
+const ConcurrentUpdates = 8
+
+// Run runs the suite against the storages returned by newStorage, which is called once per test and must return
+// an empty storage. Storages are expected to be released by newStorage itself, with t.Cleanup.
+// The listing, walking and compaction tests are only run when the storage is a game.Lister, a game.Walker
+// and a game.Compacter respectively, and are skipped as well when the storage reports them as unsupported.
+func Run(t *testing.T, newStorage func(t *testing.T) game.Storage) {
+	tests := []struct {
+		name   string
+		should string
+		verify func(t *testing.T, sto game.Storage)
+	}{
+		{
+			name:   "create and get",
+			should: "return the stored game as it was created",
+			verify: func(t *testing.T, sto game.Storage) {
+				g := NewPlayedGame("123")
+
+				assert.Nil(t, sto.Create(g))
+
+				stored, err := sto.GetByID("123")
+				assert.Nil(t, err)
+				assert.Equal(t, g, stored)
+			},
+		},
+		{
+			name:   "round trip of boards",
+			should: "keep every field of the board, nil pointers included",
+			verify: func(t *testing.T, sto game.Storage) {
+				for id, b := range Boards() {
+					g := NewGame(id, "", game.MODE_CLASSIC)
+					g.Board = b
+
+					assert.Nil(t, sto.Create(g))
+
+					stored, err := sto.GetByID(id)
+					assert.Nil(t, err)
+					assert.Equal(t, b, stored.Board, id)
+				}
+			},
+		},
+		{
+			name:   "get missing game",
+			should: "return a not found error",
+			verify: func(t *testing.T, sto game.Storage) {
+				_, err := sto.GetByID("456")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+		{
+			name:   "update",
+			should: "replace the game and increment its version",
+			verify: func(t *testing.T, sto game.Storage) {
+				g := NewGame("123", "", game.MODE_CLASSIC)
+				assert.Nil(t, sto.Create(g))
+
+				g.Board.MarkSquare(board.SquarePosition{Row: 0, Column: 0})
+				assert.Nil(t, sto.Update(g))
+
+				stored, err := sto.GetByID("123")
+				assert.Nil(t, err)
+				assert.Equal(t, int64(1), stored.Version)
+				assert.True(t, stored.Board.Get(board.SquarePosition{Row: 0, Column: 0}).Marked)
+
+				assert.Nil(t, sto.Update(stored))
+
+				stored, err = sto.GetByID("123")
+				assert.Nil(t, err)
+				assert.Equal(t, int64(2), stored.Version)
+			},
+		},
+		{
+			name:   "update of a modified game",
+			should: "return a conflict error and keep the stored game",
+			verify: func(t *testing.T, sto game.Storage) {
+				g := NewGame("123", "", game.MODE_CLASSIC)
+				assert.Nil(t, sto.Create(g))
+				assert.Nil(t, sto.Update(g))
+
+				g.Board.MarkSquare(board.SquarePosition{Row: 0, Column: 0})
+				assert.True(t, errors.Is(sto.Update(g), apperrors.Conflict))
+
+				stored, err := sto.GetByID("123")
+				assert.Nil(t, err)
+				assert.Equal(t, int64(1), stored.Version)
+				assert.False(t, stored.Board.Get(board.SquarePosition{Row: 0, Column: 0}).Marked)
+			},
+		},
+		{
+			name:   "update of a missing game",
+			should: "return a not found error and not create it",
+			verify: func(t *testing.T, sto game.Storage) {
+				assert.True(t, errors.Is(sto.Update(NewGame("456", "", game.MODE_CLASSIC)), apperrors.NotFound))
+
+				_, err := sto.GetByID("456")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+			},
+		},
+		{
+			name:   "concurrent updates",
+			should: "apply only one of the updates made at the same version",
+			verify: func(t *testing.T, sto game.Storage) {
+				g := NewGame("123", "", game.MODE_CLASSIC)
+				assert.Nil(t, sto.Create(g))
+
+				var wg sync.WaitGroup
+				errs := make(chan error, ConcurrentUpdates)
+
+				for i := 0; i < ConcurrentUpdates; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						errs <- sto.Update(g)
+					}()
+				}
+
+				wg.Wait()
+				close(errs)
+
+				applied := 0
+				for err := range errs {
+					if err == nil {
+						applied++
+						continue
+					}
+
+					assert.True(t, errors.Is(err, apperrors.Conflict))
+				}
+
+				assert.Equal(t, 1, applied)
+
+				stored, err := sto.GetByID("123")
+				assert.Nil(t, err)
+				assert.Equal(t, int64(1), stored.Version)
+			},
+		},
+		{
+			name:   "concurrent updates of different games",
+			should: "apply every update",
+			verify: func(t *testing.T, sto game.Storage) {
+				ids := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+
+				for _, id := range ids {
+					assert.Nil(t, sto.Create(NewGame(id, "", game.MODE_CLASSIC)))
+				}
+
+				var wg sync.WaitGroup
+
+				for _, id := range ids {
+					wg.Add(1)
+					go func(id string) {
+						defer wg.Done()
+
+						g, err := sto.GetByID(id)
+						assert.Nil(t, err)
+						assert.Nil(t, sto.Update(g))
+					}(id)
+				}
+
+				wg.Wait()
+
+				for _, id := range ids {
+					stored, err := sto.GetByID(id)
+					assert.Nil(t, err)
+					assert.Equal(t, int64(1), stored.Version, id)
+				}
+			},
+		},
+		{
+			name:   "delete",
+			should: "remove the game",
+			verify: func(t *testing.T, sto game.Storage) {
+				g := NewGame("123", "", game.MODE_CLASSIC)
+				assert.Nil(t, sto.Create(g))
+				assert.Nil(t, sto.Create(NewGame("456", "", game.MODE_CLASSIC)))
+
+				assert.Nil(t, sto.Delete("123"))
+
+				_, err := sto.GetByID("123")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+				assert.True(t, errors.Is(sto.Update(g), apperrors.NotFound))
+				assert.True(t, errors.Is(sto.Delete("123"), apperrors.NotFound))
+
+				_, err = sto.GetByID("456")
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:   "delete missing game",
+			should: "return a not found error",
+			verify: func(t *testing.T, sto game.Storage) {
+				assert.True(t, errors.Is(sto.Delete("456"), apperrors.NotFound))
+			},
+		},
+		{
+			name:   "walk",
+			should: "go through every stored game",
+			verify: func(t *testing.T, sto game.Storage) {
+				walker, ok := sto.(game.Walker)
+				if !ok {
+					t.Skip("storage is not a game.Walker")
+				}
+
+				if err := walker.Walk(func(g game.Game) error { return nil }); errors.Is(err, apperrors.Unsupported) {
+					t.Skip("walking games is not supported by the storage")
+				}
+
+				expected := []string{}
+				for i := 0; i < WalkedGames; i++ {
+					id := strconv.Itoa(i)
+					expected = append(expected, id)
+					assert.Nil(t, sto.Create(NewGame(id, "", game.MODE_CLASSIC)))
+				}
+
+				walked := []string{}
+				assert.Nil(t, walker.Walk(func(g game.Game) error {
+					walked = append(walked, g.ID)
+					return nil
+				}))
+
+				sort.Strings(expected)
+				sort.Strings(walked)
+				assert.Equal(t, expected, walked)
+			},
+		},
+		{
+			name:   "walk while deleting",
+			should: "go through every stored game and allow deleting them",
+			verify: func(t *testing.T, sto game.Storage) {
+				walker, ok := sto.(game.Walker)
+				if !ok {
+					t.Skip("storage is not a game.Walker")
+				}
+
+				if err := walker.Walk(func(g game.Game) error { return nil }); errors.Is(err, apperrors.Unsupported) {
+					t.Skip("walking games is not supported by the storage")
+				}
+
+				for i := 0; i < WalkedGames; i++ {
+					assert.Nil(t, sto.Create(NewGame(strconv.Itoa(i), "", game.MODE_CLASSIC)))
+				}
+
+				walked := 0
+				assert.Nil(t, walker.Walk(func(g game.Game) error {
+					walked++
+					return sto.Delete(g.ID)
+				}))
+				assert.Equal(t, WalkedGames, walked)
+
+				assert.Nil(t, walker.Walk(func(g game.Game) error {
+					t.Errorf("game %s has not been deleted", g.ID)
+					return nil
+				}))
+			},
+		},
+		{
+			name:   "walk until an error",
+			should: "stop and return the error",
+			verify: func(t *testing.T, sto game.Storage) {
+				walker, ok := sto.(game.Walker)
+				if !ok {
+					t.Skip("storage is not a game.Walker")
+				}
+
+				if err := walker.Walk(func(g game.Game) error { return nil }); errors.Is(err, apperrors.Unsupported) {
+					t.Skip("walking games is not supported by the storage")
+				}
+
+				assert.Nil(t, sto.Create(NewGame("1", "", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Create(NewGame("2", "", game.MODE_CLASSIC)))
+
+				failure := errors.New(apperrors.Internal, nil, "fail", "")
+
+				walked := 0
+				err := walker.Walk(func(g game.Game) error {
+					walked++
+					return failure
+				})
+
+				assert.True(t, errors.Is(err, apperrors.Internal))
+				assert.Equal(t, 1, walked)
+			},
+		},
+		{
+			name:   "compact",
+			should: "keep the stored games",
+			verify: func(t *testing.T, sto game.Storage) {
+				compacter, ok := sto.(game.Compacter)
+				if !ok {
+					t.Skip("storage is not a game.Compacter")
+				}
+
+				g := NewPlayedGame("1")
+				assert.Nil(t, sto.Create(g))
+				assert.Nil(t, sto.Update(g))
+				assert.Nil(t, sto.Create(NewGame("2", "", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Delete("2"))
+
+				assert.Nil(t, compacter.Compact())
+
+				stored, err := sto.GetByID("1")
+				assert.Nil(t, err)
+				g.Version++
+				assert.Equal(t, g, stored)
+
+				_, err = sto.GetByID("2")
+				assert.True(t, errors.Is(err, apperrors.NotFound))
+
+				assert.Nil(t, sto.Create(NewGame("3", "", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Update(stored))
+			},
+		},
+		{
+			name:   "list",
+			should: "return the games matching the filter, the most recent first",
+			verify: func(t *testing.T, sto game.Storage) {
+				lister, ok := sto.(game.Lister)
+				if !ok {
+					t.Skip("storage is not a game.Lister")
+				}
+
+				if _, err := lister.List(game.Filter{Limit: 1}); errors.Is(err, apperrors.Unsupported) {
+					t.Skip("listing games is not supported by the storage")
+				}
+
+				assert.Nil(t, sto.Create(NewGame("1", "alice", game.MODE_CLASSIC)))
+				assert.Nil(t, sto.Create(NewGame("2", "bob", game.MODE_COOP)))
+				assert.Nil(t, sto.Create(NewGame("3", "alice", game.MODE_COOP)))
+
+				joined := NewGame("4", "carol", game.MODE_COOP)
+				joined.Players = append(joined.Players, game.Participant{ID: "alice"})
+				joined.Board.Status = board.STATUS_WON
+				assert.Nil(t, sto.Create(joined))
+
+				ids := func(filter game.Filter) []string {
+					games, err := lister.List(filter)
+					assert.Nil(t, err)
+
+					result := []string{}
+					for _, g := range games {
+						result = append(result, g.ID)
+					}
+
+					return result
+				}
+
+				assert.Equal(t, []string{"4", "3", "2", "1"}, ids(game.Filter{Limit: 10}))
+				assert.Equal(t, []string{"4", "3", "1"}, ids(game.Filter{PlayerID: "alice", Limit: 10}))
+				assert.Equal(t, []string{"4", "3"}, ids(game.Filter{PlayerID: "alice", Mode: game.MODE_COOP, Limit: 10}))
+				assert.Equal(t, []string{"4"}, ids(game.Filter{Status: board.STATUS_WON, Limit: 10}))
+				assert.Equal(t, []string{"3", "2"}, ids(game.Filter{Limit: 2, Offset: 1}))
+				assert.Equal(t, []string{}, ids(game.Filter{PlayerID: "dave", Limit: 10}))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.verify(t, newStorage(t))
+		})
+	}
+}
+
+// RunCreationTime checks that the games stored without creation time are read as created when they started, as
+// the game schema migrations upgrade them. legacy stores the game the way the storage did before recording the
+// creation time, and return the storage once it has been opened again.
+func RunCreationTime(t *testing.T, legacy func(t *testing.T, g game.Game) game.Storage) {
+	tests := []struct {
+		name      string
+		should    string
+		startedAt int64
+		createdAt int64
+		expected  int64
+	}{
+		{
+			name:      "started game",
+			should:    "be created when it started",
+			startedAt: 1588291200,
+			expected:  1588291200,
+		},
+		{
+			name:     "game never started",
+			should:   "remain without creation time",
+			expected: 0,
+		},
+		{
+			name:      "game with creation time",
+			should:    "keep it",
+			startedAt: 1588291200,
+			createdAt: 1588291100,
+			expected:  1588291100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame("123", "", game.MODE_CLASSIC)
+			g.StartedAt = tt.startedAt
+			g.CreatedAt = tt.createdAt
+
+			stored, err := legacy(t, g).GetByID("123")
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, stored.CreatedAt)
+		})
+	}
+}
+
+// NewGame return a new game of the given mode, owned by the given player
+func NewGame(id string, ownerID string, mode string) game.Game {
+	g := game.Game{
+		ID:       id,
+		Mode:     mode,
+		PlayerID: ownerID,
+		Board:    board.NewBoard(5, 5, 3),
+	}
+
+	if mode == game.MODE_COOP {
+		g.Players = []game.Participant{{ID: ownerID}}
+	}
+
+	return g
+}
+
+// NewPlayedGame return a cooperative game with every field of the game set
+func NewPlayedGame(id string) game.Game {
+	g := NewGame(id, "alice", game.MODE_COOP)
+	g.Players = append(g.Players, game.Participant{ID: "bob", RevealedSquaresCount: 4, MarkedSquaresCount: 1, Score: 3})
+	g.Status = game.VERSUS_STATUS_PLAYING
+	g.Turn = "bob"
+	g.CreatedAt = 1588291100
+	g.Winner = "carol"
+	g.StartedAt = 1588291200
+	g.FinishedAt = 1588291260
+	g.ElapsedTime = 60
+
+	changed, _ := g.Board.PlaySquare(board.SquarePosition{Row: 2, Column: 2})
+	g.Board.MarkSquare(board.SquarePosition{Row: 0, Column: 4})
+
+	squares := []game.SquareChange{}
+	for _, pos := range changed {
+		square := g.Board.Get(pos)
+		squares = append(squares, game.SquareChange{
+			Row:      pos.Row,
+			Column:   pos.Column,
+			Type:     square.Type,
+			Revealed: square.Revealed,
+			Count:    g.Board.CountNeighborBombs(pos),
+		})
+	}
+
+	g.Moves = []game.Move{
+		{
+			ID:                   1,
+			Type:                 game.MOVE_PLAY_SQUARE,
+			PlayerID:             "alice",
+			Position:             board.SquarePosition{Row: 2, Column: 2},
+			Squares:              squares,
+			Status:               g.Board.Status,
+			StatusChanged:        true,
+			RevealedSquaresCount: g.Board.RevealedSquaresCount,
+			At:                   1588291230,
+		},
+		{
+			ID:       2,
+			Type:     game.MOVE_MARK_SQUARE,
+			PlayerID: "bob",
+			Position: board.SquarePosition{Row: 0, Column: 4},
+			Status:   g.Board.Status,
+			At:       1588291240,
+		},
+	}
+
+	return g
+}
+
+// Boards return boards covering every field of board.Board by name, among them boards with nil pointers
+func Boards() map[string]board.Board {
+	played := board.NewBoard(5, 5, 3)
+	played.PlaySquare(board.SquarePosition{Row: 2, Column: 2})
+	played.MarkSquare(board.SquarePosition{Row: 4, Column: 4})
+
+	lost := board.NewBoard(3, 3, 1)
+	lost.PlaySquare(board.SquarePosition{Row: 0, Column: 0})
+	lost.PlaySquare((*lost.BombsPositions)[0])
+
+	nilPointers := board.NewBoard(4, 4, 2)
+	nilPointers.BombsPositions = nil
+	nilPointers.FirstMoveDone = nil
+
+	firstMoveDone := true
+	noStatus := board.Board{
+		Squares:       [][]board.Square{{{Type: board.BOMB, Marked: true}, {Revealed: true}}},
+		FirstMoveDone: &firstMoveDone,
+	}
+
+	return map[string]board.Board{
+		"new":          board.NewBoard(5, 5, 3),
+		"played":       played,
+		"lost":         lost,
+		"seeded":       board.NewSeededBoard(6, 6, 5, 42),
+		"nil-pointers": nilPointers,
+		"no-status":    noStatus,
+	}
+}
@@ -1,47 +1,264 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/matiasvarela/minesweeper/internal/config"
+	"github.com/matiasvarela/minesweeper/internal/daily"
 	"github.com/matiasvarela/minesweeper/internal/game"
-	"github.com/matiasvarela/minesweeper/internal/storage/localsto"
+	"github.com/matiasvarela/minesweeper/internal/game/gamepb"
+	"github.com/matiasvarela/minesweeper/internal/gql"
+	"github.com/matiasvarela/minesweeper/internal/match"
+	"github.com/matiasvarela/minesweeper/internal/openapi"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/internal/storage"
+	"github.com/matiasvarela/minesweeper/internal/storage/cachesto"
+	"google.golang.org/grpc"
 )
 
+const DeprecationHeader = "Deprecation"
+
 func main() {
+	if config.IsAdminCommand(os.Args[1:]) {
+		cfg, err := config.LoadAdmin(os.Args[1:], os.Getenv)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = runAdmin(cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = run(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves the http and gRPC apis, deleting the expired games in background, until the process is signaled
+// to stop, then lets the ongoing requests finish and closes the storages
+func run(cfg config.Config) error {
+	if cfg.DailySecret == "" {
+		secret, err := daily.LoadSecret(cfg.Storage.DataPath)
+		if err != nil {
+			return err
+		}
+
+		cfg.DailySecret = secret
+	}
+
+	storages, err := storage.Open(cfg.Storage)
+	if err != nil {
+		return err
+	}
+
+	if cfg.CacheSize > 0 {
+		cache := cachesto.NewGameStorage(storages.Games, cfg.CacheSize)
+		expvar.Publish("game_cache", cache.Metrics())
+		storages.Games = cache
+	}
+
 	router := gin.New()
 
 	conf := cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "X-Auth-Token"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "X-Auth-Token", player.Header, game.LastEventIDHeader, game.IdempotencyKeyHeader, "If-Match"},
+		ExposeHeaders:    []string{"ETag", DeprecationHeader, "Link"},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}
 
 	router.Use(cors.New(conf))
 
-	routes(router)
+	gameHub := game.NewHub()
+	gameService := game.NewService(storages.Games, gameHub)
+	dailyService := daily.NewService(gameService, storages.Dailies, cfg.DailySecret)
 
-	err := router.Run(":8080")
+	schema, err := gql.NewSchema(gameService, dailyService)
 	if err != nil {
-		panic("run server has fail")
+		storages.Close()
+		return err
+	}
+
+	routes(router, services{
+		game:  gameService,
+		hub:   gameHub,
+		daily: dailyService,
+		match: match.NewService(gameService, storages.Matches),
+	}, schema)
+
+	httpServer := &http.Server{Addr: cfg.HttpAddr, Handler: router}
+
+	grpcServer := grpc.NewServer()
+	gamepb.RegisterGameServiceServer(grpcServer, game.NewGrpcServer(gameService, gameHub))
+
+	listener, err := net.Listen("tcp", cfg.GrpcAddr)
+	if err != nil {
+		storages.Close()
+		return err
+	}
+
+	errs := make(chan error, 3)
+
+	go func() {
+		err := httpServer.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			errs <- err
+		}
+	}()
+
+	go func() {
+		err := grpcServer.Serve(listener)
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	// the metrics are served apart from the apis, so they are not exposed along with them
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		metricsServer = &http.Server{Addr: cfg.MetricsAddr, Handler: expvar.Handler()}
+
+		go func() {
+			err := metricsServer.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				errs <- err
+			}
+		}()
 	}
+
+	var janitor *game.Janitor
+	if cfg.Retention.Enabled() {
+		janitor = game.NewJanitor(gameService, storages.Games, cfg.Retention, cfg.JanitorInterval)
+		janitor.Start()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case <-signals:
+	case err = <-errs:
+	}
+
+	shutdown(httpServer, grpcServer, cfg.ShutdownTimeout)
+
+	if metricsServer != nil {
+		metricsServer.Close()
+	}
+
+	if janitor != nil {
+		janitor.Stop()
+	}
+
+	closeErr := storages.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
 }
 
-func routes(router *gin.Engine) {
-	gameHttpHandler := game.NewHttpHandler(
-		game.NewService(localsto.NewGameStorage()),
-	)
+// shutdown stops the servers, the ongoing requests and streams are given the timeout to finish before being cut
+func shutdown(httpServer *http.Server, grpcServer *grpc.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	if httpServer.Shutdown(ctx) != nil {
+		httpServer.Close()
+	}
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+}
+
+// services are the application services the http api is served upon. Every version of the api has its own
+// handlers over the same services, so that versions can coexist while clients move to the newest one.
+type services struct {
+	game  game.Service
+	hub   *game.Hub
+	daily daily.Service
+	match match.Service
+}
+
+// routes registers the routes of the api, the graphql schema being built once over the services and shared by
+// every version
+func routes(router *gin.Engine, s services, schema graphql.Schema) {
+	routesV1(router.Group("/v1"), s, schema)
+
+	// the routes were first served at the root, they are kept until the clients move to /v1
+	routesV1(router.Group("/", deprecated("/v1")), s, schema)
+
+	router.GET("/openapi.json", openapi.NewHttpHandler(apiDocument()).Get)
+
+	router.GET("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "pong"})
+	})
+}
+
+func routesV1(router gin.IRouter, s services, schema graphql.Schema) {
+	gameHttpHandler := game.NewHttpHandler(s.game, s.hub)
+	dailyHttpHandler := daily.NewHttpHandler(s.daily)
+	matchHttpHandler := match.NewHttpHandler(s.match)
 
 	router.POST("/games", gameHttpHandler.Create)
+	router.GET("/games", gameHttpHandler.List)
 	router.GET("/games/:id", gameHttpHandler.Get)
+	router.DELETE("/games/:id", gameHttpHandler.Delete)
 	router.PUT("/games/:id/play-square", gameHttpHandler.PlaySquare)
 	router.PUT("/games/:id/mark-square", gameHttpHandler.MarkSquare)
+	router.GET("/games/:id/moves", gameHttpHandler.GetMoves)
+	router.POST("/games/:id/moves", gameHttpHandler.PlayMoves)
+	router.POST("/games/:id/join", gameHttpHandler.Join)
+	router.GET("/games/:id/ws", gameHttpHandler.Watch)
+	router.GET("/games/:id/events", gameHttpHandler.Events)
 
-	router.GET("/ping", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"message": "pong"})
-	})
+	router.POST("/matches", matchHttpHandler.Create)
+	router.GET("/matches/:id", matchHttpHandler.Get)
+	router.POST("/matches/:id/join", matchHttpHandler.Join)
+	router.POST("/matches/:id/start", matchHttpHandler.Start)
+
+	router.GET("/daily", dailyHttpHandler.Get)
+	router.GET("/daily/leaderboard", dailyHttpHandler.GetLeaderboard)
+
+	router.POST("/graphql", gql.NewHttpHandler(schema).Query)
+}
+
+// deprecated flags the responses of the routes as deprecated, linking to the same route under the given prefix
+func deprecated(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(DeprecationHeader, "true")
+		c.Header("Link", "<"+prefix+c.Request.URL.Path+">; rel=\"successor-version\"")
+		c.Next()
+	}
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/gql"
+	"github.com/matiasvarela/minesweeper/internal/match"
+	"github.com/matiasvarela/minesweeper/internal/openapi"
+	"github.com/matiasvarela/minesweeper/internal/storage/fakesto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	hub := game.NewHub()
+	gameService := game.NewService(fakesto.NewGameStorage(), hub)
+	dailyService := daily.NewService(gameService, fakesto.NewDailyStorage(), "secret")
+
+	schema, err := gql.NewSchema(gameService, dailyService)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	routes(router, services{
+		game:  gameService,
+		hub:   hub,
+		daily: dailyService,
+		match: match.NewService(gameService, fakesto.NewMatchStorage()),
+	}, schema)
+
+	return router
+}
+
+func TestRoutes_Versions(t *testing.T) {
+	router := newRouter(t)
+
+	tests := []struct {
+		name   string
+		should string
+		path   string
+		verify func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "versioned route",
+			should: "serve the route without deprecation",
+			path:   "/v1/games/456",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 404, rec.Code)
+				assert.Empty(t, rec.Header().Get(DeprecationHeader))
+			},
+		},
+		{
+			name:   "root alias",
+			should: "serve the same route flagged as deprecated",
+			path:   "/games/456",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 404, rec.Code)
+				assert.Equal(t, "true", rec.Header().Get(DeprecationHeader))
+				assert.Equal(t, `</v1/games/456>; rel="successor-version"`, rec.Header().Get("Link"))
+			},
+		},
+		{
+			name:   "unversioned route",
+			should: "serve the route without deprecation",
+			path:   "/ping",
+			verify: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.Equal(t, 200, rec.Code)
+				assert.Empty(t, rec.Header().Get(DeprecationHeader))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+			tt.verify(t, rec)
+		})
+	}
+}
+
+func TestApiDocument_Routes(t *testing.T) {
+	router := newRouter(t)
+	doc := apiDocument()
+
+	for _, route := range router.Routes() {
+		assert.True(t, doc.Has(route.Method, route.Path), "route %s %s is missing from the OpenAPI document", route.Method, route.Path)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range *item {
+			found := false
+			for _, route := range router.Routes() {
+				p, _ := openapi.Path(route.Path)
+				found = found || (p == path && route.Method == strings.ToUpper(method))
+			}
+
+			assert.True(t, found, "operation %s %s is documented but not served", method, path)
+		}
+	}
+}
+
+func TestApiDocument_Served(t *testing.T) {
+	router := newRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	assert.Equal(t, 200, rec.Code)
+
+	doc := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc["openapi"])
+
+	paths := doc["paths"].(map[string]interface{})
+	assert.Contains(t, paths["/v1/games/{id}/play-square"], "put")
+	assert.Contains(t, paths["/v1/games/{id}/mark-square"], "put")
+	assert.Equal(t, true, paths["/games/{id}/play-square"].(map[string]interface{})["put"].(map[string]interface{})["deprecated"])
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	square := schemas["Square"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{float64(0), float64(1)}, square["type"].(map[string]interface{})["enum"])
+
+	configuration := schemas["Configuration"].(map[string]interface{})
+	assert.ElementsMatch(t, []interface{}{"rows", "columns", "bombs"}, configuration["required"])
+	assert.Equal(t, float64(3), configuration["properties"].(map[string]interface{})["rows"].(map[string]interface{})["minimum"])
+
+	apiError := schemas["ApiError"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Len(t, apiError, 4)
+	assert.Contains(t, apiError, "code")
+
+	gameSchema := schemas["Game"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/Board", gameSchema["board"].(map[string]interface{})["$ref"])
+}
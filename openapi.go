@@ -0,0 +1,314 @@
+package main
+
+import (
+	"github.com/matiasvarela/minesweeper/internal/board"
+	"github.com/matiasvarela/minesweeper/internal/daily"
+	"github.com/matiasvarela/minesweeper/internal/game"
+	"github.com/matiasvarela/minesweeper/internal/gql"
+	"github.com/matiasvarela/minesweeper/internal/match"
+	"github.com/matiasvarela/minesweeper/internal/openapi"
+	"github.com/matiasvarela/minesweeper/internal/player"
+	"github.com/matiasvarela/minesweeper/pkg/apperrors"
+)
+
+// apiDocument describes every route served by the http api. The schemas are generated from the types the handlers
+// bind and reply with, so that they cannot drift apart from the code.
+func apiDocument() *openapi.Document {
+	doc := openapi.New("Minesweeper API", "1.0.0")
+
+	apiError := doc.Schema("ApiError", apperrors.ApiError{})
+
+	doc.Schema("SquarePosition", board.SquarePosition{})
+	doc.Schema("Square", board.Square{})
+	doc.Schema("Board", board.Board{})
+	doc.Schema("CompactBoard", board.CompactBoard{})
+	doc.Schema("Participant", game.Participant{})
+	doc.Schema("SquareChange", game.SquareChange{})
+	doc.Schema("Move", game.Move{})
+
+	gameSchema := doc.Schema("Game", game.Game{})
+	compactGame := doc.Schema("CompactGame", game.CompactGame{})
+	delta := doc.Schema("Delta", game.Delta{})
+	event := doc.Schema("Event", game.Event{})
+	configuration := doc.Schema("Configuration", game.Configuration{})
+	playSquareBody := doc.Schema("PlaySquareBody", game.PlaySquareBody{})
+	markSquareBody := doc.Schema("MarkSquareBody", game.MarkSquareBody{})
+	doc.Schema("MoveBody", game.MoveBody{})
+	movesBody := doc.Schema("MovesBody", game.MovesBody{})
+	batchResult := doc.Schema("BatchResult", game.BatchResult{})
+	batchDelta := doc.Schema("BatchDelta", game.BatchDelta{})
+	gameList := doc.Schema("GameList", game.GameList{})
+	moveList := doc.Schema("MoveList", game.MoveList{})
+
+	doc.Schema("MatchConfiguration", match.Configuration{})
+	doc.Schema("MatchParticipant", match.Participant{})
+	doc.Schema("MatchResult", match.Result{})
+	matchSchema := doc.Schema("Match", match.Match{})
+
+	doc.Schema("DailyResult", daily.Result{})
+	leaderboard := doc.Schema("Leaderboard", daily.Leaderboard{})
+
+	graphqlRequest := doc.Schema("GraphqlRequest", gql.Request{})
+
+	playerHeader := func(required bool) openapi.Parameter {
+		return openapi.Parameter{Name: player.Header, In: "header", Required: required, Description: "the id of the player", Schema: &openapi.Schema{Type: "string"}}
+	}
+
+	moveParameters := []openapi.Parameter{
+		playerHeader(false),
+		{Name: "If-Match", In: "header", Description: "the version the game must be at for the move to be applied", Schema: &openapi.Schema{Type: "string"}},
+		{Name: game.IdempotencyKeyHeader, In: "header", Description: "a unique value to safely retry the move", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "view", In: "query", Description: "reply with the squares changed by the move instead of the whole game", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"delta"}}},
+		{Name: "encoding", In: "query", Description: "the encoding of the board", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"compact"}}},
+	}
+
+	etag := map[string]openapi.Header{"ETag": {Description: "the version of the game", Schema: &openapi.Schema{Type: "string"}}}
+	deprecation := openapi.Header{Description: "the route is deprecated in favor of the same route under /v1", Schema: &openapi.Schema{Type: "string"}}
+
+	gameContent := map[string]openapi.MediaType{
+		"application/json":    {Schema: gameSchema},
+		game.CompactMediaType: {Schema: compactGame},
+	}
+
+	moveContent := map[string]openapi.MediaType{
+		"application/json":    {Schema: gameSchema},
+		game.CompactMediaType: {Schema: compactGame},
+		game.DeltaMediaType:   {Schema: delta},
+	}
+
+	responses := func(status string, description string, content map[string]openapi.MediaType, codes ...string) map[string]openapi.Response {
+		r := map[string]openapi.Response{status: {Description: description, Content: content}}
+
+		for _, code := range codes {
+			r[code] = openapi.Response{Description: errorDescriptions[code], Content: openapi.JSON(apiError)}
+		}
+
+		return r
+	}
+
+	withETag := func(r map[string]openapi.Response, status string) map[string]openapi.Response {
+		response := r[status]
+		response.Headers = etag
+		r[status] = response
+
+		return r
+	}
+
+	// the operations are served under /v1 and, deprecated, at the root
+	add := func(method string, route string, operation openapi.Operation) {
+		doc.Add(method, "/v1"+route, operation)
+
+		alias := operation
+		alias.OperationID += "Deprecated"
+		alias.Deprecated = true
+		alias.Responses = map[string]openapi.Response{}
+
+		for status, response := range operation.Responses {
+			headers := map[string]openapi.Header{DeprecationHeader: deprecation}
+			for name, header := range response.Headers {
+				headers[name] = header
+			}
+
+			response.Headers = headers
+			alias.Responses[status] = response
+		}
+
+		doc.Add(method, route, alias)
+	}
+
+	add("POST", "/games", openapi.Operation{
+		OperationID: "createGame",
+		Summary:     "Create a new game with the given configuration",
+		Tags:        []string{"games"},
+		Parameters:  []openapi.Parameter{playerHeader(false)},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(configuration)},
+		Responses:   withETag(responses("201", "the new game", gameContent, "400", "401", "500"), "201"),
+	})
+
+	add("GET", "/games", openapi.Operation{
+		OperationID: "listGames",
+		Summary:     "List the games the player owns or takes part in, the most recently created first. Only available when the storage can query the games.",
+		Tags:        []string{"games"},
+		Parameters: []openapi.Parameter{
+			playerHeader(true),
+			{Name: "mode", In: "query", Description: "the mode of the games", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{game.MODE_CLASSIC, game.MODE_COOP, game.MODE_VERSUS}}},
+			{Name: "status", In: "query", Description: "the status of the versus games or of the board", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "limit", In: "query", Description: "the max number of games to list", Schema: &openapi.Schema{Type: "integer", Minimum: float(1), Maximum: float(game.MaxListLimit)}},
+			{Name: "offset", In: "query", Description: "the number of games to skip", Schema: &openapi.Schema{Type: "integer", Minimum: float(0)}},
+		},
+		Responses: responses("200", "the games", openapi.JSON(gameList), "400", "401", "500", "501"),
+	})
+
+	add("GET", "/games/:id", openapi.Operation{
+		OperationID: "getGame",
+		Summary:     "Get a game",
+		Tags:        []string{"games"},
+		Parameters:  []openapi.Parameter{{Name: "encoding", In: "query", Description: "the encoding of the board", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"compact"}}}},
+		Responses:   withETag(responses("200", "the game", gameContent, "404", "500"), "200"),
+	})
+
+	add("DELETE", "/games/:id", openapi.Operation{
+		OperationID: "deleteGame",
+		Summary:     "Delete a game, which is only allowed to its owner",
+		Tags:        []string{"games"},
+		Parameters:  []openapi.Parameter{playerHeader(true)},
+		Responses:   responses("204", "the game has been deleted", nil, "401", "403", "404", "500"),
+	})
+
+	add("PUT", "/games/:id/play-square", openapi.Operation{
+		OperationID: "playSquare",
+		Summary:     "Reveal a square",
+		Tags:        []string{"games"},
+		Parameters:  moveParameters,
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(playSquareBody)},
+		Responses:   withETag(responses("200", "the game after the move", moveContent, "400", "401", "403", "404", "409", "412", "500"), "200"),
+	})
+
+	add("PUT", "/games/:id/mark-square", openapi.Operation{
+		OperationID: "markSquare",
+		Summary:     "Mark a square, toggling the mark unless a mark is given",
+		Tags:        []string{"games"},
+		Parameters:  moveParameters,
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(markSquareBody)},
+		Responses:   withETag(responses("200", "the game after the move", moveContent, "400", "401", "403", "404", "409", "412", "500"), "200"),
+	})
+
+	add("GET", "/games/:id/moves", openapi.Operation{
+		OperationID: "getMoves",
+		Summary:     "Get a page of the move log of a game, which is left out of the games",
+		Tags:        []string{"games"},
+		Parameters: []openapi.Parameter{
+			{Name: "after", In: "query", Description: "the id of the move the page starts after", Schema: &openapi.Schema{Type: "integer", Minimum: float(0)}},
+			{Name: "limit", In: "query", Description: "the max number of moves to get", Schema: &openapi.Schema{Type: "integer", Minimum: float(1), Maximum: float(game.MaxListLimit)}},
+		},
+		Responses: responses("200", "the moves", openapi.JSON(moveList), "400", "404", "500"),
+	})
+
+	add("POST", "/games/:id/moves", openapi.Operation{
+		OperationID: "playMoves",
+		Summary:     "Apply several moves at once",
+		Tags:        []string{"games"},
+		Parameters:  moveParameters,
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(movesBody)},
+		Responses: withETag(responses("200", "the moves applied and the game after them", map[string]openapi.MediaType{
+			"application/json":  {Schema: batchResult},
+			game.DeltaMediaType: {Schema: batchDelta},
+		}, "400", "401", "403", "404", "409", "412", "500"), "200"),
+	})
+
+	add("POST", "/games/:id/join", openapi.Operation{
+		OperationID: "joinGame",
+		Summary:     "Join a cooperative or versus game",
+		Tags:        []string{"games"},
+		Parameters:  []openapi.Parameter{playerHeader(true)},
+		Responses:   withETag(responses("200", "the game", gameContent, "400", "401", "404", "500"), "200"),
+	})
+
+	add("GET", "/games/:id/ws", openapi.Operation{
+		OperationID: "watchGame",
+		Summary:     "Upgrade to a websocket streaming the events of the game and accepting commands",
+		Tags:        []string{"games"},
+		Parameters:  []openapi.Parameter{playerHeader(false)},
+		Responses:   responses("101", "the connection has been upgraded to a websocket", nil, "400", "401", "404", "500"),
+	})
+
+	add("GET", "/games/:id/events", openapi.Operation{
+		OperationID: "gameEvents",
+		Summary:     "Stream the events of the game as server-sent events",
+		Tags:        []string{"games"},
+		Parameters: []openapi.Parameter{
+			{Name: game.LastEventIDHeader, In: "header", Description: "the id of the last event received, to resume the stream", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "last_event_id", In: "query", Description: "the id of the last event received, to resume the stream", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: responses("200", "the stream of events", map[string]openapi.MediaType{"text/event-stream": {Schema: event}}, "400", "404", "500"),
+	})
+
+	add("POST", "/matches", openapi.Operation{
+		OperationID: "createMatch",
+		Summary:     "Create a match where several players race on the same board",
+		Tags:        []string{"matches"},
+		Parameters:  []openapi.Parameter{playerHeader(true)},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(openapi.Ref("MatchConfiguration"))},
+		Responses:   responses("201", "the new match", openapi.JSON(matchSchema), "400", "401", "500"),
+	})
+
+	add("GET", "/matches/:id", openapi.Operation{
+		OperationID: "getMatch",
+		Summary:     "Get a match, along with the game of the player",
+		Tags:        []string{"matches"},
+		Parameters:  []openapi.Parameter{playerHeader(false)},
+		Responses:   responses("200", "the match", openapi.JSON(matchSchema), "400", "404", "500"),
+	})
+
+	add("POST", "/matches/:id/join", openapi.Operation{
+		OperationID: "joinMatch",
+		Summary:     "Join a match before it starts",
+		Tags:        []string{"matches"},
+		Parameters:  []openapi.Parameter{playerHeader(true)},
+		Responses:   responses("200", "the match", openapi.JSON(matchSchema), "400", "401", "404", "500"),
+	})
+
+	add("POST", "/matches/:id/start", openapi.Operation{
+		OperationID: "startMatch",
+		Summary:     "Start a match, only allowed to its owner",
+		Tags:        []string{"matches"},
+		Parameters:  []openapi.Parameter{playerHeader(true)},
+		Responses:   responses("200", "the match", openapi.JSON(matchSchema), "400", "401", "403", "404", "500"),
+	})
+
+	add("GET", "/daily", openapi.Operation{
+		OperationID: "getDaily",
+		Summary:     "Get the daily challenge game of the player",
+		Tags:        []string{"daily"},
+		Parameters:  []openapi.Parameter{playerHeader(true)},
+		Responses:   responses("200", "the daily game of the player", openapi.JSON(gameSchema), "401", "500"),
+	})
+
+	add("GET", "/daily/leaderboard", openapi.Operation{
+		OperationID: "getDailyLeaderboard",
+		Summary:     "Get the leaderboard of a daily challenge",
+		Tags:        []string{"daily"},
+		Parameters:  []openapi.Parameter{{Name: "date", In: "query", Description: "the day of the challenge as " + daily.DateLayout + ", today by default", Schema: &openapi.Schema{Type: "string", Format: "date"}}},
+		Responses:   responses("200", "the leaderboard", openapi.JSON(leaderboard), "400", "500"),
+	})
+
+	add("POST", "/graphql", openapi.Operation{
+		OperationID: "graphql",
+		Summary:     "Execute a graphql request, whose errors are part of the graphql result",
+		Tags:        []string{"graphql"},
+		Parameters:  []openapi.Parameter{playerHeader(false)},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(graphqlRequest)},
+		Responses:   responses("200", "the graphql result", openapi.JSON(&openapi.Schema{Type: "object"}), "400", "401"),
+	})
+
+	doc.Add("GET", "/openapi.json", openapi.Operation{
+		OperationID: "openapi",
+		Summary:     "Get this document",
+		Tags:        []string{"meta"},
+		Responses:   responses("200", "the OpenAPI document", openapi.JSON(&openapi.Schema{Type: "object"})),
+	})
+
+	doc.Add("GET", "/ping", openapi.Operation{
+		OperationID: "ping",
+		Summary:     "Check the api is up",
+		Tags:        []string{"meta"},
+		Responses:   responses("200", "the api is up", openapi.JSON(&openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{"message": {Type: "string"}}})),
+	})
+
+	return doc
+}
+
+var errorDescriptions = map[string]string{
+	"400": "the request is invalid, with the validation or invalid_input code",
+	"401": "the player is missing, with the unauthorized code",
+	"403": "the player is not allowed, with the forbidden code",
+	"404": "the resource has not been found, with the not_found code",
+	"409": "the move is out of turn or the game has been concurrently modified, with the out_of_turn or conflict code",
+	"412": "the game is not at the version given in If-Match, with the precondition_failed code",
+	"500": "an internal error, with the internal code",
+	"501": "the operation is not supported by the storage, with the unsupported code",
+}
+
+func float(n float64) *float64 {
+	return &n
+}
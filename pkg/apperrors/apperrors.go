@@ -7,6 +7,13 @@ var (
 	Validation   = errors.Define("validation")
 	InvalidInput = errors.Define("invalid_input")
 	Internal     = errors.Define("internal")
+	Unauthorized = errors.Define("unauthorized")
+	Forbidden    = errors.Define("forbidden")
+	OutOfTurn    = errors.Define("out_of_turn")
+	Conflict     = errors.Define("conflict")
+	Unsupported  = errors.Define("unsupported")
+
+	PreconditionFailed = errors.Define("precondition_failed")
 )
 
 type ApiError struct {
@@ -28,6 +35,18 @@ func ToApiError(err error) ApiError {
 		return NewApiError(400, errors.Code(err), err.Error(), errors.Data(err))
 	case "invalid_input":
 		return NewApiError(400, errors.Code(err), err.Error(), errors.Data(err))
+	case "unauthorized":
+		return NewApiError(401, errors.Code(err), err.Error(), errors.Data(err))
+	case "forbidden":
+		return NewApiError(403, errors.Code(err), err.Error(), errors.Data(err))
+	case "out_of_turn":
+		return NewApiError(409, errors.Code(err), err.Error(), errors.Data(err))
+	case "conflict":
+		return NewApiError(409, errors.Code(err), err.Error(), errors.Data(err))
+	case "precondition_failed":
+		return NewApiError(412, errors.Code(err), err.Error(), errors.Data(err))
+	case "unsupported":
+		return NewApiError(501, errors.Code(err), err.Error(), errors.Data(err))
 	default:
 		return NewApiError(500, errors.Code(err), err.Error(), errors.Data(err))
 	}